@@ -0,0 +1,54 @@
+package generator
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/tlipoca9/devgen/genkit"
+)
+
+// ProjectRule implements genkit.ProjectRuleTool.
+// It lists the converters of the loaded packages with their methods.
+func (g *Generator) ProjectRule(gen *genkit.Generator) genkit.ProjectRule {
+	var pr genkit.ProjectRule
+	var sb strings.Builder
+	for _, pkg := range gen.Packages {
+		converters := g.findConverters(pkg)
+		if len(converters) == 0 {
+			continue
+		}
+		pr.Packages = append(pr.Packages, pkg)
+		for _, conv := range converters {
+			fmt.Fprintf(&sb, "\n### `%s` (package `%s`)\n\n", conv.name, pkg.PkgPath)
+			fmt.Fprintf(&sb, "Use `Default%s`.\n\n", conv.name)
+			for _, method := range conv.methods {
+				fmt.Fprintf(&sb, "- `%s(%s) %s`", method.name,
+					g.typeString(pkg, method.srcType), g.typeString(pkg, method.dstType))
+				var notes []string
+				for _, src := range slices.Sorted(maps.Keys(method.fieldMaps)) {
+					notes = append(notes, fmt.Sprintf("maps `%s` to `%s`", src, method.fieldMaps[src]))
+				}
+				if ignored := slices.Sorted(maps.Keys(method.ignoreFields)); len(ignored) > 0 {
+					notes = append(notes, "ignores `"+strings.Join(ignored, "`, `")+"`")
+				}
+				if method.shallow {
+					notes = append(notes, "shallow copy")
+				}
+				if len(notes) > 0 {
+					sb.WriteString(": " + strings.Join(notes, ", "))
+				}
+				sb.WriteString("\n")
+			}
+		}
+	}
+	if sb.Len() == 0 {
+		return genkit.ProjectRule{}
+	}
+
+	pr.Content = "## Converters (convertgen)\n\n" +
+		"These interfaces have generated implementations. Call them instead of copying fields by hand, " +
+		"and add methods to the interface for new conversions.\n" + sb.String()
+	return pr
+}
@@ -3,17 +3,21 @@ package generator
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/tlipoca9/devgen/cmd/delegatorgen/rules"
 	"github.com/tlipoca9/devgen/genkit"
 )
 
 // Generator generates delegator code for interfaces.
-type Generator struct{}
+type Generator struct {
+	// cacheTTL is the TTL used by @cache annotations without ttl.
+	cacheTTL string
+}
 
 // New creates a new Generator.
 func New() *Generator {
-	return &Generator{}
+	return &Generator{cacheTTL: DefaultCacheTTL}
 }
 
 // Name returns the tool name.
@@ -26,6 +30,29 @@ func (g *Generator) Config() genkit.ToolConfig {
 	return g.config()
 }
 
+// OptionsSchema implements genkit.OptionsTool.
+func (g *Generator) OptionsSchema() []genkit.OptionSpec {
+	return []genkit.OptionSpec{
+		{
+			Name:    "cache_ttl",
+			Type:    genkit.OptionDuration,
+			Default: DefaultCacheTTL,
+			Doc:     "@cache 未指定 ttl 时使用的默认缓存过期时间",
+		},
+	}
+}
+
+// SetOptions implements genkit.OptionsTool.
+func (g *Generator) SetOptions(opts map[string]any) error {
+	if ttl, ok := opts["cache_ttl"].(time.Duration); ok {
+		if ttl <= 0 {
+			return fmt.Errorf("cache_ttl must be positive, got %s", ttl)
+		}
+		g.cacheTTL = ttl.String()
+	}
+	return nil
+}
+
 // Rules implements genkit.RuleTool.
 func (g *Generator) Rules() []genkit.Rule {
 	return []genkit.Rule{
@@ -117,7 +144,7 @@ func (g *Generator) WriteHeader(gf *genkit.GeneratedFile, pkgName string) {
 func (g *Generator) GenerateDelegator(gf *genkit.GeneratedFile, iface *genkit.Interface, pkg *genkit.Package) error {
 	// Validate first
 	c := genkit.NewDiagnosticCollector(ToolName)
-	g.validateInterface(c, iface)
+	g.validateInterface(nil, c, iface)
 	if c.HasErrors() {
 		for _, d := range c.Collect() {
 			if d.Severity == genkit.DiagnosticError {
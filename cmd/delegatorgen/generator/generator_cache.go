@@ -87,7 +87,7 @@ func (g *Generator) generateCacheReadMethod(gf *genkit.GeneratedFile, m *genkit.
 	ifaceName := iface.Name
 
 	// Parse configuration
-	ttl := ann.GetOr("ttl", DefaultCacheTTL)
+	ttl := ann.GetOr("ttl", g.defaultCacheTTL())
 	jitter := parseIntOr(ann.Get("jitter"), DefaultCacheJitter)
 	refresh := parseIntOr(ann.Get("refresh"), DefaultCacheRefresh)
 	prefix := ann.GetOr("prefix", DefaultCacheKeyPrefix)
@@ -207,7 +207,7 @@ func (g *Generator) generateCacheReadMethod(gf *genkit.GeneratedFile, m *genkit.
 func (g *Generator) generateRefreshMethod(gf *genkit.GeneratedFile, m *genkit.Method, iface *genkit.Interface, pkg *genkit.Package, ann *genkit.Annotation, ctxParam string) {
 	ifaceName := iface.Name
 
-	ttl := ann.GetOr("ttl", DefaultCacheTTL)
+	ttl := ann.GetOr("ttl", g.defaultCacheTTL())
 	jitter := parseIntOr(ann.Get("jitter"), DefaultCacheJitter)
 	refresh := parseIntOr(ann.Get("refresh"), DefaultCacheRefresh)
 
@@ -639,6 +639,14 @@ func parseIntOr(s string, def int) int {
 	return v
 }
 
+// defaultCacheTTL returns the TTL for @cache annotations without ttl.
+func (g *Generator) defaultCacheTTL() string {
+	if g.cacheTTL == "" {
+		return DefaultCacheTTL
+	}
+	return g.cacheTTL
+}
+
 // writeDurationConst writes a duration constant definition to the generated file.
 // Examples: "5m" -> "baseTTL = 5 * time.Minute", "1h" -> "baseTTL = time.Hour"
 func writeDurationConst(gf *genkit.GeneratedFile, name, s string) {
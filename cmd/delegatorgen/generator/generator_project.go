@@ -0,0 +1,70 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tlipoca9/devgen/genkit"
+)
+
+// ProjectRule implements genkit.ProjectRuleTool.
+// It lists the delegator interfaces of the loaded packages with the
+// annotations of their methods.
+func (g *Generator) ProjectRule(gen *genkit.Generator) genkit.ProjectRule {
+	var pr genkit.ProjectRule
+	var sb strings.Builder
+	for _, pkg := range gen.Packages {
+		ifaces := g.FindInterfaces(pkg)
+		if len(ifaces) == 0 {
+			continue
+		}
+		pr.Packages = append(pr.Packages, pkg)
+		for _, iface := range ifaces {
+			g.describeInterface(&sb, iface)
+		}
+	}
+	if sb.Len() == 0 {
+		return genkit.ProjectRule{}
+	}
+
+	pr.Content = "## Delegators (delegatorgen)\n\n" +
+		"Wrap implementations of these interfaces with the generated builder, " +
+		"e.g. `NewUserRepositoryDelegator(base).WithCache(cache).Build()`, " +
+		"instead of writing tracing or caching decorators by hand.\n" + sb.String()
+	return pr
+}
+
+// describeInterface writes the section of a delegator interface in the
+// project rule.
+func (g *Generator) describeInterface(sb *strings.Builder, iface *genkit.Interface) {
+	fmt.Fprintf(sb, "\n### `%s` (package `%s`)\n\n", iface.Name, iface.Pkg.PkgPath)
+
+	var with []string
+	if g.hasAnnotation(iface, "trace") {
+		with = append(with, "`WithTracing`")
+	}
+	if g.hasAnnotation(iface, "cache") || g.hasAnnotation(iface, "cache_evict") {
+		with = append(with, "`WithCache`")
+	}
+	fmt.Fprintf(sb, "Builder: `New%sDelegator`", iface.Name)
+	if len(with) > 0 {
+		sb.WriteString(" with " + strings.Join(with, ", "))
+	}
+	sb.WriteString(".\n\n")
+
+	for _, m := range iface.Methods {
+		fmt.Fprintf(sb, "- `%s`", m.Name)
+		var anns []string
+		for _, ann := range genkit.ParseAnnotations(m.Doc) {
+			if ann.Tool == ToolName {
+				anns = append(anns, "`"+strings.TrimPrefix(ann.Raw, ToolName+":")+"`")
+			}
+		}
+		if len(anns) > 0 {
+			sb.WriteString(": " + strings.Join(anns, " "))
+		} else {
+			sb.WriteString(": passed through")
+		}
+		sb.WriteString("\n")
+	}
+}
@@ -0,0 +1,99 @@
+package generator_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/tlipoca9/devgen/cmd/delegatorgen/generator"
+	"github.com/tlipoca9/devgen/genkit"
+)
+
+func TestGenerator(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Delegatorgen Generator Suite")
+}
+
+var _ = Describe("Generator", func() {
+	var gen *generator.Generator
+
+	BeforeEach(func() {
+		gen = generator.New()
+	})
+
+	Describe("Name", func() {
+		It("should return the correct tool name", func() {
+			Expect(gen.Name()).To(Equal("delegatorgen"))
+		})
+	})
+
+	Describe("Validate", func() {
+		var tempDir string
+
+		BeforeEach(func() {
+			tempDir = GinkgoT().TempDir()
+			err := os.WriteFile(filepath.Join(tempDir, "go.mod"), []byte("module testpkg\n\ngo 1.21\n"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		const content = `package testpkg
+
+// delegatorgen:@delegator
+type UserRepository interface {
+	// delegatorgen:@cache(prefix=5min, ttl=5min)
+	GetByID(id string) (string, error)
+}
+`
+
+		validate := func(opts genkit.Options) []genkit.Diagnostic {
+			opts.Dir = tempDir
+			gk := genkit.New(opts)
+			Expect(gk.Load(".")).To(Succeed())
+			return gen.Validate(gk, nil)
+		}
+
+		It("should suggest a fix for a misspelled TTL unit", func() {
+			file := filepath.Join(tempDir, "repo.go")
+			Expect(os.WriteFile(file, []byte(content), 0644)).To(Succeed())
+
+			diags := validate(genkit.Options{})
+			Expect(diags).To(HaveLen(1))
+			Expect(diags[0].Code).To(Equal(generator.ErrCodeCacheInvalidTTL))
+			Expect(diags[0].Fixes).To(HaveLen(1))
+			Expect(diags[0].Fixes[0].Message).To(Equal("replace ttl=5min with ttl=5m"))
+
+			// Only the ttl argument is replaced, not the prefix with the same value
+			fixed, err := genkit.ApplyEdits([]byte(content), diags[0].Fixes[0].Edits)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(fixed)).To(ContainSubstring("@cache(prefix=5min, ttl=5m)"))
+		})
+
+		It("should compute fixes against unsaved buffers", func() {
+			file := filepath.Join(tempDir, "repo.go")
+			Expect(os.WriteFile(file, []byte(content), 0644)).To(Succeed())
+
+			unsaved := strings.Replace(content, "// delegatorgen:@delegator\n", "// Users.\n//\n// delegatorgen:@delegator\n", 1)
+			diags := validate(genkit.Options{Overlay: map[string][]byte{file: []byte(unsaved)}})
+			Expect(diags).To(HaveLen(1))
+			Expect(diags[0].Fixes).To(HaveLen(1))
+
+			fixed, err := genkit.ApplyEdits([]byte(unsaved), diags[0].Fixes[0].Edits)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(fixed)).To(ContainSubstring("@cache(prefix=5min, ttl=5m)"))
+		})
+
+		It("should not suggest a fix for an unknown TTL unit", func() {
+			unknown := strings.Replace(content, "ttl=5min", "ttl=5x", 1)
+			Expect(os.WriteFile(filepath.Join(tempDir, "repo.go"), []byte(unknown), 0644)).To(Succeed())
+
+			diags := validate(genkit.Options{})
+			Expect(diags).To(HaveLen(1))
+			Expect(diags[0].Code).To(Equal(generator.ErrCodeCacheInvalidTTL))
+			Expect(diags[0].Fixes).To(BeEmpty())
+		})
+	})
+})
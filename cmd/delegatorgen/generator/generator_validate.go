@@ -1,6 +1,7 @@
 package generator
 
 import (
+	"fmt"
 	"strings"
 	"time"
 
@@ -16,7 +17,7 @@ func (g *Generator) Validate(gen *genkit.Generator, _ *genkit.Logger) []genkit.D
 			if !genkit.HasAnnotation(iface.Doc, ToolName, "delegator") {
 				continue
 			}
-			g.validateInterface(c, iface)
+			g.validateInterface(gen, c, iface)
 		}
 	}
 
@@ -24,19 +25,26 @@ func (g *Generator) Validate(gen *genkit.Generator, _ *genkit.Logger) []genkit.D
 }
 
 // validateInterface validates a single interface and collects diagnostics.
-func (g *Generator) validateInterface(c *genkit.DiagnosticCollector, iface *genkit.Interface) {
+// Suggested fixes are computed against the sources of gen; a nil gen
+// reads them from disk.
+func (g *Generator) validateInterface(gen *genkit.Generator, c *genkit.DiagnosticCollector, iface *genkit.Interface) {
 	if len(iface.Methods) == 0 {
 		c.Error(ErrCodeNoMethods, "interface has no methods", iface.Pos)
 		return
 	}
 
 	for _, m := range iface.Methods {
-		g.validateMethod(c, m, iface)
+		g.validateMethod(gen, c, m, iface)
 	}
 }
 
 // validateMethod validates a single method and collects diagnostics.
-func (g *Generator) validateMethod(c *genkit.DiagnosticCollector, m *genkit.Method, iface *genkit.Interface) {
+func (g *Generator) validateMethod(
+	gen *genkit.Generator,
+	c *genkit.DiagnosticCollector,
+	m *genkit.Method,
+	iface *genkit.Interface,
+) {
 	// Validate @cache annotation
 	if ann := genkit.GetAnnotation(m.Doc, ToolName, "cache"); ann != nil {
 		// @cache requires at least one return value (besides error)
@@ -54,6 +62,11 @@ func (g *Generator) validateMethod(c *genkit.DiagnosticCollector, m *genkit.Meth
 			if !isValidDuration(ttl) {
 				c.Errorf(ErrCodeCacheInvalidTTL, m.Pos,
 					"invalid TTL format %q, expected duration like 5m, 1h, 30s", ttl)
+				if fixed := suggestDuration(ttl); fixed != "" {
+					if edit, ok := gen.ReplaceAnnotationArg(m.Pos, ann, "ttl", fixed); ok {
+						c.WithFix(fmt.Sprintf("replace ttl=%s with ttl=%s", ttl, fixed), edit)
+					}
+				}
 			}
 		}
 
@@ -179,6 +192,34 @@ func isValidDuration(s string) bool {
 	return err == nil
 }
 
+// durationUnitTypos maps common misspellings of duration units to Go units.
+var durationUnitTypos = map[string]string{
+	"sec": "s", "secs": "s", "second": "s", "seconds": "s",
+	"min": "m", "mins": "m", "minute": "m", "minutes": "m",
+	"hr": "h", "hrs": "h", "hour": "h", "hours": "h",
+}
+
+// suggestDuration returns a valid duration close to s, or "" if none.
+// Known unit misspellings are corrected, e.g. "5min" -> "5m"; a missing or
+// unknown unit such as "5x" has no suggestion.
+func suggestDuration(s string) string {
+	i := 0
+	for i < len(s) && (s[i] >= '0' && s[i] <= '9' || s[i] == '.') {
+		i++
+	}
+	if i == 0 {
+		return ""
+	}
+	num, unit := s[:i], strings.ToLower(strings.TrimSpace(s[i:]))
+	if u, ok := durationUnitTypos[unit]; ok {
+		unit = u
+	}
+	if !isValidDuration(num + unit) {
+		return ""
+	}
+	return num + unit
+}
+
 // getParamNames returns a list of parameter names for error messages.
 func getParamNames(m *genkit.Method) []string {
 	var names []string
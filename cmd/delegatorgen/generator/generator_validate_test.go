@@ -0,0 +1,36 @@
+package generator
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("suggestDuration", func() {
+	DescribeTable("should correct misspelled units",
+		func(input, want string) {
+			Expect(suggestDuration(input)).To(Equal(want))
+		},
+		Entry("seconds", "30sec", "30s"),
+		Entry("minutes", "5min", "5m"),
+		Entry("hours", "2hrs", "2h"),
+		Entry("upper case", "5MIN", "5m"),
+		Entry("space before unit", "10 minutes", "10m"),
+		Entry("fraction", "1.5hours", "1.5h"),
+	)
+
+	DescribeTable("should not guess",
+		func(input string) {
+			Expect(suggestDuration(input)).To(BeEmpty())
+		},
+		Entry("unknown unit", "5x"),
+		Entry("missing unit", "5"),
+		Entry("missing number", "min"),
+		Entry("days", "1d"),
+	)
+
+	It("should map every typo to a valid Go duration unit", func() {
+		for typo, unit := range durationUnitTypos {
+			Expect(isValidDuration("1"+unit)).To(BeTrue(), "unit %q for %q", unit, typo)
+		}
+	})
+})
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around changes.
+const diffContext = 3
+
+// diffOp is a line of an edit script: ' ' keeps, '-' removes and '+' adds
+// the line.
+type diffOp struct {
+	kind byte
+	text string
+}
+
+// unifiedDiff returns a unified diff from have to want, the old and new
+// content of the file name. A missing side is shown as /dev/null.
+func unifiedDiff(name, have, want string) string {
+	if have == want {
+		return ""
+	}
+	ops := diffLines(splitLines(have), splitLines(want))
+
+	var sb strings.Builder
+	from, to := "a/"+name, "b/"+name
+	if have == "" {
+		from = "/dev/null"
+	}
+	if want == "" {
+		to = "/dev/null"
+	}
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", from, to)
+
+	// haveLine[k] and wantLine[k] count the lines before ops[k].
+	haveLine := make([]int, len(ops)+1)
+	wantLine := make([]int, len(ops)+1)
+	for k, op := range ops {
+		haveLine[k+1], wantLine[k+1] = haveLine[k], wantLine[k]
+		if op.kind != '+' {
+			haveLine[k+1]++
+		}
+		if op.kind != '-' {
+			wantLine[k+1]++
+		}
+	}
+
+	for next := 0; next < len(ops); {
+		first := next
+		for first < len(ops) && ops[first].kind == ' ' {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+		// Changes closer than twice the context share a hunk.
+		last := first + 1
+		for k := first; k < len(ops) && k-last < 2*diffContext; k++ {
+			if ops[k].kind != ' ' {
+				last = k + 1
+			}
+		}
+		start, end := max(first-diffContext, 0), min(last+diffContext, len(ops))
+
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n",
+			hunkRange(haveLine[start], haveLine[end]-haveLine[start]),
+			hunkRange(wantLine[start], wantLine[end]-wantLine[start]))
+		for _, op := range ops[start:end] {
+			sb.WriteByte(op.kind)
+			sb.WriteString(op.text + "\n")
+		}
+		next = end
+	}
+	return sb.String()
+}
+
+// hunkRange formats the range of a hunk that starts after line before and
+// spans count lines.
+func hunkRange(before, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", before)
+	}
+	return fmt.Sprintf("%d,%d", before+1, count)
+}
+
+// diffLines returns an edit script from a to b based on their longest
+// common subsequence.
+func diffLines(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	ma, mb := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	// lcs[i][j] is the length of the longest common subsequence of ma[i:]
+	// and mb[j:].
+	lcs := make([][]int, len(ma)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(mb)+1)
+	}
+	for i := len(ma) - 1; i >= 0; i-- {
+		for j := len(mb) - 1; j >= 0; j-- {
+			if ma[i] == mb[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b)-prefix-suffix)
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	i, j := 0, 0
+	for i < len(ma) && j < len(mb) {
+		switch {
+		case ma[i] == mb[j]:
+			ops = append(ops, diffOp{' ', ma[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', ma[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', mb[j]})
+			j++
+		}
+	}
+	for ; i < len(ma); i++ {
+		ops = append(ops, diffOp{'-', ma[i]})
+	}
+	for ; j < len(mb); j++ {
+		ops = append(ops, diffOp{'+', mb[j]})
+	}
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops
+}
+
+// splitLines splits s into lines without their line breaks.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
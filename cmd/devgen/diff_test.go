@@ -0,0 +1,37 @@
+package main
+
+import "testing"
+
+func TestUnifiedDiff(t *testing.T) {
+	have := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\n"
+	want := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nl\nm\n"
+
+	got := unifiedDiff("x.md", have, want)
+	wantDiff := `--- a/x.md
++++ b/x.md
+@@ -1,5 +1,5 @@
+ a
+-b
++B
+ c
+ d
+ e
+@@ -8,5 +8,5 @@
+ h
+ i
+ j
+-k
+ l
++m
+`
+	if got != wantDiff {
+		t.Errorf("unifiedDiff() =\n%s\nwant\n%s", got, wantDiff)
+	}
+
+	if got := unifiedDiff("x.md", "", "a\n"); got != "--- /dev/null\n+++ b/x.md\n@@ -0,0 +1,1 @@\n+a\n" {
+		t.Errorf("unifiedDiff() of a new file =\n%s", got)
+	}
+	if got := unifiedDiff("x.md", "a\n", "a\n"); got != "" {
+		t.Errorf("unifiedDiff() of equal content = %q", got)
+	}
+}
@@ -0,0 +1,486 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tlipoca9/devgen/genkit"
+)
+
+// ExplainResult describes what devgen tools generate for a single symbol.
+type ExplainResult struct {
+	Package string        `json:"package"`
+	Symbol  string        `json:"symbol"`
+	Kind    string        `json:"kind"` // "type", "enum" or "interface"
+	File    string        `json:"file"`
+	Line    int           `json:"line"`
+	Tools   []ExplainTool `json:"tools"`
+}
+
+// ExplainTool describes one tool that applies to the explained symbol.
+type ExplainTool struct {
+	Name        string              `json:"name"`
+	Annotations []ExplainAnnotation `json:"annotations"`
+	Generated   []ExplainSymbol     `json:"generated,omitempty"`
+}
+
+// ExplainAnnotation is a parsed annotation together with its resulting options.
+type ExplainAnnotation struct {
+	Target string            `json:"target"` // symbol or field the annotation is attached to
+	Name   string            `json:"name"`
+	Flags  []string          `json:"flags,omitempty"`
+	Args   map[string]string `json:"args,omitempty"`
+	Raw    string            `json:"raw"`
+	Doc    string            `json:"doc,omitempty"`
+}
+
+// ExplainSymbol is a generated declaration that belongs to the explained symbol.
+type ExplainSymbol struct {
+	Name    string `json:"name"`
+	Kind    string `json:"kind"` // "method", "func", "type", "var" or "const"
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Snippet string `json:"snippet"`
+}
+
+// ExplainCommand handles the 'devgen explain' subcommand.
+// It shows which tools apply to a symbol, how its annotations are parsed,
+// and which declarations each tool generates for it.
+type ExplainCommand struct {
+	log *genkit.Logger
+	out io.Writer
+}
+
+// NewExplainCommand creates a new ExplainCommand writing to stdout.
+func NewExplainCommand(log *genkit.Logger) *ExplainCommand {
+	return &ExplainCommand{log: log, out: os.Stdout}
+}
+
+// Execute explains the symbol identified by args.
+// Supported forms:
+//   - <package> <Type>: explain a type declared in the package
+//   - <file.go>:<line>: explain the type declared at (or containing) the line
+func (c *ExplainCommand) Execute(ctx context.Context, args []string, jsonOutput, includeTests bool) error {
+	pattern, symbol, file, line, err := parseExplainArgs(args)
+	if err != nil {
+		return err
+	}
+
+	configSearchDir, err := findConfigSearchDir([]string{pattern})
+	if err != nil {
+		return err
+	}
+	cfg, err := genkit.LoadConfig(configSearchDir)
+	if err != nil {
+		c.log.Warn("Failed to load devgen.toml: %v", err)
+		cfg = &genkit.Config{}
+	}
+	tools, loader, err := loadTools(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = loader.Close() }()
+
+	gen := genkit.New(genkit.Options{
+		IgnoreGeneratedFiles: true,
+		IncludeTests:         includeTests,
+	})
+	if err := gen.Load(pattern); err != nil {
+		return fmt.Errorf("load: %w", err)
+	}
+
+	target, err := findExplainTarget(gen, symbol, file, line)
+	if err != nil {
+		return err
+	}
+
+	result := &ExplainResult{
+		Package: target.pkg.PkgPath,
+		Symbol:  target.name,
+		Kind:    target.kind,
+		File:    target.pos.Filename,
+		Line:    target.pos.Line,
+	}
+
+	toolConfigs := genkit.CollectToolConfigs(tools)
+	if cfg.Tools != nil {
+		toolConfigs = genkit.MergeToolConfigs(toolConfigs, cfg.Tools)
+	}
+
+	// Run each tool in turn: the files it adds or changes in the dry run
+	// are the files it generates.
+	var prev map[string][]byte
+	for _, tool := range tools {
+		anns := target.annotations(tool.Name(), toolConfigs[tool.Name()])
+
+		if err := runTool(gen, loader, tool, genkit.NewLoggerWithWriter(io.Discard)); err != nil {
+			return fmt.Errorf("%s: %w", tool.Name(), err)
+		}
+		files, err := gen.DryRun()
+		if err != nil {
+			return fmt.Errorf("generate: %w", err)
+		}
+		generated := toolSymbols(prev, files, target.pkg.Dir, target.name)
+		prev = files
+
+		if len(anns) == 0 && len(generated) == 0 {
+			continue
+		}
+		result.Tools = append(result.Tools, ExplainTool{
+			Name:        tool.Name(),
+			Annotations: anns,
+			Generated:   generated,
+		})
+	}
+
+	if jsonOutput {
+		enc := json.NewEncoder(c.out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	}
+	c.print(result)
+	return nil
+}
+
+// print renders an ExplainResult in human-readable form.
+func (c *ExplainCommand) print(result *ExplainResult) {
+	c.log.Find("%s %s.%s (%s:%d)", result.Kind, result.Package, result.Symbol, result.File, result.Line)
+	if len(result.Tools) == 0 {
+		c.log.Warn("No tools apply to %s", result.Symbol)
+		return
+	}
+
+	for _, tool := range result.Tools {
+		c.log.Info("%s", tool.Name)
+		for _, ann := range tool.Annotations {
+			c.log.Item("%s: %s", ann.Target, ann.Raw)
+			if len(ann.Flags) > 0 {
+				c.log.Item("  flags: %s", strings.Join(ann.Flags, ", "))
+			}
+			for _, k := range sortedKeys(ann.Args) {
+				c.log.Item("  %s = %s", k, ann.Args[k])
+			}
+		}
+		for _, sym := range tool.Generated {
+			c.log.Write("%s %s (%s:%d)", sym.Kind, sym.Name, sym.File, sym.Line)
+			_, _ = fmt.Fprintf(c.out, "\n%s\n\n", sym.Snippet)
+		}
+	}
+}
+
+// parseExplainArgs parses command arguments into either a package pattern and
+// symbol name, or a file and line number.
+func parseExplainArgs(args []string) (pattern, symbol, file string, line int, err error) {
+	switch len(args) {
+	case 1:
+		idx := strings.LastIndex(args[0], ":")
+		if idx <= 0 || !strings.HasSuffix(args[0][:idx], ".go") {
+			return "", "", "", 0, fmt.Errorf("expected <file.go>:<line>, got %q", args[0])
+		}
+		line, err = strconv.Atoi(args[0][idx+1:])
+		if err != nil || line <= 0 {
+			return "", "", "", 0, fmt.Errorf("invalid line number in %q", args[0])
+		}
+		file, err = filepath.Abs(args[0][:idx])
+		if err != nil {
+			return "", "", "", 0, err
+		}
+		pattern = relOrAbs(filepath.Dir(file))
+		if !filepath.IsAbs(pattern) {
+			pattern = "./" + pattern
+		}
+		return pattern, "", file, line, nil
+	case 2:
+		return args[0], args[1], "", 0, nil
+	default:
+		return "", "", "", 0, fmt.Errorf("expected <package> <Type> or <file.go>:<line>")
+	}
+}
+
+// relOrAbs returns dir relative to the working directory when possible.
+func relOrAbs(dir string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return dir
+	}
+	rel, err := filepath.Rel(wd, dir)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return dir
+	}
+	return rel
+}
+
+// explainTarget is the symbol being explained.
+type explainTarget struct {
+	pkg    *genkit.Package
+	name   string
+	kind   string
+	pos    token.Position
+	doc    string
+	fields []*genkit.Field
+}
+
+// annotations returns the parsed annotations of the target for a tool,
+// including annotations on struct fields and enum values.
+func (t *explainTarget) annotations(tool string, cfg genkit.ToolConfig) []ExplainAnnotation {
+	docs := make(map[string]string)
+	for _, ann := range cfg.Annotations {
+		docs[ann.Name] = ann.Doc
+	}
+
+	var result []ExplainAnnotation
+	add := func(target, doc string) {
+		for _, ann := range genkit.ParseDoc(doc) {
+			if ann.Tool != tool {
+				continue
+			}
+			ea := ExplainAnnotation{
+				Target: target,
+				Name:   ann.Name,
+				Flags:  ann.Flags,
+				Raw:    ann.Raw,
+				Doc:    firstLine(docs[ann.Name]),
+			}
+			if len(ann.Args) > 0 {
+				ea.Args = ann.Args
+			}
+			result = append(result, ea)
+		}
+	}
+
+	add(t.name, t.doc)
+	for _, f := range t.fields {
+		add(t.name+"."+f.Name, f.Doc)
+	}
+	for _, enum := range t.pkg.Enums {
+		if enum.Name != t.name {
+			continue
+		}
+		for _, v := range enum.Values {
+			add(v.Name, v.Doc+"\n"+v.Comment)
+		}
+	}
+	return result
+}
+
+// findExplainTarget locates the symbol by name, or by file and line.
+func findExplainTarget(gen *genkit.Generator, symbol, file string, line int) (*explainTarget, error) {
+	for _, pkg := range gen.Packages {
+		for _, typ := range pkg.Types {
+			pos := gen.Fset.Position(typ.TypeSpec.Name.Pos())
+			if symbol != "" && typ.Name != symbol {
+				continue
+			}
+			if file != "" {
+				end := gen.Fset.Position(typ.TypeSpec.End())
+				if pos.Filename != file || line < pos.Line-docLines(typ.Doc) || line > end.Line {
+					continue
+				}
+			}
+
+			t := &explainTarget{
+				pkg:    pkg,
+				name:   typ.Name,
+				kind:   "type",
+				pos:    pos,
+				doc:    typ.Doc,
+				fields: typ.Fields,
+			}
+			for _, enum := range pkg.Enums {
+				if enum.Name == typ.Name {
+					t.kind = "enum"
+				}
+			}
+			for _, iface := range pkg.Interfaces {
+				if iface.Name == typ.Name {
+					t.kind = "interface"
+				}
+			}
+			return t, nil
+		}
+	}
+
+	if symbol != "" {
+		return nil, fmt.Errorf("type %s not found", symbol)
+	}
+	return nil, fmt.Errorf("no type declaration found at %s:%d", file, line)
+}
+
+// docLines returns the number of lines in a doc comment.
+func docLines(doc string) int {
+	doc = strings.TrimRight(doc, "\n")
+	if doc == "" {
+		return 0
+	}
+	return strings.Count(doc, "\n") + 1
+}
+
+// toolSymbols returns the declarations for the named symbol in the
+// files under dir that were added or changed between two dry runs.
+// Declarations already present in prev are attributed to an earlier tool.
+func toolSymbols(prev, files map[string][]byte, dir, name string) []ExplainSymbol {
+	var result []ExplainSymbol
+	for _, path := range sortedKeys(files) {
+		if filepath.Dir(path) != dir || bytes.Equal(prev[path], files[path]) {
+			continue
+		}
+		existing := make(map[string]bool)
+		for _, sym := range extractGeneratedSymbols(path, prev[path], name) {
+			existing[sym.Kind+" "+sym.Name] = true
+		}
+		for _, sym := range extractGeneratedSymbols(path, files[path], name) {
+			if !existing[sym.Kind+" "+sym.Name] {
+				result = append(result, sym)
+			}
+		}
+	}
+	return result
+}
+
+// extractGeneratedSymbols parses a generated file and returns the declarations
+// that belong to the named symbol: its methods and the declarations that
+// refer to it, each with its own source snippet.
+func extractGeneratedSymbols(path string, content []byte, name string) []ExplainSymbol {
+	if content == nil {
+		return nil
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, content, parser.ParseComments)
+	if err != nil {
+		return nil
+	}
+
+	var result []ExplainSymbol
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			kind := "func"
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				if receiverTypeName(d.Recv.List[0].Type) != name {
+					continue
+				}
+				kind = "method"
+			} else if !refersTo(d, name) {
+				continue
+			}
+			result = append(result, newExplainSymbol(fset, content, d.Name.Name, kind, d.Doc, d))
+		case *ast.GenDecl:
+			if d.Tok == token.IMPORT || !refersTo(d, name) {
+				continue
+			}
+			names := genDeclNames(d)
+			if len(names) == 0 {
+				continue
+			}
+			result = append(result, newExplainSymbol(fset, content, names[0], d.Tok.String(), d.Doc, d))
+		}
+	}
+	return result
+}
+
+// refersTo reports whether node uses the identifier name, ignoring field and
+// method selectors such as x.Status.
+func refersTo(node ast.Node, name string) bool {
+	found := false
+	ast.Inspect(node, func(n ast.Node) bool {
+		if found {
+			return false
+		}
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			found = refersTo(n.X, name)
+			return false
+		case *ast.Ident:
+			found = n.Name == name
+		}
+		return true
+	})
+	return found
+}
+
+// newExplainSymbol builds an ExplainSymbol with the source text of node,
+// including its doc comment.
+func newExplainSymbol(
+	fset *token.FileSet,
+	content []byte,
+	name, kind string,
+	doc *ast.CommentGroup,
+	node ast.Node,
+) ExplainSymbol {
+	start := node.Pos()
+	if doc != nil {
+		start = doc.Pos()
+	}
+	startPos := fset.Position(start)
+	endPos := fset.Position(node.End())
+	return ExplainSymbol{
+		Name:    name,
+		Kind:    kind,
+		File:    startPos.Filename,
+		Line:    fset.Position(node.Pos()).Line,
+		Snippet: string(content[startPos.Offset:endPos.Offset]),
+	}
+}
+
+// receiverTypeName returns the base type name of a method receiver.
+func receiverTypeName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return receiverTypeName(e.X)
+	case *ast.Ident:
+		return e.Name
+	case *ast.IndexExpr:
+		return receiverTypeName(e.X)
+	case *ast.IndexListExpr:
+		return receiverTypeName(e.X)
+	default:
+		return ""
+	}
+}
+
+// genDeclNames returns the names declared by a type, var or const declaration.
+func genDeclNames(d *ast.GenDecl) []string {
+	var names []string
+	for _, spec := range d.Specs {
+		switch s := spec.(type) {
+		case *ast.TypeSpec:
+			names = append(names, s.Name.Name)
+		case *ast.ValueSpec:
+			for _, n := range s.Names {
+				names = append(names, n.Name)
+			}
+		}
+	}
+	return names
+}
+
+// firstLine returns the first non-empty line of s.
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// sortedKeys returns the keys of m in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
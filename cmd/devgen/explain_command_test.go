@@ -0,0 +1,117 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestParseExplainArgs tests parsing of package/type and file:line arguments
+func TestParseExplainArgs(t *testing.T) {
+	pattern, symbol, file, line, err := parseExplainArgs([]string{"./pkg/model", "User"})
+	if err != nil {
+		t.Fatalf("parseExplainArgs() error = %v", err)
+	}
+	if pattern != "./pkg/model" || symbol != "User" || file != "" || line != 0 {
+		t.Errorf("parseExplainArgs() = %q, %q, %q, %d", pattern, symbol, file, line)
+	}
+
+	pattern, symbol, file, line, err = parseExplainArgs([]string{"model/user.go:42"})
+	if err != nil {
+		t.Fatalf("parseExplainArgs() error = %v", err)
+	}
+	if pattern != "./model" || symbol != "" || !strings.HasSuffix(file, "model/user.go") || line != 42 {
+		t.Errorf("parseExplainArgs() = %q, %q, %q, %d", pattern, symbol, file, line)
+	}
+
+	// Files outside the working directory keep their absolute package path
+	outside := filepath.Join(filepath.Dir(t.TempDir()), "model")
+	t.Chdir(t.TempDir())
+	pattern, _, _, _, err = parseExplainArgs([]string{filepath.Join(outside, "user.go") + ":1"})
+	if err != nil || pattern != outside {
+		t.Errorf("parseExplainArgs(outside) = %q, %v, want %q", pattern, err, outside)
+	}
+
+	for _, args := range [][]string{{"User"}, {"user.go:abc"}, {"user.go:0"}, {"a", "b", "c"}} {
+		if _, _, _, _, err := parseExplainArgs(args); err == nil {
+			t.Errorf("parseExplainArgs(%v) should return error", args)
+		}
+	}
+}
+
+// TestExtractGeneratedSymbols tests that only declarations of the symbol are extracted
+func TestExtractGeneratedSymbols(t *testing.T) {
+	content := `package model
+
+// IsValid reports whether x is a valid Status.
+func (x Status) IsValid() bool {
+	return true
+}
+
+func (x Other) IsValid() bool {
+	return true
+}
+
+// StatusEnums is the enum helper for Status.
+var StatusEnums = []Status{}
+
+// OrderStatusEnums belongs to another enum.
+var OrderStatusEnums = []OrderStatus{}
+
+func ParseOrderStatus() OrderStatus { return 0 }
+
+type StatusCodeMapper struct{}
+
+func helper(o Order) string { return o.Status }
+`
+	symbols := extractGeneratedSymbols("model_enum.go", []byte(content), "Status")
+	if len(symbols) != 2 {
+		t.Fatalf("extractGeneratedSymbols() returned %d symbols, want 2", len(symbols))
+	}
+
+	if symbols[0].Name != "IsValid" || symbols[0].Kind != "method" || symbols[0].Line != 4 {
+		t.Errorf("symbols[0] = %+v", symbols[0])
+	}
+	if !strings.HasPrefix(symbols[0].Snippet, "// IsValid reports") {
+		t.Errorf("snippet should include doc comment, got %q", symbols[0].Snippet)
+	}
+	if strings.Contains(symbols[0].Snippet, "Other") {
+		t.Errorf("snippet should not include other declarations, got %q", symbols[0].Snippet)
+	}
+
+	if symbols[1].Name != "StatusEnums" || symbols[1].Kind != "var" {
+		t.Errorf("symbols[1] = %+v", symbols[1])
+	}
+}
+
+// TestToolSymbols tests that only declarations added by the latest
+// dry run in the symbol's package are attributed to it
+func TestToolSymbols(t *testing.T) {
+	enums := []byte("package model\n\nfunc (x Status) IsValid() bool { return true }\n")
+	validate := []byte(string(enums) + "\nfunc (x Status) Validate() error { return nil }\n")
+	prev := map[string][]byte{
+		"/model/model_enum.go": enums,
+	}
+	files := map[string][]byte{
+		"/model/model_enum.go":     enums,
+		"/model/model_validate.go": validate,
+		"/other/other_validate.go": validate,
+	}
+
+	symbols := toolSymbols(prev, files, "/model", "Status")
+	if len(symbols) != 2 {
+		t.Fatalf("toolSymbols() returned %d symbols, want 2: %+v", len(symbols), symbols)
+	}
+	for _, sym := range symbols {
+		if sym.File != "/model/model_validate.go" {
+			t.Errorf("symbol %s attributed from %s", sym.Name, sym.File)
+		}
+	}
+
+	// A changed file only contributes its new declarations
+	files["/model/model_enum.go"] = validate
+	symbols = toolSymbols(prev, files, "/model", "Status")
+	if len(symbols) != 3 || symbols[0].Name != "Validate" {
+		t.Errorf("toolSymbols(changed) = %+v", symbols)
+	}
+}
@@ -0,0 +1,759 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tlipoca9/devgen/genkit"
+)
+
+// lspDiagnosticsDelay debounces validation while the user is typing.
+const lspDiagnosticsDelay = 300 * time.Millisecond
+
+var (
+	// lspAnnotationRe matches a complete annotation such as enumgen:@enum(string, json).
+	lspAnnotationRe = regexp.MustCompile(`(\w+):@([\w.]+)(?:\(([^)]*)\))?`)
+
+	// lspAnnotationNameRe matches an annotation name being typed, e.g. "enumgen:@na".
+	lspAnnotationNameRe = regexp.MustCompile(`(\w+):@([\w.]*)$`)
+
+	// lspAnnotationParamRe matches a parameter being typed, e.g. "enumgen:@enum(string, js".
+	lspAnnotationParamRe = regexp.MustCompile(`(\w+):@([\w.]+)\(([^)]*)$`)
+
+	// lspToolNameRe matches a tool name being typed at the start of a comment.
+	lspToolNameRe = regexp.MustCompile(`//\s*(\w*)$`)
+
+	// lspTypeDeclRe matches a type declaration line.
+	lspTypeDeclRe = regexp.MustCompile(`^type\s+(\w+)`)
+)
+
+// LSPServer serves devgen features over the Language Server Protocol.
+// It provides annotation completion, hover documentation, live diagnostics
+// from ValidatableTool and go-to-definition for generated symbols.
+type LSPServer struct {
+	in  *bufio.Reader
+	out io.Writer
+	log *genkit.Logger
+
+	writeMu sync.Mutex
+
+	// validateMu serializes validations, since tools are not safe for
+	// concurrent use and each package validates on its own timer
+	validateMu sync.Mutex
+
+	mu          sync.Mutex
+	cfg         *genkit.Config
+	tools       []genkit.Tool
+	loader      *genkit.PluginLoader
+	toolConfigs map[string]genkit.ToolConfig
+	docs        map[string]string          // URI -> text of open documents
+	published   map[string]map[string]bool // package dir -> URIs with diagnostics
+	timers      map[string]*time.Timer     // package dir -> pending validation
+	shutdown    bool
+}
+
+// NewLSPServer creates a server that reads requests from in and writes
+// responses to out. Log output goes to log and must not use out.
+func NewLSPServer(in io.Reader, out io.Writer, log *genkit.Logger) *LSPServer {
+	return &LSPServer{
+		in:          bufio.NewReader(in),
+		out:         out,
+		log:         log,
+		cfg:         &genkit.Config{},
+		toolConfigs: make(map[string]genkit.ToolConfig),
+		docs:        make(map[string]string),
+		published:   make(map[string]map[string]bool),
+		timers:      make(map[string]*time.Timer),
+	}
+}
+
+// Serve handles messages until the client sends "exit" or the input is closed.
+func (s *LSPServer) Serve(ctx context.Context) error {
+	defer s.closeTools()
+	for {
+		msg, err := readLSPMessage(s.in)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			var rpcErr *lspError
+			if errors.As(err, &rpcErr) {
+				s.reply(nil, nil, rpcErr)
+				continue
+			}
+			return fmt.Errorf("read message: %w", err)
+		}
+		if msg.Method == "exit" {
+			s.stopTimers()
+			s.mu.Lock()
+			clean := s.shutdown
+			s.mu.Unlock()
+			if !clean {
+				return fmt.Errorf("exit without shutdown")
+			}
+			return nil
+		}
+
+		result, rpcErr := s.handle(ctx, msg)
+		if msg.ID != nil {
+			s.reply(msg.ID, result, rpcErr)
+		}
+	}
+}
+
+// handle dispatches a single request or notification.
+func (s *LSPServer) handle(ctx context.Context, msg *lspMessage) (any, *lspError) {
+	switch msg.Method {
+	case "initialize":
+		var params lspInitializeParams
+		if err := unmarshalParams(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.initialize(ctx, params), nil
+	case "initialized", "$/cancelRequest", "$/setTrace", "workspace/didChangeConfiguration":
+		return nil, nil
+	case "shutdown":
+		s.mu.Lock()
+		s.shutdown = true
+		s.mu.Unlock()
+		s.stopTimers()
+		return nil, nil
+	case "textDocument/didOpen":
+		var params lspDidOpenParams
+		if err := unmarshalParams(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		s.setDocument(params.TextDocument.URI, params.TextDocument.Text)
+		s.scheduleValidation(params.TextDocument.URI, 0)
+		return nil, nil
+	case "textDocument/didChange":
+		var params lspDidChangeParams
+		if err := unmarshalParams(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		// Full document sync: the last change holds the whole text
+		if n := len(params.ContentChanges); n > 0 {
+			s.setDocument(params.TextDocument.URI, params.ContentChanges[n-1].Text)
+			s.scheduleValidation(params.TextDocument.URI, lspDiagnosticsDelay)
+		}
+		return nil, nil
+	case "textDocument/didSave":
+		var params lspDidSaveParams
+		if err := unmarshalParams(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		s.scheduleValidation(params.TextDocument.URI, 0)
+		return nil, nil
+	case "textDocument/didClose":
+		var params lspDidCloseParams
+		if err := unmarshalParams(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		delete(s.docs, params.TextDocument.URI)
+		s.mu.Unlock()
+		return nil, nil
+	case "textDocument/completion":
+		var params lspTextDocumentPositionParams
+		if err := unmarshalParams(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.completion(params), nil
+	case "textDocument/hover":
+		var params lspTextDocumentPositionParams
+		if err := unmarshalParams(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.hover(params), nil
+	case "textDocument/definition":
+		var params lspTextDocumentPositionParams
+		if err := unmarshalParams(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.definition(params), nil
+	}
+
+	if msg.ID == nil {
+		// Unknown notifications are ignored
+		return nil, nil
+	}
+	return nil, &lspError{Code: lspMethodNotFound, Message: "method not found: " + msg.Method}
+}
+
+// initialize loads devgen.toml and tools for the workspace root.
+func (s *LSPServer) initialize(ctx context.Context, params lspInitializeParams) any {
+	root := params.RootPath
+	if params.RootURI != "" {
+		if path, err := uriToPath(params.RootURI); err == nil {
+			root = path
+		}
+	}
+	if root == "" {
+		root, _ = os.Getwd()
+	}
+
+	cfg, err := genkit.LoadConfig(root)
+	if err != nil {
+		s.log.Warn("Failed to load devgen.toml: %v", err)
+		cfg = &genkit.Config{}
+	}
+	tools, loader, err := loadTools(ctx, cfg)
+	if err != nil {
+		s.log.Warn("Failed to load tools: %v", err)
+		tools = builtinTools
+	}
+	toolConfigs := genkit.CollectToolConfigs(tools)
+	if cfg.Tools != nil {
+		toolConfigs = genkit.MergeToolConfigs(toolConfigs, cfg.Tools)
+	}
+
+	s.mu.Lock()
+	s.cfg = cfg
+	s.tools = tools
+	previous := s.loader
+	s.loader = loader
+	s.toolConfigs = toolConfigs
+	s.mu.Unlock()
+	if previous != nil {
+		_ = previous.Close()
+	}
+
+	return map[string]any{
+		"capabilities": map[string]any{
+			"textDocumentSync": map[string]any{
+				"openClose": true,
+				"change":    1, // full
+				"save":      map[string]any{"includeText": false},
+			},
+			"completionProvider": map[string]any{
+				"triggerCharacters": []string{"@", "(", ",", " "},
+			},
+			"hoverProvider":      true,
+			"definitionProvider": true,
+		},
+		"serverInfo": map[string]any{
+			"name":    "devgen",
+			"version": version,
+		},
+	}
+}
+
+// completion suggests tool names, annotation names and annotation parameter
+// values inside comments.
+func (s *LSPServer) completion(params lspTextDocumentPositionParams) *lspCompletionList {
+	line, ok := s.lineAt(params.TextDocument.URI, params.Position.Line)
+	if !ok {
+		return &lspCompletionList{Items: []lspCompletionItem{}}
+	}
+	prefix := line[:byteOffset(line, params.Position.Character)]
+	if !strings.Contains(prefix, "//") {
+		return &lspCompletionList{Items: []lspCompletionItem{}}
+	}
+
+	s.mu.Lock()
+	toolConfigs := s.toolConfigs
+	s.mu.Unlock()
+
+	items := []lspCompletionItem{}
+	if m := lspAnnotationParamRe.FindStringSubmatch(prefix); m != nil {
+		ann := findAnnotationConfig(toolConfigs, m[1], m[2])
+		if ann == nil || ann.Params == nil {
+			return &lspCompletionList{Items: items}
+		}
+		used := make(map[string]bool)
+		for _, v := range strings.Split(m[3], ",") {
+			used[strings.TrimSpace(v)] = true
+		}
+		for _, v := range ann.Params.Values {
+			if used[v] {
+				continue
+			}
+			item := lspCompletionItem{Label: v, Kind: lspCompletionKindValue, Detail: m[1] + ":@" + m[2]}
+			if doc := ann.Params.Docs[v]; doc != "" {
+				item.Documentation = &lspMarkupContent{Kind: "markdown", Value: doc}
+			}
+			items = append(items, item)
+		}
+		return &lspCompletionList{Items: items}
+	}
+
+	if m := lspAnnotationNameRe.FindStringSubmatch(prefix); m != nil {
+		tc, ok := toolConfigs[m[1]]
+		if !ok {
+			return &lspCompletionList{Items: items}
+		}
+		for _, ann := range tc.Annotations {
+			items = append(items, lspCompletionItem{
+				Label:         ann.Name,
+				Kind:          lspCompletionKindKeyword,
+				Detail:        fmt.Sprintf("%s:@%s (%s)", m[1], ann.Name, ann.Type),
+				Documentation: &lspMarkupContent{Kind: "markdown", Value: ann.Doc},
+			})
+		}
+		return &lspCompletionList{Items: items}
+	}
+
+	if lspToolNameRe.MatchString(prefix) {
+		for _, name := range sortedKeys(toolConfigs) {
+			if len(toolConfigs[name].Annotations) == 0 {
+				continue
+			}
+			items = append(items, lspCompletionItem{
+				Label:      name,
+				Kind:       lspCompletionKindModule,
+				Detail:     name + " annotations",
+				InsertText: name + ":@",
+			})
+		}
+	}
+	return &lspCompletionList{Items: items}
+}
+
+// hover returns the documentation of the annotation under the cursor.
+func (s *LSPServer) hover(params lspTextDocumentPositionParams) *lspHover {
+	line, ok := s.lineAt(params.TextDocument.URI, params.Position.Line)
+	if !ok {
+		return nil
+	}
+	offset := byteOffset(line, params.Position.Character)
+
+	s.mu.Lock()
+	toolConfigs := s.toolConfigs
+	s.mu.Unlock()
+
+	for _, loc := range lspAnnotationRe.FindAllStringSubmatchIndex(line, -1) {
+		if offset < loc[0] || offset >= loc[1] {
+			continue
+		}
+		tool, name := line[loc[2]:loc[3]], line[loc[4]:loc[5]]
+		ann := findAnnotationConfig(toolConfigs, tool, name)
+		if ann == nil {
+			return nil
+		}
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "**%s:@%s**", tool, name)
+		if ann.Type != "" {
+			fmt.Fprintf(&b, " _(%s)_", ann.Type)
+		}
+		if ann.Doc != "" {
+			b.WriteString("\n\n" + ann.Doc)
+		}
+		// Document the parameter value under the cursor, if any
+		if loc[6] >= 0 && offset >= loc[6] && offset < loc[7] && ann.Params != nil {
+			if doc := ann.Params.Docs[wordAt(line, offset)]; doc != "" {
+				fmt.Fprintf(&b, "\n\n`%s`: %s", wordAt(line, offset), doc)
+			}
+		}
+
+		return &lspHover{
+			Contents: lspMarkupContent{Kind: "markdown", Value: b.String()},
+			Range: &lspRange{
+				Start: lspPosition{Line: params.Position.Line, Character: utf16Column(line, loc[0]+1)},
+				End:   lspPosition{Line: params.Position.Line, Character: utf16Column(line, loc[1]+1)},
+			},
+		}
+	}
+	return nil
+}
+
+// definition resolves generated symbols. On an annotation it jumps to the
+// declarations generated for the annotated type; on an identifier it jumps to
+// a matching top-level declaration in a generated file of the same package.
+func (s *LSPServer) definition(params lspTextDocumentPositionParams) []lspLocation {
+	path, err := uriToPath(params.TextDocument.URI)
+	if err != nil {
+		return nil
+	}
+	line, ok := s.lineAt(params.TextDocument.URI, params.Position.Line)
+	if !ok {
+		return nil
+	}
+	offset := byteOffset(line, params.Position.Character)
+
+	var locations []lspLocation
+	if typeName := s.annotatedTypeAt(params.TextDocument.URI, params.Position.Line, offset); typeName != "" {
+		for _, sym := range generatedSymbols(filepath.Dir(path), typeName) {
+			pos := lspPosition{Line: sym.Line - 1}
+			locations = append(locations, lspLocation{URI: pathToURI(sym.File), Range: lspRange{Start: pos, End: pos}})
+		}
+		return locations
+	}
+
+	word := wordAt(line, offset)
+	if word == "" {
+		return nil
+	}
+	return findGeneratedDecls(filepath.Dir(path), word)
+}
+
+// annotatedTypeAt returns the type that the annotation under the cursor
+// documents, or "" if the cursor is not on a type annotation.
+func (s *LSPServer) annotatedTypeAt(uri string, lineNum, offset int) string {
+	text, ok := s.text(uri)
+	if !ok {
+		return ""
+	}
+	lines := strings.Split(text, "\n")
+	if lineNum < 0 || lineNum >= len(lines) {
+		return ""
+	}
+	onAnnotation := false
+	for _, loc := range lspAnnotationRe.FindAllStringIndex(lines[lineNum], -1) {
+		if offset >= loc[0] && offset < loc[1] {
+			onAnnotation = true
+		}
+	}
+	if !onAnnotation {
+		return ""
+	}
+	// The annotated declaration follows the doc comment
+	for i := lineNum + 1; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(trimmed, "//") {
+			continue
+		}
+		if m := lspTypeDeclRe.FindStringSubmatch(trimmed); m != nil {
+			return m[1]
+		}
+		return ""
+	}
+	return ""
+}
+
+// scheduleValidation validates the package containing uri after delay,
+// replacing any validation already pending for that package.
+func (s *LSPServer) scheduleValidation(uri string, delay time.Duration) {
+	path, err := uriToPath(uri)
+	if err != nil || !strings.HasSuffix(path, ".go") {
+		return
+	}
+	dir := filepath.Dir(path)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.shutdown {
+		return
+	}
+	if t, ok := s.timers[dir]; ok {
+		t.Stop()
+	}
+	s.timers[dir] = time.AfterFunc(delay, func() {
+		s.validate(dir)
+	})
+}
+
+// validate runs all ValidatableTools on the package in dir, using the text of
+// open documents, and publishes the resulting diagnostics.
+func (s *LSPServer) validate(dir string) {
+	s.validateMu.Lock()
+	defer s.validateMu.Unlock()
+
+	s.mu.Lock()
+	overlay := make(map[string][]byte)
+	for uri, text := range s.docs {
+		if path, err := uriToPath(uri); err == nil {
+			overlay[path] = []byte(text)
+		}
+	}
+	tools, cfg := s.tools, s.cfg
+	s.mu.Unlock()
+
+	gen := genkit.New(genkit.Options{
+		Dir:                  dir,
+		IgnoreGeneratedFiles: true,
+		Overlay:              overlay,
+	})
+	if err := gen.Load("."); err != nil {
+		s.log.Warn("Failed to load %s: %v", dir, err)
+		return
+	}
+	silent := genkit.NewLoggerWithWriter(io.Discard)
+	diags := validateTools(gen, tools, cfg, silent)
+
+	byURI := make(map[string][]lspDiagnostic)
+	for _, d := range diags {
+		if d.File == "" {
+			continue
+		}
+		uri := pathToURI(d.File)
+		byURI[uri] = append(byURI[uri], s.toLSPDiagnostic(uri, d))
+	}
+
+	s.mu.Lock()
+	previous := s.published[dir]
+	s.published[dir] = make(map[string]bool, len(byURI))
+	for uri := range byURI {
+		s.published[dir][uri] = true
+	}
+	s.mu.Unlock()
+
+	// Clear diagnostics of files that no longer have any
+	for uri := range previous {
+		if _, ok := byURI[uri]; !ok {
+			byURI[uri] = []lspDiagnostic{}
+		}
+	}
+	for _, uri := range sortedKeys(byURI) {
+		s.notify("textDocument/publishDiagnostics", lspPublishDiagnosticsParams{
+			URI:         uri,
+			Diagnostics: byURI[uri],
+		})
+	}
+}
+
+// toLSPDiagnostic converts a devgen diagnostic to an LSP diagnostic.
+func (s *LSPServer) toLSPDiagnostic(uri string, d genkit.Diagnostic) lspDiagnostic {
+	var lines []string
+	if text, ok := s.text(uri); ok {
+		lines = strings.Split(text, "\n")
+	}
+	position := func(line, col int) lspPosition {
+		if line < 1 {
+			return lspPosition{}
+		}
+		p := lspPosition{Line: line - 1}
+		if line <= len(lines) && col > 0 {
+			p.Character = utf16Column(lines[line-1], col)
+		}
+		return p
+	}
+
+	start := position(d.Line, d.Column)
+	end := start
+	if d.EndLine > 0 {
+		end = position(d.EndLine, d.EndCol)
+	} else if start.Line < len(lines) {
+		// Highlight to the end of the line
+		end.Character = utf16Column(lines[start.Line], len(lines[start.Line])+1)
+	}
+
+	severity := lspSeverityError
+	switch d.Severity {
+	case genkit.DiagnosticWarning:
+		severity = lspSeverityWarning
+	case genkit.DiagnosticInfo:
+		severity = lspSeverityInformation
+	}
+	return lspDiagnostic{
+		Range:    lspRange{Start: start, End: end},
+		Severity: severity,
+		Code:     d.Code,
+		Source:   d.Tool,
+		Message:  d.Message,
+	}
+}
+
+// setDocument stores the text of an open document.
+func (s *LSPServer) setDocument(uri, text string) {
+	s.mu.Lock()
+	s.docs[uri] = text
+	s.mu.Unlock()
+}
+
+// text returns the text of uri, preferring the open document over the file
+// on disk.
+func (s *LSPServer) text(uri string) (string, bool) {
+	s.mu.Lock()
+	text, ok := s.docs[uri]
+	s.mu.Unlock()
+	if ok {
+		return text, true
+	}
+	path, err := uriToPath(uri)
+	if err != nil {
+		return "", false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+// lineAt returns the 0-based line of uri.
+func (s *LSPServer) lineAt(uri string, line int) (string, bool) {
+	text, ok := s.text(uri)
+	if !ok {
+		return "", false
+	}
+	lines := strings.Split(text, "\n")
+	if line < 0 || line >= len(lines) {
+		return "", false
+	}
+	return strings.TrimSuffix(lines[line], "\r"), true
+}
+
+// closeTools stops pending validations and releases the loaded plugins.
+// A validation that is still running fails on the closed plugins.
+func (s *LSPServer) closeTools() {
+	s.stopTimers()
+
+	s.mu.Lock()
+	loader := s.loader
+	s.loader = nil
+	s.mu.Unlock()
+	if loader != nil {
+		_ = loader.Close()
+	}
+}
+
+func (s *LSPServer) stopTimers() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for dir, t := range s.timers {
+		t.Stop()
+		delete(s.timers, dir)
+	}
+}
+
+func (s *LSPServer) reply(id json.RawMessage, result any, rpcErr *lspError) {
+	msg := map[string]any{"jsonrpc": "2.0", "id": id}
+	if rpcErr != nil {
+		msg["error"] = rpcErr
+	} else {
+		msg["result"] = result
+	}
+	s.write(msg)
+}
+
+func (s *LSPServer) notify(method string, params any) {
+	s.write(map[string]any{"jsonrpc": "2.0", "method": method, "params": params})
+}
+
+func (s *LSPServer) write(msg any) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if err := writeLSPMessage(s.out, msg); err != nil {
+		s.log.Warn("Failed to write message: %v", err)
+	}
+}
+
+func unmarshalParams(raw json.RawMessage, v any) *lspError {
+	if len(raw) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return &lspError{Code: lspInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+// findAnnotationConfig returns the configuration of tool:@name, or nil.
+func findAnnotationConfig(configs map[string]genkit.ToolConfig, tool, name string) *genkit.AnnotationConfig {
+	tc, ok := configs[tool]
+	if !ok {
+		return nil
+	}
+	for i := range tc.Annotations {
+		if tc.Annotations[i].Name == name {
+			return &tc.Annotations[i]
+		}
+	}
+	return nil
+}
+
+// wordAt returns the identifier at byte offset in line.
+func wordAt(line string, offset int) string {
+	isIdent := func(c byte) bool {
+		return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+	}
+	start, end := offset, offset
+	for start > 0 && isIdent(line[start-1]) {
+		start--
+	}
+	for end < len(line) && isIdent(line[end]) {
+		end++
+	}
+	return line[start:end]
+}
+
+// generatedFiles returns the generated Go files in dir with their content.
+func generatedFiles(dir string) map[string][]byte {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	files := make(map[string][]byte)
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".go") {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		content, err := os.ReadFile(path)
+		if err != nil || !strings.HasPrefix(string(content), "// Code generated") {
+			continue
+		}
+		files[path] = content
+	}
+	return files
+}
+
+// generatedSymbols returns the declarations generated for the named type in dir.
+func generatedSymbols(dir, name string) []ExplainSymbol {
+	files := generatedFiles(dir)
+	var result []ExplainSymbol
+	for _, path := range sortedKeys(files) {
+		result = append(result, extractGeneratedSymbols(path, files[path], name)...)
+	}
+	return result
+}
+
+// findGeneratedDecls returns the locations of top-level functions, methods,
+// types, vars and consts named name in generated files of dir.
+func findGeneratedDecls(dir, name string) []lspLocation {
+	files := generatedFiles(dir)
+	var locations []lspLocation
+	for _, path := range sortedKeys(files) {
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, path, files[path], parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		var idents []*ast.Ident
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				idents = append(idents, d.Name)
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					switch sp := spec.(type) {
+					case *ast.TypeSpec:
+						idents = append(idents, sp.Name)
+					case *ast.ValueSpec:
+						idents = append(idents, sp.Names...)
+					}
+				}
+			}
+		}
+		lines := strings.Split(string(files[path]), "\n")
+		for _, id := range idents {
+			if id.Name != name {
+				continue
+			}
+			pos := fset.Position(id.Pos())
+			line := lines[pos.Line-1]
+			locations = append(locations, lspLocation{
+				URI: pathToURI(path),
+				Range: lspRange{
+					Start: lspPosition{Line: pos.Line - 1, Character: utf16Column(line, pos.Column)},
+					End:   lspPosition{Line: pos.Line - 1, Character: utf16Column(line, pos.Column+len(name))},
+				},
+			})
+		}
+	}
+	return locations
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tlipoca9/devgen/genkit"
+)
+
+const lspTestSource = `package model
+
+// Status is a status.
+// enumgen:@enum(string, json)
+type Status int
+
+const (
+	// enumgen:@name
+	StatusActive Status = iota + 1
+	StatusInactive
+)
+`
+
+const lspTestGenerated = `// Code generated by enumgen. DO NOT EDIT.
+
+package model
+
+// StatusEnums is the enum helper for Status.
+var StatusEnums = []Status{}
+
+// IsValid reports whether x is a valid Status.
+func (x Status) IsValid() bool {
+	return true
+}
+`
+
+// lspTestClient drives an LSPServer through in-memory pipes.
+type lspTestClient struct {
+	t      *testing.T
+	in     *io.PipeWriter
+	out    *bufio.Reader
+	nextID int
+	done   chan error
+}
+
+func newLSPTestClient(t *testing.T) *lspTestClient {
+	t.Helper()
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	server := NewLSPServer(inR, outW, genkit.NewLoggerWithWriter(io.Discard))
+
+	c := &lspTestClient{t: t, in: inW, out: bufio.NewReader(outR), done: make(chan error, 1)}
+	go func() {
+		c.done <- server.Serve(context.Background())
+		outW.Close()
+	}()
+	return c
+}
+
+func (c *lspTestClient) send(method string, id int, params any) {
+	c.t.Helper()
+	msg := map[string]any{"jsonrpc": "2.0", "method": method, "params": params}
+	if id > 0 {
+		msg["id"] = id
+	}
+	if err := writeLSPMessage(c.in, msg); err != nil {
+		c.t.Fatalf("write %s: %v", method, err)
+	}
+}
+
+// call sends a request and returns its result, skipping notifications.
+func (c *lspTestClient) call(method string, params any, result any) {
+	c.t.Helper()
+	c.nextID++
+	c.send(method, c.nextID, params)
+	for {
+		msg := c.read()
+		if msg.Method != "" {
+			continue
+		}
+		if msg.Error != nil {
+			c.t.Fatalf("%s error: %v", method, msg.Error)
+		}
+		if err := json.Unmarshal(msg.Result, result); err != nil {
+			c.t.Fatalf("decode %s result: %v", method, err)
+		}
+		return
+	}
+}
+
+func (c *lspTestClient) read() *lspMessage {
+	c.t.Helper()
+	msg, err := readLSPMessage(c.out)
+	if err != nil {
+		c.t.Fatalf("read message: %v", err)
+	}
+	return msg
+}
+
+func (c *lspTestClient) close() {
+	c.t.Helper()
+	var result any
+	c.call("shutdown", nil, &result)
+	c.send("exit", 0, nil)
+	select {
+	case err := <-c.done:
+		if err != nil {
+			c.t.Errorf("Serve() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		c.t.Fatal("server did not exit")
+	}
+}
+
+func setupLSPTest(t *testing.T) (*lspTestClient, string) {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":        "module example.com/model\n\ngo 1.21\n",
+		"model.go":      lspTestSource,
+		"model_enum.go": lspTestGenerated,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("WriteFile error: %v", err)
+		}
+	}
+
+	c := newLSPTestClient(t)
+	var result map[string]any
+	c.call("initialize", map[string]any{"rootUri": pathToURI(dir)}, &result)
+	if _, ok := result["capabilities"]; !ok {
+		t.Fatalf("initialize result missing capabilities: %v", result)
+	}
+	c.send("initialized", 0, map[string]any{})
+	return c, pathToURI(filepath.Join(dir, "model.go"))
+}
+
+func position(uri string, line, character int) lspTextDocumentPositionParams {
+	return lspTextDocumentPositionParams{
+		TextDocument: lspTextDocumentIdentifier{URI: uri},
+		Position:     lspPosition{Line: line, Character: character},
+	}
+}
+
+// TestLSPServer_Completion tests annotation name and parameter completion
+func TestLSPServer_Completion(t *testing.T) {
+	c, uri := setupLSPTest(t)
+	defer c.close()
+
+	// Complete annotation names after "enumgen:@" on an unsaved buffer
+	text := strings.Replace(lspTestSource, "// Status is a status.", "// enumgen:@", 1)
+	c.send("textDocument/didOpen", 0, lspDidOpenParams{TextDocument: lspTextDocumentItem{URI: uri, Text: text}})
+
+	var list lspCompletionList
+	c.call("textDocument/completion", position(uri, 2, len("// enumgen:@")), &list)
+	labels := completionLabels(list)
+	if !strings.Contains(labels, "enum") || !strings.Contains(labels, "name") {
+		t.Errorf("annotation completion = %s, want enum and name", labels)
+	}
+
+	// Complete parameter values, excluding those already used
+	c.call("textDocument/completion", position(uri, 3, len("// enumgen:@enum(string, ")), &list)
+	labels = completionLabels(list)
+	if strings.Contains(labels, "string") || !strings.Contains(labels, "sql") {
+		t.Errorf("parameter completion = %s, want sql without string", labels)
+	}
+
+	// No completion outside comments
+	c.call("textDocument/completion", position(uri, 4, 4), &list)
+	if len(list.Items) != 0 {
+		t.Errorf("completion outside comment = %s, want none", completionLabels(list))
+	}
+}
+
+// TestLSPServer_Hover tests hover documentation for annotations
+func TestLSPServer_Hover(t *testing.T) {
+	c, uri := setupLSPTest(t)
+	defer c.close()
+
+	var hover *lspHover
+	c.call("textDocument/hover", position(uri, 3, len("// enumgen:@en")), &hover)
+	if hover == nil || !strings.Contains(hover.Contents.Value, "enumgen:@enum") {
+		t.Fatalf("hover = %+v, want enumgen:@enum docs", hover)
+	}
+	if hover.Range.Start.Character != 3 {
+		t.Errorf("hover range = %+v, want start at 3", hover.Range)
+	}
+
+	hover = nil
+	c.call("textDocument/hover", position(uri, 4, 6), &hover)
+	if hover != nil {
+		t.Errorf("hover outside annotation = %+v, want nil", hover)
+	}
+}
+
+// TestLSPServer_Definition tests jumping to generated symbols
+func TestLSPServer_Definition(t *testing.T) {
+	c, uri := setupLSPTest(t)
+	defer c.close()
+
+	// From an annotation to the code generated for the type
+	var locations []lspLocation
+	c.call("textDocument/definition", position(uri, 3, len("// enumgen:@en")), &locations)
+	if len(locations) != 2 || !strings.HasSuffix(locations[0].URI, "model_enum.go") {
+		t.Fatalf("definition from annotation = %+v, want 2 generated symbols", locations)
+	}
+
+	// From an identifier to its generated declaration
+	text := lspTestSource + "\nvar _ = StatusEnums\n"
+	c.send("textDocument/didOpen", 0, lspDidOpenParams{TextDocument: lspTextDocumentItem{URI: uri, Text: text}})
+	locations = nil
+	c.call("textDocument/definition", position(uri, 12, len("var _ = Stat")), &locations)
+	if len(locations) != 1 || locations[0].Range.Start.Line != 5 || locations[0].Range.Start.Character != 4 {
+		t.Errorf("definition of StatusEnums = %+v, want model_enum.go:5:4", locations)
+	}
+}
+
+// TestUTF16Column tests conversion between byte columns and UTF-16 offsets
+func TestUTF16Column(t *testing.T) {
+	line := "// 状态 enumgen:@enum"
+	idx := strings.Index(line, "enumgen")
+	if got := utf16Column(line, idx+1); got != 6 {
+		t.Errorf("utf16Column() = %d, want 6", got)
+	}
+	if got := byteOffset(line, 6); got != idx {
+		t.Errorf("byteOffset() = %d, want %d", got, idx)
+	}
+}
+
+func completionLabels(list lspCompletionList) string {
+	labels := make([]string, 0, len(list.Items))
+	for _, item := range list.Items {
+		labels = append(labels, item.Label)
+	}
+	return strings.Join(labels, ",")
+}
@@ -4,12 +4,15 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime/debug"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/fang"
 	"github.com/spf13/cobra"
@@ -61,8 +64,8 @@ func versionTemplate() string {
 	}
 	ver = strings.TrimSuffix(ver, "+dirty")
 
-	// Format like: devgen version v0.3.7 (commit: ea1998c, built: 2026-01-11T12:00:15Z)
-	return fmt.Sprintf("devgen version %s (commit: %s, built: %s)\n", ver, commit, date)
+	// Format like: devgen version v0.3.7 (commit: ea1998c, built: 2026-01-11T12:00:15Z, genkit: v0.4.0)
+	return fmt.Sprintf("devgen version %s (commit: %s, built: %s, genkit: %s)\n", ver, commit, date, genkit.APIVersion)
 }
 
 // builtinTools is the list of built-in code generation tools.
@@ -83,6 +86,7 @@ func main() {
 func rootCmd() *cobra.Command {
 	var dryRun bool
 	var jsonOutput bool
+	var format string
 	var includeTests bool
 
 	cmd := &cobra.Command{
@@ -96,20 +100,32 @@ External plugins can be configured in devgen.toml:
   [[plugins]]
   name = "customgen"
   path = "./tools/customgen"
-  type = "source"  # source | plugin`,
+  type = "source"  # source | plugin | exec | wasm`,
 		Version: version,
 		Example: `  devgen ./...              # all packages
   devgen ./pkg/model        # specific package
   devgen ./pkg/...          # all packages under pkg/
   devgen --dry-run ./...    # validate without writing files
-  devgen --dry-run --json ./...  # JSON output for IDE integration`,
+  devgen --dry-run --json ./...  # JSON output for IDE integration
+  devgen --dry-run --format sarif ./... > devgen.sarif  # SARIF for code scanning
+  devgen --dry-run --format junit ./... > devgen.xml    # JUnit XML for test dashboards`,
 		Args: cobra.ArbitraryArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
 				return cmd.Help()
 			}
+			if !dryRun && cmd.Flags().Changed("format") {
+				return fmt.Errorf("--format requires --dry-run")
+			}
+			if jsonOutput {
+				format = string(genkit.ReportFormatJSON)
+			}
+			reportFormat, err := genkit.ParseReportFormat(format)
+			if err != nil {
+				return err
+			}
 			if dryRun {
-				return runDryRun(cmd.Context(), args, jsonOutput, includeTests)
+				return runDryRun(cmd.Context(), args, reportFormat, includeTests)
 			}
 			return run(cmd.Context(), args, includeTests)
 		},
@@ -119,7 +135,10 @@ External plugins can be configured in devgen.toml:
 	// Add flags
 	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Validate and preview without writing files")
 	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format (for IDE integration, requires --dry-run)")
+	cmd.Flags().StringVar(&format, "format", string(genkit.ReportFormatText),
+		"Diagnostics output format: text, json, sarif, junit (requires --dry-run)")
 	cmd.Flags().BoolVar(&includeTests, "include-tests", false, "Also generate *_test.go files")
+	cmd.MarkFlagsMutuallyExclusive("json", "format")
 
 	// Add config subcommand
 	cmd.AddCommand(configCmd())
@@ -127,6 +146,21 @@ External plugins can be configured in devgen.toml:
 	// Add rules subcommand
 	cmd.AddCommand(rulesCmd())
 
+	// Add explain subcommand
+	cmd.AddCommand(explainCmd())
+
+	// Add fix subcommand
+	cmd.AddCommand(fixCmd())
+
+	// Add lsp subcommand
+	cmd.AddCommand(lspCmd())
+
+	// Add mcp subcommand
+	cmd.AddCommand(mcpCmd())
+
+	// Add plugins subcommand
+	cmd.AddCommand(pluginsCmd())
+
 	return cmd
 }
 
@@ -253,29 +287,11 @@ func runConfig(ctx context.Context, jsonOutput bool) error {
 		cfg = &genkit.Config{}
 	}
 
-	// Collect all tools
-	tools := make([]genkit.Tool, 0, len(builtinTools)+len(cfg.Plugins))
-	toolNames := make(map[string]bool)
-
-	// Load external plugins first
-	if len(cfg.Plugins) > 0 {
-		loader := genkit.NewPluginLoader("")
-		pluginTools, err := loader.LoadPlugins(ctx, cfg)
-		if err != nil {
-			return fmt.Errorf("load plugins: %w", err)
-		}
-		for _, tool := range pluginTools {
-			tools = append(tools, tool)
-			toolNames[tool.Name()] = true
-		}
-	}
-
-	// Add built-in tools
-	for _, tool := range builtinTools {
-		if !toolNames[tool.Name()] {
-			tools = append(tools, tool)
-		}
+	tools, loader, err := loadTools(ctx, cfg)
+	if err != nil {
+		return err
 	}
+	defer func() { _ = loader.Close() }()
 
 	// Collect configs from tools
 	toolConfigs := genkit.CollectToolConfigs(tools)
@@ -350,71 +366,30 @@ func formatStringSlice(ss []string) string {
 	return "[" + strings.Join(quoted, ", ") + "]"
 }
 
-func runDryRun(ctx context.Context, args []string, jsonOutput bool, includeTests bool) error {
-	// Use silent logger for JSON output to avoid polluting stdout
+func runDryRun(ctx context.Context, args []string, format genkit.ReportFormat, includeTests bool) error {
+	// Use silent logger for machine-readable output to avoid polluting stdout
 	var log *genkit.Logger
-	if jsonOutput {
+	if format != genkit.ReportFormatText {
 		log = genkit.NewLoggerWithWriter(io.Discard)
 	} else {
 		log = genkit.NewLogger()
 	}
-	result := &genkit.DryRunResult{
-		Success: true,
-		Files:   make(map[string]string),
-	}
-
-	// Determine config search directory from first argument
-	configSearchDir, err := os.Getwd()
+	configSearchDir, err := findConfigSearchDir(args)
 	if err != nil {
-		return fmt.Errorf("get working directory: %w", err)
-	}
-
-	// If first arg is a relative path, use it as the starting point for config search
-	if len(args) > 0 {
-		arg := args[0]
-		arg = strings.TrimSuffix(arg, "/...")
-		arg = strings.TrimSuffix(arg, "...")
-		if arg == "." || arg == "" {
-			// Use current directory
-		} else if strings.HasPrefix(arg, "./") || strings.HasPrefix(arg, "../") || !strings.HasPrefix(arg, "/") {
-			absPath, err := filepath.Abs(arg)
-			if err == nil {
-				if info, err := os.Stat(absPath); err == nil && info.IsDir() {
-					configSearchDir = absPath
-				}
-			}
-		}
+		return err
 	}
 
 	cfg, err := genkit.LoadConfig(configSearchDir)
 	if err != nil {
+		log.Warn("Failed to load devgen.toml: %v", err)
 		cfg = &genkit.Config{}
 	}
 
-	// Collect all tools: built-in + plugins
-	tools := make([]genkit.Tool, 0, len(builtinTools)+len(cfg.Plugins))
-	toolNames := make(map[string]bool)
-
-	// Load external plugins first
-	if len(cfg.Plugins) > 0 {
-		loader := genkit.NewPluginLoader("")
-		pluginTools, err := loader.LoadPlugins(ctx, cfg)
-		if err != nil {
-			return fmt.Errorf("load plugins: %w", err)
-		}
-		for _, tool := range pluginTools {
-			tools = append(tools, tool)
-			toolNames[tool.Name()] = true
-		}
-	}
-
-	// Add built-in tools
-	for _, tool := range builtinTools {
-		if !toolNames[tool.Name()] {
-			tools = append(tools, tool)
-			toolNames[tool.Name()] = true
-		}
+	tools, loader, err := loadTools(ctx, cfg)
+	if err != nil {
+		return err
 	}
+	defer func() { _ = loader.Close() }()
 
 	gen := genkit.New(genkit.Options{
 		IgnoreGeneratedFiles: true,
@@ -423,22 +398,55 @@ func runDryRun(ctx context.Context, args []string, jsonOutput bool, includeTests
 	if err := gen.Load(args...); err != nil {
 		return fmt.Errorf("load: %w", err)
 	}
+
+	result, files := dryRunTools(gen, tools, loader, cfg, log)
+	for path, content := range files {
+		// Store first 500 bytes as preview
+		preview := string(content)
+		if len(preview) > 500 {
+			preview = preview[:500] + "\n... (truncated)"
+		}
+		result.Files[path] = preview
+	}
+
+	// Output result
+	reportOpts := genkit.ReportOptions{ToolVersion: version, BaseDir: configSearchDir}
+	if configPath, err := genkit.FindConfig(configSearchDir); err == nil && configPath != "" {
+		reportOpts.BaseDir = filepath.Dir(configPath)
+	}
+	switch format {
+	case genkit.ReportFormatJSON:
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	case genkit.ReportFormatSARIF:
+		return genkit.WriteSARIF(os.Stdout, result, reportOpts)
+	case genkit.ReportFormatJUnit:
+		return genkit.WriteJUnit(os.Stdout, result, reportOpts)
+	}
+
+	return printDryRunResult(result, log)
+}
+
+// dryRunTools validates the loaded packages and, if there are no errors,
+// runs the tools. It returns the result, without file previews, and the
+// files the tools would generate.
+func dryRunTools(gen *genkit.Generator, tools []genkit.Tool, loader *genkit.PluginLoader, cfg *genkit.Config, log *genkit.Logger) (*genkit.DryRunResult, map[string][]byte) {
+	result := &genkit.DryRunResult{
+		Success: true,
+		Files:   make(map[string]string),
+	}
 	result.Stats.PackagesLoaded = len(gen.Packages)
 
 	// Run validation for tools that support it
-	for _, tool := range tools {
-		if vt, ok := tool.(genkit.ValidatableTool); ok {
-			diagnostics := vt.Validate(gen, log)
-			for _, d := range diagnostics {
-				result.AddDiagnostic(d)
-			}
-		}
+	for _, d := range validateTools(gen, tools, cfg, log) {
+		result.AddDiagnostic(d)
 	}
 
 	// If no validation errors, try to generate (dry-run)
 	if result.Success {
 		for _, tool := range tools {
-			if err := tool.Run(gen, log); err != nil {
+			if err := runTool(gen, loader, tool, log); err != nil {
 				// Convert run error to diagnostic if possible
 				result.Success = false
 				result.AddDiagnostic(genkit.Diagnostic{
@@ -449,38 +457,42 @@ func runDryRun(ctx context.Context, args []string, jsonOutput bool, includeTests
 			}
 		}
 	}
+	if !result.Success {
+		return result, nil
+	}
 
-	// Get generated files preview
-	if result.Success {
-		files, err := gen.DryRun()
-		if err != nil {
-			result.Success = false
-			result.AddDiagnostic(genkit.Diagnostic{
-				Severity: genkit.DiagnosticError,
-				Message:  fmt.Sprintf("generate: %v", err),
-				Tool:     "devgen",
-			})
-		} else {
-			result.Stats.FilesGenerated = len(files)
-			for path, content := range files {
-				// Store first 500 bytes as preview
-				preview := string(content)
-				if len(preview) > 500 {
-					preview = preview[:500] + "\n... (truncated)"
-				}
-				result.Files[path] = preview
-			}
+	// Get generated files
+	files, err := gen.DryRun()
+	var writeErr *genkit.WriteError
+	if errors.As(err, &writeErr) {
+		result.Success = false
+		for _, d := range writeErr.Diagnostics {
+			result.AddDiagnostic(d)
 		}
+		return result, nil
+	} else if err != nil {
+		result.Success = false
+		result.AddDiagnostic(genkit.Diagnostic{
+			Severity: genkit.DiagnosticError,
+			Message:  fmt.Sprintf("generate: %v", err),
+			Tool:     "devgen",
+		})
+		return result, nil
 	}
+	result.Stats.FilesGenerated = len(files)
+	return result, files
+}
 
-	// Output result
-	if jsonOutput {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(result)
+// validateTools runs all tools that implement ValidatableTool and applies
+// //devgen:ignore directives and severity overrides to their diagnostics.
+func validateTools(gen *genkit.Generator, tools []genkit.Tool, cfg *genkit.Config, log *genkit.Logger) []genkit.Diagnostic {
+	var diagnostics []genkit.Diagnostic
+	for _, tool := range tools {
+		if vt, ok := tool.(genkit.ValidatableTool); ok {
+			diagnostics = append(diagnostics, vt.Validate(gen, log)...)
+		}
 	}
-
-	return printDryRunResult(result, log)
+	return gen.ApplyDiagnosticsConfig(diagnostics, cfg.Diagnostics)
 }
 
 func printDryRunResult(result *genkit.DryRunResult, log *genkit.Logger) error {
@@ -526,29 +538,9 @@ func printDryRunResult(result *genkit.DryRunResult, log *genkit.Logger) error {
 func run(ctx context.Context, args []string, includeTests bool) error {
 	log := genkit.NewLogger()
 
-	// Determine config search directory from first argument
-	configSearchDir, err := os.Getwd()
+	configSearchDir, err := findConfigSearchDir(args)
 	if err != nil {
-		return fmt.Errorf("get working directory: %w", err)
-	}
-
-	// If first arg is a relative path, use it as the starting point for config search
-	if len(args) > 0 {
-		arg := args[0]
-		// Handle patterns like "./...", "./pkg/...", "./pkg"
-		arg = strings.TrimSuffix(arg, "/...")
-		arg = strings.TrimSuffix(arg, "...")
-		if arg == "." || arg == "" {
-			// Use current directory
-		} else if strings.HasPrefix(arg, "./") || strings.HasPrefix(arg, "../") || !strings.HasPrefix(arg, "/") {
-			// Relative path - resolve it
-			absPath, err := filepath.Abs(arg)
-			if err == nil {
-				if info, err := os.Stat(absPath); err == nil && info.IsDir() {
-					configSearchDir = absPath
-				}
-			}
-		}
+		return err
 	}
 
 	cfg, err := genkit.LoadConfig(configSearchDir)
@@ -558,34 +550,16 @@ func run(ctx context.Context, args []string, includeTests bool) error {
 		cfg = &genkit.Config{}
 	}
 
-	// Collect all tools: built-in + plugins
-	tools := make([]genkit.Tool, 0, len(builtinTools)+len(cfg.Plugins))
-	toolNames := make(map[string]bool)
-
-	// Load external plugins first (they can override built-in tools)
-	if len(cfg.Plugins) > 0 {
-		loader := genkit.NewPluginLoader("")
-		pluginTools, err := loader.LoadPlugins(ctx, cfg)
-		if err != nil {
-			return fmt.Errorf("load plugins: %w", err)
-		}
-		for _, tool := range pluginTools {
-			tools = append(tools, tool)
-			toolNames[tool.Name()] = true
-		}
-		if len(pluginTools) > 0 {
-			log.Load("Loaded %v plugin(s)", len(pluginTools))
-			for _, tool := range pluginTools {
-				log.Item("'%s'", tool.Name())
-			}
-		}
+	// Collect all tools: plugins first, then built-in tools they do not override
+	tools, loader, err := loadTools(ctx, cfg)
+	if err != nil {
+		return err
 	}
-
-	// Add built-in tools (skip if overridden by plugin)
-	for _, tool := range builtinTools {
-		if !toolNames[tool.Name()] {
-			tools = append(tools, tool)
-			toolNames[tool.Name()] = true
+	defer func() { _ = loader.Close() }()
+	if pluginTools := tools[:len(tools)-countBuiltin(tools)]; len(pluginTools) > 0 {
+		log.Load("Loaded %v plugin tool(s)", len(pluginTools))
+		for _, tool := range pluginTools {
+			log.Item("'%s'", tool.Name())
 		}
 	}
 
@@ -604,7 +578,7 @@ func run(ctx context.Context, args []string, includeTests bool) error {
 
 	// Run all tools
 	for _, tool := range tools {
-		if err := tool.Run(gen, log); err != nil {
+		if err := runTool(gen, loader, tool, log); err != nil {
 			return fmt.Errorf("%s: %w", tool.Name(), err)
 		}
 	}
@@ -630,14 +604,85 @@ func run(ctx context.Context, args []string, includeTests bool) error {
 	return nil
 }
 
+// findConfigSearchDir determines where to start searching for devgen.toml.
+// If the first package argument is a relative directory, it is used as the
+// starting point; otherwise the current working directory is used.
+func findConfigSearchDir(args []string) (string, error) {
+	configSearchDir, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("get working directory: %w", err)
+	}
+
+	if len(args) > 0 {
+		arg := args[0]
+		// Handle patterns like "./...", "./pkg/...", "./pkg"
+		arg = strings.TrimSuffix(arg, "/...")
+		arg = strings.TrimSuffix(arg, "...")
+		if arg == "." || arg == "" {
+			// Use current directory
+		} else if strings.HasPrefix(arg, "./") || strings.HasPrefix(arg, "../") || !strings.HasPrefix(arg, "/") {
+			// Relative path - resolve it
+			absPath, err := filepath.Abs(arg)
+			if err == nil {
+				if info, err := os.Stat(absPath); err == nil && info.IsDir() {
+					configSearchDir = absPath
+				}
+			}
+		}
+	}
+	return configSearchDir, nil
+}
+
+// loadTools returns all tools: external plugin tools first, followed by
+// built-in tools that are not overridden by a plugin tool of the same name.
+// The returned loader holds the write policies of the plugin tools and must
+// be closed once the tools are no longer used.
+func loadTools(ctx context.Context, cfg *genkit.Config) ([]genkit.Tool, *genkit.PluginLoader, error) {
+	loader := genkit.NewPluginLoader("")
+	tools, err := loader.LoadTools(ctx, cfg, builtinTools)
+	if err != nil {
+		_ = loader.Close()
+		return nil, nil, fmt.Errorf("load plugins: %w", err)
+	}
+	if err := genkit.ApplyOptions(tools, cfg); err != nil {
+		_ = loader.Close()
+		return nil, nil, fmt.Errorf("tool options: %w", err)
+	}
+	return tools, loader, nil
+}
+
+// runTool runs tool, restricting the files a plugin tool generates to the
+// paths its plugin may write to.
+func runTool(gen *genkit.Generator, loader *genkit.PluginLoader, tool genkit.Tool, log *genkit.Logger) error {
+	gen.SetWritePolicy(loader.WritePolicy(tool.Name()))
+	defer gen.SetWritePolicy(nil)
+	return tool.Run(gen, log)
+}
+
+// countBuiltin returns the number of built-in tools in tools.
+func countBuiltin(tools []genkit.Tool) int {
+	n := 0
+	for _, tool := range tools {
+		for _, b := range builtinTools {
+			if tool == b {
+				n++
+				break
+			}
+		}
+	}
+	return n
+}
+
 func rulesCmd() *cobra.Command {
 	var agentName string
 	var writeFiles bool
+	var checkFiles bool
+	var project bool
 	var listAgents bool
 	var noColor bool
 
 	cmd := &cobra.Command{
-		Use:   "rules",
+		Use:   "rules [packages]",
 		Short: "Generate AI rules for coding assistants",
 		Long: `Generate AI-friendly rules/documentation for AI coding assistants.
 
@@ -647,9 +692,17 @@ step-by-step documentation with examples.
 
 SUPPORTED AGENTS:
   all          All supported agents (generate for all at once)
+  agents       AGENTS.md (single file with all rules)
+  claude       CLAUDE.md (single file with all rules)
+  cline        Cline (.clinerules/*.md)
   codebuddy    Tencent CodeBuddy (.codebuddy/rules/*.mdc)
+  copilot      GitHub Copilot (.github/copilot-instructions.md and
+               .github/instructions/*.instructions.md)
   cursor       Cursor AI (.cursor/rules/*.mdc)
   kiro         Kiro AI (.kiro/steering/*.md)
+  windsurf     Windsurf (.windsurf/rules/*.md)
+
+  Further agents can be declared under [[rules.adapters]] in devgen.toml.
 
 WHAT GETS GENERATED:
   Each tool that implements the RuleTool interface will generate a rule file
@@ -662,7 +715,20 @@ WHAT GETS GENERATED:
 
 OUTPUT:
   By default, rules are printed to stdout.
-  Use -w/--write to write files to the appropriate directory.`,
+  Use -w/--write to write files to the appropriate directory. With --agent
+  all, files shared with other tools (AGENTS.md, CLAUDE.md and
+  .github/copilot-instructions.md) are only written if they exist or their
+  agent is configured under [rules.agents] in devgen.toml.
+  Use --check to compare the files on disk with the generated rules, print
+  diffs and fail if they are out of date (e.g. in CI). With --agent all,
+  agents without rule files on disk are skipped.
+
+PROJECT RULE:
+  With --project (or project = true under [rules] in devgen.toml), devgen
+  also loads the packages (default ./...) and generates the devgen-project
+  rule. It lists the actual enums and their values, validated structs and
+  their constraints, converters and delegator interfaces, scoped with globs
+  to the packages involved.`,
 		Example: `  # List supported agents
   devgen rules --list-agents
 
@@ -676,10 +742,24 @@ OUTPUT:
   devgen rules --agent all -w
 
   # Generate and write rules for Kiro
-  devgen rules --agent kiro -w`,
+  devgen rules --agent kiro -w
+
+  # Fail if committed rule files are out of date
+  devgen rules --agent all --check
+
+  # Also generate the project rule from the annotated declarations
+  devgen rules --agent cursor -w --project ./...`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			log := genkit.NewLogger().SetNoColor(noColor)
 			rulesCmd := NewRulesCommand(log)
+			if project {
+				if len(args) == 0 {
+					args = []string{"./..."}
+				}
+				rulesCmd.SetProjectPatterns(args)
+			} else if len(args) > 0 {
+				return fmt.Errorf("packages can only be given with --project")
+			}
 
 			if listAgents {
 				return listSupportedAgents(rulesCmd)
@@ -687,6 +767,12 @@ OUTPUT:
 			if agentName == "" {
 				return fmt.Errorf("--agent is required. Use --list-agents to see supported agents")
 			}
+			if checkFiles {
+				if writeFiles {
+					return fmt.Errorf("--check cannot be used with -w/--write")
+				}
+				return rulesCmd.Check(cmd.Context(), agentName)
+			}
 			if agentName == "all" {
 				return rulesCmd.ExecuteAll(cmd.Context(), writeFiles)
 			}
@@ -696,28 +782,435 @@ OUTPUT:
 
 	cmd.Flags().StringVar(&agentName, "agent", "", "Target AI agent (e.g., codebuddy, kiro, all)")
 	cmd.Flags().BoolVarP(&writeFiles, "write", "w", false, "Write rules to files instead of stdout")
+	cmd.Flags().BoolVar(&checkFiles, "check", false, "Check that rule files on disk are up to date")
+	cmd.Flags().BoolVar(&project, "project", false, "Also generate the project rule from the annotated declarations of the packages")
 	cmd.Flags().BoolVar(&listAgents, "list-agents", false, "List supported AI agents")
 	cmd.Flags().BoolVar(&noColor, "no-color", false, "Disable colored output")
 
+	cmd.AddCommand(rulesImportCmd())
+	cmd.AddCommand(rulesLintCmd())
+
+	return cmd
+}
+
+func rulesImportCmd() *cobra.Command {
+	var from string
+	var writeFiles bool
+	var noColor bool
+
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import existing agent rule files into rules.source_dir",
+		Long: `Import the rule files of an AI agent as project rule sources.
+
+The rule files in the agent's output directory (e.g. .cursor/rules/*.mdc)
+are parsed back into rules and written as Markdown files with YAML
+frontmatter to rules.source_dir (default .devgen/rules), so that a
+project can keep one source of truth and generate the files of every
+agent from it with 'devgen rules --agent all -w'.
+
+Files that devgen generated (with managed sections) and existing source
+files are skipped. Supported agents: cline, codebuddy, copilot (path-specific
+instructions files), cursor, kiro, windsurf.`,
+		Example: `  # Preview the source files converted from Cursor rules
+  devgen rules import --from cursor
+
+  # Write them to rules.source_dir
+  devgen rules import --from cursor -w`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if from == "" {
+				return fmt.Errorf("--from is required. Use 'devgen rules --list-agents' to see supported agents")
+			}
+			log := genkit.NewLogger().SetNoColor(noColor)
+			return NewRulesCommand(log).Import(from, writeFiles)
+		},
+	}
+
+	cmd.Flags().StringVar(&from, "from", "", "Agent to import rules from (e.g., cursor, kiro)")
+	cmd.Flags().BoolVarP(&writeFiles, "write", "w", false, "Write source files instead of printing them")
+	cmd.Flags().BoolVar(&noColor, "no-color", false, "Disable colored output")
+
+	return cmd
+}
+
+func rulesLintCmd() *cobra.Command {
+	var noColor bool
+
+	cmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Check rule sources for mistakes agents silently ignore",
+		Long: `Check the rules of all sources: rules.source_dir, devgen's own rules,
+tools and plugins, and the project rule if rules.project is set.
+
+Reported issues:
+  - rule files that fail to load, and unknown frontmatter keys
+  - rule names defined more than once
+  - globs that are invalid or match no file in the working directory
+  - empty descriptions of rules that do not always apply
+
+Exits with a non-zero status if any issue is found.`,
+		Example: `  # Lint rules, e.g. in CI before 'devgen rules --check'
+  devgen rules lint`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log := genkit.NewLogger().SetNoColor(noColor)
+			return NewRulesCommand(log).Lint(cmd.Context())
+		},
+	}
+
+	cmd.Flags().BoolVar(&noColor, "no-color", false, "Disable colored output")
+
 	return cmd
 }
 
+func explainCmd() *cobra.Command {
+	var jsonOutput bool
+	var includeTests bool
+	var noColor bool
+
+	cmd := &cobra.Command{
+		Use:   "explain <package> <Type> | <file.go>:<line>",
+		Short: "Explain what devgen generates for a type",
+		Long: `Explain what devgen generates for a single type.
+
+For the selected type this command lists:
+  • The tools that apply to it
+  • The annotations each tool parses, with their flags and key=value options
+  • Every generated method, function, type, var and const that belongs to
+    the type, with the generated source for that symbol only
+
+This lets reviewers and AI assistants understand generated APIs without
+reading whole *_enum.go or *_validate.go files. No files are written.`,
+		Example: `  # Explain a type by name
+  devgen explain ./pkg/model User
+
+  # Explain the type declared at a file position
+  devgen explain ./pkg/model/user.go:42
+
+  # JSON output for tooling
+  devgen explain --json ./pkg/model OrderStatus`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log := genkit.NewLogger().SetNoColor(noColor)
+			return NewExplainCommand(log).Execute(cmd.Context(), args, jsonOutput, includeTests)
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+	cmd.Flags().BoolVar(&includeTests, "include-tests", false, "Also include symbols from generated *_test.go files")
+	cmd.Flags().BoolVar(&noColor, "no-color", false, "Disable colored output")
+
+	return cmd
+}
+
+func fixCmd() *cobra.Command {
+	var dryRun bool
+	var noColor bool
+
+	cmd := &cobra.Command{
+		Use:   "fix [packages]",
+		Short: "Apply suggested fixes for diagnostics",
+		Long: `Apply the suggested fixes attached to devgen diagnostics.
+
+Tools can attach machine-readable fixes to their diagnostics, for example:
+  • delegatorgen: replace @cache(ttl=5min) with @cache(ttl=5m)
+  • enumgen:      fill in a missing @name parameter
+  • devgen:       remove unused //devgen:ignore directives
+
+The same fixes are included in 'devgen --dry-run --json' output under
+"fixes", which IDEs use to offer quick fixes. This command applies the first
+fix of every diagnostic in bulk. Fixes that overlap are skipped; run the
+command again to apply them.`,
+		Example: `  # Apply all fixes
+  devgen fix ./...
+
+  # Show fixes without modifying files
+  devgen fix --dry-run ./...`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log := genkit.NewLogger().SetNoColor(noColor)
+			return runFix(cmd.Context(), args, dryRun, log)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "Show fixes without modifying files")
+	cmd.Flags().BoolVar(&noColor, "no-color", false, "Disable colored output")
+
+	return cmd
+}
+
+func lspCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lsp",
+		Short: "Run the devgen language server over stdio",
+		Long: `Run a Language Server Protocol server over stdin/stdout.
+
+The server provides editor support for devgen annotations:
+  • Completion of tool names, annotations and parameter values
+  • Hover documentation for annotations
+  • Live diagnostics from tool validation, including unsaved changes
+  • Go to definition for generated symbols and from an annotation to
+    the code generated for the annotated type
+
+Tools and annotation metadata are loaded from devgen.toml in the workspace
+root, so plugins are supported the same way as 'devgen config'.`,
+		Example: `  # Neovim (nvim-lspconfig)
+  vim.lsp.start({ name = "devgen", cmd = { "devgen", "lsp" } })
+
+  # Helix (languages.toml)
+  [language-server.devgen]
+  command = "devgen"
+  args = ["lsp"]`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log := genkit.NewLoggerWithWriter(os.Stderr).SetNoColor(true)
+			return NewLSPServer(os.Stdin, os.Stdout, log).Serve(cmd.Context())
+		},
+	}
+	return cmd
+}
+
+func mcpCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mcp",
+		Short: "Run the devgen Model Context Protocol server over stdio",
+		Long: `Run a Model Context Protocol (MCP) server over stdin/stdout.
+
+AI assistants can call these tools to check annotations against the real
+generators before proposing edits:
+  list_annotations     Annotations of the tools with docs and parameter values
+  validate_package     Validation diagnostics of packages (no generation)
+  preview_generation   Diagnostics and the files devgen would generate
+  get_rules            The devgen AI rules of the project
+
+validate_package and preview_generation accept file contents that replace
+the files on disk, so edits can be checked before they are made. Nothing is
+written and no network is used. devgen.toml and plugins are loaded from the
+working directory on every call.`,
+		Example: `  # Claude Code
+  claude mcp add devgen -- devgen mcp
+
+  # Cursor (.cursor/mcp.json)
+  { "mcpServers": { "devgen": { "command": "devgen", "args": ["mcp"] } } }`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log := genkit.NewLoggerWithWriter(os.Stderr).SetNoColor(true)
+			return NewMCPServer(os.Stdin, os.Stdout, log).Serve(cmd.Context())
+		},
+	}
+	return cmd
+}
+
+func runFix(ctx context.Context, args []string, dryRun bool, log *genkit.Logger) error {
+	configSearchDir, err := findConfigSearchDir(args)
+	if err != nil {
+		return err
+	}
+	cfg, err := genkit.LoadConfig(configSearchDir)
+	if err != nil {
+		log.Warn("Failed to load devgen.toml: %v", err)
+		cfg = &genkit.Config{}
+	}
+	tools, loader, err := loadTools(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = loader.Close() }()
+
+	gen := genkit.New(genkit.Options{IgnoreGeneratedFiles: true})
+	if err := gen.Load(args...); err != nil {
+		return fmt.Errorf("load: %w", err)
+	}
+
+	diagnostics := validateTools(gen, tools, cfg, genkit.NewLoggerWithWriter(io.Discard))
+	files, skipped, err := genkit.ApplyFixes(diagnostics)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		log.Done("No fixes to apply")
+		return nil
+	}
+
+	for _, d := range diagnostics {
+		if len(d.Fixes) > 0 {
+			log.Find("%s:%d: %s", d.File, d.Line, d.Fixes[0].Message)
+		}
+	}
+	if len(skipped) > 0 {
+		log.Warn("Skipped %v overlapping fix(es), run again to apply them", len(skipped))
+	}
+	if dryRun {
+		return nil
+	}
+
+	for _, path := range sortedKeys(files) {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, files[path], info.Mode().Perm()); err != nil {
+			return fmt.Errorf("write %s: %w", path, err)
+		}
+	}
+	log.Done("Fixed %v file(s)", len(files))
+	for _, path := range sortedKeys(files) {
+		log.Item("%s", path)
+	}
+	return nil
+}
+
 func listSupportedAgents(rulesCmd *RulesCommand) error {
+	if _, err := rulesCmd.loadConfig(); err != nil {
+		return err
+	}
 	agents := rulesCmd.ListAgents()
 
 	fmt.Println("Supported AI agents:")
 	fmt.Println()
 
-	// Get adapter registry to show output directories
-	registry := genkit.NewAdapterRegistry()
 	for _, name := range agents {
-		adapter, ok := registry.Get(name)
+		adapter, ok := rulesCmd.registry.Get(name)
 		if !ok {
 			continue
 		}
-		fmt.Printf("  %-12s  %s/\n", name, adapter.OutputDir())
+		output := adapter.OutputDir() + "/"
+		if _, ok := adapter.(genkit.AggregatingAdapter); ok {
+			// Show the file a rule is aggregated into
+			filename, _, _ := adapter.Transform(genkit.Rule{Name: "*"})
+			output = path.Join(adapter.OutputDir(), filename)
+		}
+		fmt.Printf("  %-12s  %s\n", name, output)
 	}
 	fmt.Println()
-	fmt.Println("Usage: devgen rules --agent <name> [-w]")
+	fmt.Println("Usage: devgen rules --agent <name> [-w | --check]")
 	return nil
 }
+
+func pluginsCmd() *cobra.Command {
+	var noColor bool
+
+	cmd := &cobra.Command{
+		Use:   "plugins",
+		Short: "Manage external plugins",
+		Long: `Manage the external plugins configured in devgen.toml.
+
+Plugins can be referenced by local path or by Go module:
+
+  [[plugins]]
+  name = "markgen"
+  module = "example.com/gen/markgen"
+  version = "v1.2.3"
+
+Module plugins are resolved through a replace directive in go.mod, the
+Go module cache or GOPROXY, and built into the plugin cache.`,
+	}
+	cmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colored output")
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "lock",
+		Short: "Pin module plugin versions and checksums in devgen.lock",
+		Long: `Resolve every module plugin in devgen.toml and write devgen.lock next to it.
+
+The lockfile records the resolved version and go.sum checksum of each
+module. Later runs use the locked version and fail if the downloaded
+module does not match the checksum. Run it again after changing a
+plugin version in devgen.toml.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := NewPluginsCommand(genkit.NewLogger().SetNoColor(noColor))
+			if err != nil {
+				return err
+			}
+			return c.Lock(cmd.Context())
+		},
+	})
+
+	var jsonOutput bool
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List configured plugins",
+		Long: `List the plugins configured in devgen.toml.
+
+For each plugin this shows its type, resolved path, cache location, last
+build time, the optional genkit interfaces it implements (ConfigurableTool,
+ValidatableTool, RuleTool, ...) and its annotations. Plugins are loaded to
+inspect them, so source and module plugins are built if needed.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := NewPluginsCommand(genkit.NewLogger().SetNoColor(noColor))
+			if err != nil {
+				return err
+			}
+			return c.List(cmd.Context(), jsonOutput)
+		},
+	}
+	listCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
+	cmd.AddCommand(listCmd)
+
+	infoCmd := &cobra.Command{
+		Use:   "info <name>",
+		Short: "Show details of a plugin",
+		Long: `Show the details of a plugin, including the documentation of its
+annotations and the options it accepts.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := NewPluginsCommand(genkit.NewLogger().SetNoColor(noColor))
+			if err != nil {
+				return err
+			}
+			return c.Info(cmd.Context(), args[0], jsonOutput)
+		},
+	}
+	infoCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
+	cmd.AddCommand(infoCmd)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "build [name...]",
+		Short: "Build plugins into the plugin cache",
+		Long: `Build source and module plugins into the plugin cache without running
+any generator. Without arguments, every configured plugin is built.
+
+Builds are keyed by a hash of the plugin sources, go.mod/go.sum, the Go
+toolchain and the genkit version, so the cache directory can be saved and
+restored between CI runs.`,
+		Example: `  # Warm the plugin cache in CI
+  devgen plugins build
+
+  # Build a single plugin
+  devgen plugins build markgen`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := NewPluginsCommand(genkit.NewLogger().SetNoColor(noColor))
+			if err != nil {
+				return err
+			}
+			return c.Build(cmd.Context(), args)
+		},
+	})
+
+	var olderThan time.Duration
+	cleanCmd := &cobra.Command{
+		Use:   "clean",
+		Short: "Remove cached plugin builds",
+		Long: `Remove plugin builds from the plugin cache. By default the whole cache
+is removed; use --older-than to keep recent builds.`,
+		Example: `  # Remove everything
+  devgen plugins clean
+
+  # Remove builds older than a week
+  devgen plugins clean --older-than 168h`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := NewPluginsCommand(genkit.NewLogger().SetNoColor(noColor))
+			if err != nil {
+				return err
+			}
+			return c.Clean(olderThan)
+		},
+	}
+	cleanCmd.Flags().DurationVar(&olderThan, "older-than", 0, "Only remove builds older than this duration")
+	cmd.AddCommand(cleanCmd)
+
+	return cmd
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRootCmd_ReportFlags tests that --json and --format are exclusive and
+// that --format requires --dry-run
+func TestRootCmd_ReportFlags(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--format", "sarif", "./..."}, "requires --dry-run"},
+		{[]string{"--dry-run", "--json", "--format", "sarif", "./..."}, "[format json] were all set"},
+	}
+	for _, tt := range tests {
+		cmd := rootCmd()
+		cmd.SetArgs(tt.args)
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("devgen %s error = %v, want %q", strings.Join(tt.args, " "), err, tt.want)
+		}
+	}
+}
+
+// TestRootCmd_JSONWithoutDryRun tests that --json is accepted on a normal run
+func TestRootCmd_JSONWithoutDryRun(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":   "module example.com/model\n\ngo 1.21\n",
+		"model.go": "package model\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("WriteFile error: %v", err)
+		}
+	}
+	t.Chdir(dir)
+
+	cmd := rootCmd()
+	cmd.SetArgs([]string{"--json", "./..."})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("devgen --json ./... error = %v", err)
+	}
+}
@@ -0,0 +1,386 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/tlipoca9/devgen/genkit"
+)
+
+// MCPServer serves devgen to AI assistants over the Model Context Protocol.
+// Its tools document the annotations of the loaded tools, validate packages,
+// preview generated code and return the AI rules, so that an assistant can
+// check annotations against the real generators before proposing edits.
+//
+// devgen.toml and the tools are loaded again for every call, so changes to
+// the configuration apply without restarting the server.
+type MCPServer struct {
+	in  *bufio.Reader
+	out io.Writer
+	log *genkit.Logger
+
+	// dir is the project directory, the working directory by default.
+	dir string
+}
+
+// NewMCPServer creates a server that reads requests from in and writes
+// responses to out. Log output goes to log and must not use out.
+func NewMCPServer(in io.Reader, out io.Writer, log *genkit.Logger) *MCPServer {
+	dir, _ := os.Getwd()
+	return &MCPServer{
+		in:  bufio.NewReader(in),
+		out: out,
+		log: log,
+		dir: dir,
+	}
+}
+
+// Serve handles messages until the input is closed.
+func (s *MCPServer) Serve(ctx context.Context) error {
+	for {
+		msg, err := readMCPMessage(s.in)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			var rpcErr *lspError
+			if errors.As(err, &rpcErr) {
+				s.reply(nil, nil, rpcErr)
+				continue
+			}
+			return fmt.Errorf("read message: %w", err)
+		}
+
+		result, rpcErr := s.handle(ctx, msg)
+		if msg.ID != nil {
+			s.reply(msg.ID, result, rpcErr)
+		}
+	}
+}
+
+// handle dispatches a single request or notification.
+func (s *MCPServer) handle(ctx context.Context, msg *lspMessage) (any, *lspError) {
+	switch msg.Method {
+	case "initialize":
+		var params mcpInitializeParams
+		if err := unmarshalParams(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		return map[string]any{
+			"protocolVersion": negotiateMCPVersion(params.ProtocolVersion),
+			"capabilities": map[string]any{
+				"tools": map[string]any{},
+			},
+			"serverInfo": map[string]any{
+				"name":    "devgen",
+				"version": version,
+			},
+			"instructions": "Use list_annotations to look up devgen annotations, and validate_package or " +
+				"preview_generation to check annotated Go code (optionally with unsaved file contents) " +
+				"before proposing edits. get_rules returns the devgen usage rules.",
+		}, nil
+	case "ping":
+		return map[string]any{}, nil
+	case "tools/list":
+		return map[string]any{"tools": mcpTools}, nil
+	case "tools/call":
+		var params mcpCallToolParams
+		if err := unmarshalParams(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.callTool(ctx, params)
+	}
+
+	if msg.ID == nil {
+		// Notifications such as notifications/initialized are ignored
+		return nil, nil
+	}
+	return nil, &lspError{Code: lspMethodNotFound, Message: "method not found: " + msg.Method}
+}
+
+// mcpPackagesSchema are the input properties of the tools that load packages.
+var mcpPackagesSchema = map[string]any{
+	"packages": map[string]any{
+		"type":        "array",
+		"items":       map[string]any{"type": "string"},
+		"description": `Package patterns relative to the project root, e.g. ["./internal/model"]. Default: ["./..."]`,
+	},
+	"files": map[string]any{
+		"type":                 "object",
+		"additionalProperties": map[string]any{"type": "string"},
+		"description":          "Contents of Go files to use instead of the files on disk, by path relative to the project root. Use this to check an edit before making it.",
+	},
+	"include_tests": map[string]any{
+		"type":        "boolean",
+		"description": "Also load _test.go files.",
+	},
+}
+
+// mcpTools are the tools the server provides.
+var mcpTools = []mcpTool{
+	{
+		Name:        "list_annotations",
+		Description: "List the annotations of the devgen tools (built-in and plugins) with their documentation and parameter values.",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"tool": map[string]any{"type": "string", "description": `Only list the annotations of this tool, e.g. "enumgen".`},
+			},
+		},
+	},
+	{
+		Name:        "validate_package",
+		Description: "Validate the devgen annotations of Go packages and return the diagnostics, without generating code.",
+		InputSchema: map[string]any{"type": "object", "properties": mcpPackagesSchema},
+	},
+	{
+		Name:        "preview_generation",
+		Description: "Validate Go packages and return the files devgen would generate for them, without writing anything.",
+		InputSchema: map[string]any{"type": "object", "properties": mcpPackagesSchema},
+	},
+	{
+		Name:        "get_rules",
+		Description: "Return the devgen AI rules of the project. Without a name, list the rules; with a name, return its content.",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"name":  map[string]any{"type": "string", "description": `Rule name, e.g. "enumgen".`},
+				"agent": map[string]any{"type": "string", "description": `Only list the rules written for this agent, e.g. "cursor".`},
+			},
+		},
+	},
+}
+
+// mcpPackagesArgs are the arguments of validate_package and preview_generation.
+type mcpPackagesArgs struct {
+	Packages     []string          `json:"packages"`
+	Files        map[string]string `json:"files"`
+	IncludeTests bool              `json:"include_tests"`
+}
+
+// callTool runs a tool. Failures of the tool are reported in the result, so
+// that the assistant sees them, rather than as JSON-RPC errors.
+func (s *MCPServer) callTool(ctx context.Context, params mcpCallToolParams) (any, *lspError) {
+	var text string
+	var err error
+	switch params.Name {
+	case "list_annotations":
+		var args struct {
+			Tool string `json:"tool"`
+		}
+		if rpcErr := unmarshalParams(params.Arguments, &args); rpcErr != nil {
+			return nil, rpcErr
+		}
+		text, err = s.listAnnotations(ctx, args.Tool)
+	case "validate_package", "preview_generation":
+		var args mcpPackagesArgs
+		if rpcErr := unmarshalParams(params.Arguments, &args); rpcErr != nil {
+			return nil, rpcErr
+		}
+		text, err = s.dryRun(ctx, args, params.Name == "preview_generation")
+	case "get_rules":
+		var args struct {
+			Name  string `json:"name"`
+			Agent string `json:"agent"`
+		}
+		if rpcErr := unmarshalParams(params.Arguments, &args); rpcErr != nil {
+			return nil, rpcErr
+		}
+		text, err = s.getRules(ctx, args.Name, args.Agent)
+	default:
+		return nil, &lspError{Code: lspInvalidParams, Message: "unknown tool: " + params.Name}
+	}
+
+	if err != nil {
+		return mcpCallToolResult{Content: []mcpContent{{Type: "text", Text: err.Error()}}, IsError: true}, nil
+	}
+	return mcpCallToolResult{Content: []mcpContent{{Type: "text", Text: text}}}, nil
+}
+
+// loadConfigAndTools loads devgen.toml of the project and its tools.
+func (s *MCPServer) loadConfigAndTools(ctx context.Context) (*genkit.Config, []genkit.Tool, *genkit.PluginLoader, error) {
+	cfg, err := genkit.LoadConfig(s.dir)
+	if err != nil {
+		s.log.Warn("Failed to load devgen.toml: %v", err)
+		cfg = &genkit.Config{}
+	}
+	tools, loader, err := loadTools(ctx, cfg)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return cfg, tools, loader, nil
+}
+
+// listAnnotations documents the annotations of all tools, or of tool.
+func (s *MCPServer) listAnnotations(ctx context.Context, tool string) (string, error) {
+	cfg, tools, loader, err := s.loadConfigAndTools(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = loader.Close() }()
+	configs := genkit.MergeToolConfigs(genkit.CollectToolConfigs(tools), cfg.Tools)
+	if tool != "" {
+		tc, ok := configs[tool]
+		if !ok {
+			return "", fmt.Errorf("unknown tool %q, available tools: %s", tool, strings.Join(sortedKeys(configs), ", "))
+		}
+		configs = map[string]genkit.ToolConfig{tool: tc}
+	}
+
+	var b strings.Builder
+	for _, name := range sortedKeys(configs) {
+		tc := configs[name]
+		if len(tc.Annotations) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "## %s\n", name)
+		if tc.OutputSuffix != "" {
+			fmt.Fprintf(&b, "\nGenerates `*%s` files.\n", tc.OutputSuffix)
+		}
+		for _, ann := range tc.Annotations {
+			fmt.Fprintf(&b, "\n### %s:@%s", name, ann.Name)
+			if ann.Type != "" {
+				fmt.Fprintf(&b, " (%s)", ann.Type)
+			}
+			b.WriteString("\n")
+			if ann.Doc != "" {
+				b.WriteString("\n" + strings.TrimSpace(ann.Doc) + "\n")
+			}
+			if ann.Params == nil || len(ann.Params.Values) == 0 {
+				continue
+			}
+			b.WriteString("\nParameters:\n")
+			for _, v := range ann.Params.Values {
+				if doc := ann.Params.Docs[v]; doc != "" {
+					fmt.Fprintf(&b, "- `%s`: %s\n", v, doc)
+				} else {
+					fmt.Fprintf(&b, "- `%s`\n", v)
+				}
+			}
+		}
+		b.WriteString("\n")
+	}
+	if b.Len() == 0 {
+		return "No annotations found.", nil
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// dryRun validates the packages of args and, if preview is set, generates
+// them. It returns the DryRunResult as JSON, with the full content of the
+// generated files by path relative to the project root.
+func (s *MCPServer) dryRun(ctx context.Context, args mcpPackagesArgs, preview bool) (string, error) {
+	cfg, tools, loader, err := s.loadConfigAndTools(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = loader.Close() }()
+
+	patterns := args.Packages
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+	overlay := make(map[string][]byte, len(args.Files))
+	for name, content := range args.Files {
+		path := name
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(s.dir, path)
+		}
+		overlay[path] = []byte(content)
+	}
+
+	gen := genkit.New(genkit.Options{
+		Dir:                  s.dir,
+		IgnoreGeneratedFiles: true,
+		IncludeTests:         args.IncludeTests,
+		Overlay:              overlay,
+	})
+	if err := gen.Load(patterns...); err != nil {
+		return "", fmt.Errorf("load %s: %w", strings.Join(patterns, " "), err)
+	}
+
+	silent := genkit.NewLoggerWithWriter(io.Discard)
+	var result *genkit.DryRunResult
+	if preview {
+		var files map[string][]byte
+		result, files = dryRunTools(gen, tools, loader, cfg, silent)
+		for path, content := range files {
+			if rel, err := filepath.Rel(s.dir, path); err == nil {
+				path = filepath.ToSlash(rel)
+			}
+			result.Files[path] = string(content)
+		}
+	} else {
+		result = &genkit.DryRunResult{Success: true}
+		result.Stats.PackagesLoaded = len(gen.Packages)
+		for _, d := range validateTools(gen, tools, cfg, silent) {
+			result.AddDiagnostic(d)
+		}
+	}
+	for i, d := range result.Diagnostics {
+		if rel, err := filepath.Rel(s.dir, d.File); err == nil && d.File != "" {
+			result.Diagnostics[i].File = filepath.ToSlash(rel)
+		}
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// getRules lists the rules, or returns the content of the rule name.
+func (s *MCPServer) getRules(ctx context.Context, name, agent string) (string, error) {
+	rules, err := NewRulesCommand(s.log).SetDir(s.dir).collectRules(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if name != "" {
+		i := slices.IndexFunc(rules, func(r genkit.Rule) bool { return r.Name == name })
+		if i < 0 {
+			return "", fmt.Errorf("unknown rule %q", name)
+		}
+		return rules[i].Content, nil
+	}
+
+	var b strings.Builder
+	for _, rule := range rules {
+		if agent != "" && !rule.ForAgent(agent) {
+			continue
+		}
+		fmt.Fprintf(&b, "- `%s`: %s", rule.Name, rule.Description)
+		switch {
+		case rule.AlwaysApply:
+			b.WriteString(" (always applies)")
+		case len(rule.Globs) > 0:
+			fmt.Fprintf(&b, " (files: %s)", strings.Join(rule.Globs, ", "))
+		}
+		b.WriteString("\n")
+	}
+	if b.Len() == 0 {
+		return "No rules found.", nil
+	}
+	return "Call get_rules with a name to read a rule.\n\n" + b.String(), nil
+}
+
+func (s *MCPServer) reply(id json.RawMessage, result any, rpcErr *lspError) {
+	msg := map[string]any{"jsonrpc": "2.0", "id": id}
+	if rpcErr != nil {
+		msg["error"] = rpcErr
+	} else {
+		msg["result"] = result
+	}
+	if err := writeMCPMessage(s.out, msg); err != nil {
+		s.log.Warn("Failed to write message: %v", err)
+	}
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tlipoca9/devgen/genkit"
+)
+
+const mcpTestSource = `package model
+
+// Status is a status.
+// enumgen:@enum(string)
+type Status int
+
+const (
+	StatusActive Status = iota + 1
+	StatusInactive
+)
+`
+
+// mcpTestClient drives an MCPServer through in-memory pipes.
+type mcpTestClient struct {
+	t      *testing.T
+	in     *io.PipeWriter
+	out    *bufio.Reader
+	nextID int
+	done   chan error
+}
+
+func newMCPTestClient(t *testing.T) *mcpTestClient {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	files := map[string]string{
+		"go.mod":   "module example.com/model\n\ngo 1.21\n",
+		"model.go": mcpTestSource,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	server := NewMCPServer(inR, outW, genkit.NewLoggerWithWriter(io.Discard))
+
+	c := &mcpTestClient{t: t, in: inW, out: bufio.NewReader(outR), done: make(chan error, 1)}
+	go func() {
+		c.done <- server.Serve(context.Background())
+		outW.Close()
+	}()
+	t.Cleanup(c.close)
+
+	var result struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	c.call("initialize", map[string]any{"protocolVersion": "2025-03-26"}, &result)
+	if result.ProtocolVersion != "2025-03-26" {
+		t.Errorf("protocolVersion = %q, want the client's version", result.ProtocolVersion)
+	}
+	if err := writeMCPMessage(c.in, map[string]any{"jsonrpc": "2.0", "method": "notifications/initialized"}); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// call sends a request and decodes its result.
+func (c *mcpTestClient) call(method string, params any, result any) *lspError {
+	c.t.Helper()
+	c.nextID++
+	msg := map[string]any{"jsonrpc": "2.0", "id": c.nextID, "method": method, "params": params}
+	if err := writeMCPMessage(c.in, msg); err != nil {
+		c.t.Fatalf("write %s: %v", method, err)
+	}
+	resp, err := readMCPMessage(c.out)
+	if err != nil {
+		c.t.Fatalf("read %s response: %v", method, err)
+	}
+	if resp.Error != nil {
+		return resp.Error
+	}
+	if err := json.Unmarshal(resp.Result, result); err != nil {
+		c.t.Fatalf("decode %s result: %v", method, err)
+	}
+	return nil
+}
+
+// callTool calls an MCP tool and returns its text.
+func (c *mcpTestClient) callTool(name string, args any) (string, bool) {
+	c.t.Helper()
+	var result mcpCallToolResult
+	if err := c.call("tools/call", map[string]any{"name": name, "arguments": args}, &result); err != nil {
+		c.t.Fatalf("tools/call %s error: %v", name, err)
+	}
+	if len(result.Content) != 1 {
+		c.t.Fatalf("tools/call %s content = %+v", name, result.Content)
+	}
+	return result.Content[0].Text, result.IsError
+}
+
+func (c *mcpTestClient) close() {
+	c.in.Close()
+	select {
+	case err := <-c.done:
+		if err != nil {
+			c.t.Errorf("Serve() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		c.t.Fatal("server did not exit")
+	}
+}
+
+// TestMCPServer_Tools tests listing and calling the annotation and rule tools
+func TestMCPServer_Tools(t *testing.T) {
+	c := newMCPTestClient(t)
+
+	var list struct {
+		Tools []mcpTool `json:"tools"`
+	}
+	c.call("tools/list", nil, &list)
+	var names []string
+	for _, tool := range list.Tools {
+		names = append(names, tool.Name)
+	}
+	if got := strings.Join(names, ","); got != "list_annotations,validate_package,preview_generation,get_rules" {
+		t.Errorf("tools/list = %s", got)
+	}
+
+	text, isError := c.callTool("list_annotations", map[string]any{"tool": "enumgen"})
+	if isError || !strings.Contains(text, "### enumgen:@enum") || strings.Contains(text, "validategen") {
+		t.Errorf("list_annotations = %s", text)
+	}
+	if text, isError = c.callTool("list_annotations", map[string]any{"tool": "nogen"}); !isError {
+		t.Errorf("list_annotations of an unknown tool = %s, want an error", text)
+	}
+
+	text, isError = c.callTool("get_rules", map[string]any{})
+	if isError || !strings.Contains(text, "`devgen-tool-enumgen`") {
+		t.Errorf("get_rules = %s", text)
+	}
+	text, isError = c.callTool("get_rules", map[string]any{"name": "devgen-tool-enumgen"})
+	if isError || !strings.HasPrefix(text, "# ") {
+		t.Errorf("get_rules(devgen-tool-enumgen) = %.80s", text)
+	}
+
+	var result mcpCallToolResult
+	if err := c.call("tools/call", map[string]any{"name": "nope"}, &result); err == nil || err.Code != lspInvalidParams {
+		t.Errorf("unknown tool error = %v", err)
+	}
+}
+
+// TestMCPServer_Generation tests validation and preview with file contents
+// that are not on disk
+func TestMCPServer_Generation(t *testing.T) {
+	c := newMCPTestClient(t)
+
+	text, isError := c.callTool("preview_generation", map[string]any{"packages": []string{"."}})
+	var result genkit.DryRunResult
+	if err := json.Unmarshal([]byte(text), &result); isError || err != nil {
+		t.Fatalf("preview_generation = %s (%v)", text, err)
+	}
+	if !result.Success || !strings.Contains(result.Files["model_enum.go"], "func (x Status) IsValid() bool") {
+		t.Errorf("preview_generation = %s", text)
+	}
+
+	// An edit adding a @name without a parameter is reported before it is made
+	edited := strings.Replace(mcpTestSource, "\tStatusActive", "\t// enumgen:@name\n\tStatusActive", 1)
+	text, isError = c.callTool("validate_package", map[string]any{"files": map[string]string{"model.go": edited}})
+	result = genkit.DryRunResult{}
+	if err := json.Unmarshal([]byte(text), &result); isError || err != nil {
+		t.Fatalf("validate_package = %s (%v)", text, err)
+	}
+	if result.Success || len(result.Diagnostics) == 0 || result.Diagnostics[0].File != "model.go" {
+		t.Errorf("validate_package = %s", text)
+	}
+	if len(result.Files) != 0 {
+		t.Errorf("validate_package should not generate files, got %v", result.Files)
+	}
+}
+
+// TestMCPServer_GetRulesDir tests that get_rules reads the rules of the
+// server's project rather than of the working directory
+func TestMCPServer_GetRulesDir(t *testing.T) {
+	project := t.TempDir()
+	files := map[string]string{
+		"devgen.toml":            "[rules]\nsource_dir = \".devgen/rules\"\n",
+		".devgen/rules/local.md": "---\ndescription: Local conventions\nalwaysApply: true\n---\n\n# Local\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(project, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(t.TempDir())
+
+	server := NewMCPServer(strings.NewReader(""), io.Discard, genkit.NewLoggerWithWriter(io.Discard))
+	server.dir = project
+	text, err := server.getRules(context.Background(), "", "")
+	if err != nil || !strings.Contains(text, "`local`: Local conventions") {
+		t.Errorf("getRules() = %s, %v", text, err)
+	}
+}
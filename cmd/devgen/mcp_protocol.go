@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"slices"
+)
+
+// mcpProtocolVersion is the newest Model Context Protocol version the server
+// implements. Clients asking for one of mcpProtocolVersions get that version.
+const mcpProtocolVersion = "2025-06-18"
+
+var mcpProtocolVersions = []string{"2024-11-05", "2025-03-26", mcpProtocolVersion}
+
+// negotiateMCPVersion returns the protocol version to use with a client that
+// requested version.
+func negotiateMCPVersion(version string) string {
+	if slices.Contains(mcpProtocolVersions, version) {
+		return version
+	}
+	return mcpProtocolVersion
+}
+
+// readMCPMessage reads a single message. The stdio transport of MCP frames
+// JSON-RPC messages, the same as those of the LSP server, by newlines.
+func readMCPMessage(r *bufio.Reader) (*lspMessage, error) {
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) == 0 {
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		var msg lspMessage
+		if jsonErr := json.Unmarshal(line, &msg); jsonErr != nil {
+			return nil, &lspError{Code: lspParseError, Message: jsonErr.Error()}
+		}
+		return &msg, nil
+	}
+}
+
+// writeMCPMessage writes msg followed by a newline. encoding/json escapes
+// newlines in strings, so a message never spans lines.
+func writeMCPMessage(w io.Writer, msg any) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(body, '\n'))
+	return err
+}
+
+// MCP protocol types. Only the fields used by devgen are declared.
+
+type mcpInitializeParams struct {
+	ProtocolVersion string `json:"protocolVersion"`
+}
+
+type mcpTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+}
+
+type mcpCallToolParams struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+}
+
+type mcpContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type mcpCallToolResult struct {
+	Content []mcpContent `json:"content"`
+	IsError bool         `json:"isError,omitempty"`
+}
@@ -0,0 +1,383 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tlipoca9/devgen/genkit"
+)
+
+// PluginStatus describes a configured plugin and the tools it provides.
+type PluginStatus struct {
+	genkit.PluginInfo
+
+	// Tools lists the selected tools of the plugin.
+	Tools []ToolStatus `json:"tools,omitempty"`
+
+	// Error is set if the plugin could not be resolved or loaded.
+	Error string `json:"error,omitempty"`
+}
+
+// ToolStatus describes a tool provided by a plugin.
+type ToolStatus struct {
+	Name string `json:"name"`
+
+	// Interfaces lists the optional genkit interfaces the tool implements.
+	Interfaces []string `json:"interfaces,omitempty"`
+
+	// Annotations lists the annotations the tool declares.
+	Annotations []PluginAnnotation `json:"annotations,omitempty"`
+
+	// Options lists the options the tool accepts.
+	Options []genkit.OptionSpec `json:"options,omitempty"`
+}
+
+// PluginAnnotation is an annotation declared by a plugin.
+type PluginAnnotation struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Doc  string `json:"doc,omitempty"`
+}
+
+// PluginsCommand handles the 'devgen plugins' subcommands.
+type PluginsCommand struct {
+	log    *genkit.Logger
+	out    io.Writer
+	dir    string
+	loader *genkit.PluginLoader
+}
+
+// NewPluginsCommand creates a new PluginsCommand for the devgen.toml found
+// from the working directory.
+func NewPluginsCommand(log *genkit.Logger) (*PluginsCommand, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("get working directory: %w", err)
+	}
+	return &PluginsCommand{log: log, out: os.Stdout, dir: dir, loader: genkit.NewPluginLoader("")}, nil
+}
+
+// loadConfig loads devgen.toml and returns it with its path.
+func (c *PluginsCommand) loadConfig() (*genkit.Config, string, error) {
+	configPath, err := genkit.FindConfig(c.dir)
+	if err != nil {
+		return nil, "", err
+	}
+	if configPath == "" {
+		return nil, "", fmt.Errorf("devgen.toml not found in %s or its parents", c.dir)
+	}
+	cfg, err := genkit.LoadConfigFile(configPath)
+	if err != nil {
+		return nil, "", err
+	}
+	return cfg, configPath, nil
+}
+
+// Lock resolves every module plugin and writes devgen.lock next to devgen.toml.
+func (c *PluginsCommand) Lock(ctx context.Context) error {
+	cfg, configPath, err := c.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	lock, err := c.loader.LockPlugins(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	if len(lock.Plugins) == 0 {
+		c.log.Info("No module plugins configured in %s", configPath)
+		return nil
+	}
+
+	if err := genkit.WritePluginLock(configPath, lock); err != nil {
+		return err
+	}
+	c.log.Done("Locked %v plugin(s) in %s", len(lock.Plugins), genkit.PluginLockFile)
+	for _, p := range lock.Plugins {
+		if p.Replace != "" {
+			c.log.Item("%s: %s => %s", p.Name, p.Module, p.Replace)
+			continue
+		}
+		c.log.Item("%s: %s@%s %s", p.Name, p.Module, p.Version, p.Sum)
+	}
+	return nil
+}
+
+// List shows every configured plugin.
+func (c *PluginsCommand) List(ctx context.Context, jsonOutput bool) error {
+	defer func() { _ = c.loader.Close() }()
+
+	cfg, configPath, err := c.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	statuses := make([]*PluginStatus, 0, len(cfg.Plugins))
+	for _, p := range cfg.Plugins {
+		statuses = append(statuses, c.status(ctx, p))
+	}
+	if jsonOutput {
+		enc := json.NewEncoder(c.out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(statuses); err != nil {
+			return err
+		}
+		return pluginErrors(statuses)
+	}
+
+	if len(statuses) == 0 {
+		c.log.Info("No plugins configured in %s", configPath)
+		return nil
+	}
+	c.log.Info("%v plugin(s) in %s, cache: %s", len(statuses), configPath, c.loader.CacheDir())
+	for _, st := range statuses {
+		_, _ = fmt.Fprintln(c.out)
+		c.printStatus(st, false)
+	}
+	return pluginErrors(statuses)
+}
+
+// Info shows the details of a single plugin, including annotation docs
+// and options.
+func (c *PluginsCommand) Info(ctx context.Context, name string, jsonOutput bool) error {
+	defer func() { _ = c.loader.Close() }()
+
+	cfg, _, err := c.loadConfig()
+	if err != nil {
+		return err
+	}
+	p, err := findPlugin(cfg, name)
+	if err != nil {
+		return err
+	}
+
+	st := c.status(ctx, p)
+	if jsonOutput {
+		enc := json.NewEncoder(c.out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(st); err != nil {
+			return err
+		}
+	} else {
+		c.printStatus(st, true)
+	}
+	return pluginErrors([]*PluginStatus{st})
+}
+
+// Build builds the named plugins, or all plugins if names is empty, into
+// the plugin cache without running them.
+func (c *PluginsCommand) Build(ctx context.Context, names []string) error {
+	cfg, configPath, err := c.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	plugins := cfg.Plugins
+	if len(names) > 0 {
+		plugins = plugins[:0:0]
+		for _, name := range names {
+			p, err := findPlugin(cfg, name)
+			if err != nil {
+				return err
+			}
+			plugins = append(plugins, p)
+		}
+	}
+	if len(plugins) == 0 {
+		c.log.Info("No plugins configured in %s", configPath)
+		return nil
+	}
+
+	built := 0
+	for _, p := range plugins {
+		start := time.Now()
+		info, err := c.loader.BuildPlugin(ctx, p)
+		if err != nil {
+			return err
+		}
+		if info.CachePath == "" {
+			c.log.Item("%s: prebuilt %s, nothing to build", info.Name, info.Path)
+			continue
+		}
+		built++
+		c.log.Item("%s: %s (%v)", info.Name, info.CachePath, time.Since(start).Round(time.Millisecond))
+	}
+	c.log.Done("Built %v plugin(s) into %s", built, c.loader.CacheDir())
+	return nil
+}
+
+// Clean removes plugin builds written more than maxAge ago from the
+// plugin cache. A zero maxAge removes every build.
+func (c *PluginsCommand) Clean(maxAge time.Duration) error {
+	if err := c.loader.CleanCache(maxAge); err != nil {
+		return fmt.Errorf("clean plugin cache: %w", err)
+	}
+	if maxAge > 0 {
+		c.log.Done("Removed plugin builds older than %v from %s", maxAge, c.loader.CacheDir())
+	} else {
+		c.log.Done("Removed all plugin builds from %s", c.loader.CacheDir())
+	}
+	return nil
+}
+
+// status resolves and loads a plugin and describes the tools it provides.
+// Loading builds the plugin if needed.
+func (c *PluginsCommand) status(ctx context.Context, p genkit.PluginConfig) *PluginStatus {
+	st := &PluginStatus{PluginInfo: genkit.PluginInfo{Name: p.Name, Type: p.Type, Path: p.Path}}
+
+	tools, err := c.loader.LoadPluginTools(ctx, p)
+	if err != nil {
+		st.Error = err.Error()
+		return st
+	}
+	info, err := c.loader.InspectPlugin(ctx, p)
+	if err != nil {
+		st.Error = err.Error()
+		return st
+	}
+	st.PluginInfo = *info
+
+	for _, tool := range tools {
+		ts := ToolStatus{Name: tool.Name(), Interfaces: genkit.ToolInterfaces(tool)}
+		for _, name := range ts.Interfaces {
+			switch name {
+			case "ConfigurableTool":
+				for _, ann := range tool.(genkit.ConfigurableTool).Config().Annotations {
+					ts.Annotations = append(ts.Annotations, PluginAnnotation{Name: ann.Name, Type: ann.Type, Doc: ann.Doc})
+				}
+			case "OptionsTool":
+				ts.Options = tool.(genkit.OptionsTool).OptionsSchema()
+			}
+		}
+		st.Tools = append(st.Tools, ts)
+	}
+	return st
+}
+
+// printStatus prints a plugin status; detailed adds annotation docs and
+// options. The tool of a single-tool plugin is printed inline.
+func (c *PluginsCommand) printStatus(st *PluginStatus, detailed bool) {
+	field := func(label, value string) {
+		_, _ = fmt.Fprintln(c.out, strings.TrimRight(fmt.Sprintf("  %-12s %s", label+":", value), " "))
+	}
+
+	_, _ = fmt.Fprintln(c.out, st.Name)
+	field("type", string(st.Type))
+	if st.Module != "" {
+		version := st.Version
+		if version == "" {
+			version = "(replaced)"
+		}
+		field("module", st.Module+" "+version)
+	}
+	field("path", st.Path)
+	if st.CachePath != "" {
+		field("cache", st.CachePath)
+		if st.BuiltAt.IsZero() {
+			field("built", "never")
+		} else {
+			field("built", st.BuiltAt.Format(time.DateTime))
+		}
+	}
+	if st.Error != "" {
+		field("error", st.Error)
+		return
+	}
+
+	if len(st.Tools) == 1 && st.Tools[0].Name == st.Name {
+		c.printTool(st.Tools[0], "  ", detailed)
+		return
+	}
+	names := make([]string, 0, len(st.Tools))
+	for _, ts := range st.Tools {
+		names = append(names, ts.Name)
+	}
+	field("tools", strings.Join(names, ", "))
+	for _, ts := range st.Tools {
+		_, _ = fmt.Fprintf(c.out, "  %s\n", ts.Name)
+		c.printTool(ts, "    ", detailed)
+	}
+}
+
+// printTool prints the interfaces, annotations and options of a tool with
+// the given indentation.
+func (c *PluginsCommand) printTool(ts ToolStatus, indent string, detailed bool) {
+	field := func(label, value string) {
+		_, _ = fmt.Fprintln(c.out, strings.TrimRight(fmt.Sprintf("%s%-12s %s", indent, label+":", value), " "))
+	}
+
+	field("interfaces", orNone(strings.Join(ts.Interfaces, ", ")))
+
+	if !detailed {
+		names := make([]string, 0, len(ts.Annotations))
+		for _, ann := range ts.Annotations {
+			names = append(names, "@"+ann.Name)
+		}
+		field("annotations", orNone(strings.Join(names, ", ")))
+		return
+	}
+
+	if len(ts.Annotations) == 0 {
+		field("annotations", "none")
+	} else {
+		field("annotations", "")
+	}
+	for _, ann := range ts.Annotations {
+		_, _ = fmt.Fprintf(c.out, "%s  %s:@%s (%s)\n", indent, ts.Name, ann.Name, ann.Type)
+		if ann.Doc != "" {
+			_, _ = fmt.Fprintf(c.out, "%s    %s\n", indent, firstLine(ann.Doc))
+		}
+	}
+	if len(ts.Options) > 0 {
+		field("options", "")
+		for _, opt := range ts.Options {
+			line := fmt.Sprintf("%s  %s (%s)", indent, opt.Name, opt.Type)
+			if opt.Default != nil {
+				line += fmt.Sprintf(" = %v", opt.Default)
+			}
+			_, _ = fmt.Fprintln(c.out, line)
+			if opt.Doc != "" {
+				_, _ = fmt.Fprintf(c.out, "%s    %s\n", indent, firstLine(opt.Doc))
+			}
+		}
+	}
+}
+
+// findPlugin returns the configured plugin with the given name.
+func findPlugin(cfg *genkit.Config, name string) (genkit.PluginConfig, error) {
+	names := make([]string, 0, len(cfg.Plugins))
+	for _, p := range cfg.Plugins {
+		if p.Name == name {
+			return p, nil
+		}
+		names = append(names, p.Name)
+	}
+	return genkit.PluginConfig{}, fmt.Errorf("plugin %q not found in devgen.toml (configured: %s)", name, orNone(strings.Join(names, ", ")))
+}
+
+// pluginErrors returns an error if any plugin failed to load.
+func pluginErrors(statuses []*PluginStatus) error {
+	var failed []string
+	for _, st := range statuses {
+		if st.Error != "" {
+			failed = append(failed, st.Name)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to load plugin(s): %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// orNone returns s, or "none" if it is empty.
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/tlipoca9/devgen/genkit"
+)
+
+// newTestPluginsCommand writes devgen.toml with an exec plugin that
+// describes itself and returns a command for that directory.
+func newTestPluginsCommand(t *testing.T) (*PluginsCommand, *bytes.Buffer) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("exec plugin fixture is a shell script")
+	}
+
+	dir := t.TempDir()
+	desc := `{"version":1,"name":"shgen","capabilities":["config"],"config":{"Annotations":[{"Name":"gen","Type":"type","Doc":"Generate code"}]}}`
+	script := "#!/bin/sh\ncat >/dev/null\necho '" + desc + "'\n"
+	if err := os.WriteFile(filepath.Join(dir, "shgen"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	config := "[[plugins]]\nname = \"shgen\"\npath = \"./shgen\"\ntype = \"exec\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "devgen.toml"), []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	return &PluginsCommand{
+		log:    genkit.NewLoggerWithWriter(&out).SetNoColor(true),
+		out:    &out,
+		dir:    dir,
+		loader: genkit.NewPluginLoader(t.TempDir()),
+	}, &out
+}
+
+// TestPluginsCommand_List tests listing plugins with their interfaces and annotations
+func TestPluginsCommand_List(t *testing.T) {
+	c, out := newTestPluginsCommand(t)
+	if err := c.List(context.Background(), true); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	var statuses []PluginStatus
+	if err := json.Unmarshal(out.Bytes(), &statuses); err != nil {
+		t.Fatalf("List() output is not JSON: %v\n%s", err, out.String())
+	}
+	if len(statuses) != 1 {
+		t.Fatalf("List() = %+v", statuses)
+	}
+	st := statuses[0]
+	if st.Name != "shgen" || st.Type != genkit.PluginTypeExec || st.CachePath != "" || st.Error != "" {
+		t.Errorf("status = %+v", st)
+	}
+	if len(st.Tools) != 1 || st.Tools[0].Name != "shgen" {
+		t.Fatalf("tools = %+v", st.Tools)
+	}
+	if ts := st.Tools[0]; len(ts.Interfaces) != 1 || ts.Interfaces[0] != "ConfigurableTool" {
+		t.Errorf("interfaces = %v", ts.Interfaces)
+	}
+	if ts := st.Tools[0]; len(ts.Annotations) != 1 || ts.Annotations[0].Name != "gen" {
+		t.Errorf("annotations = %v", ts.Annotations)
+	}
+}
+
+// TestPluginsCommand_Info tests showing a single plugin
+func TestPluginsCommand_Info(t *testing.T) {
+	c, out := newTestPluginsCommand(t)
+	if err := c.Info(context.Background(), "shgen", false); err != nil {
+		t.Fatalf("Info() error = %v", err)
+	}
+	for _, want := range []string{"shgen\n", "type:        exec", "interfaces:  ConfigurableTool", "shgen:@gen (type)", "Generate code"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Info() output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	err := c.Info(context.Background(), "nope", false)
+	if err == nil || !strings.Contains(err.Error(), `plugin "nope" not found`) {
+		t.Errorf("Info() with unknown plugin error = %v", err)
+	}
+}
+
+// TestPluginsCommand_ToolSet tests showing the tools of a tool set
+func TestPluginsCommand_ToolSet(t *testing.T) {
+	c, out := newTestPluginsCommand(t)
+	script := `#!/bin/sh
+req=$(cat)
+case "$req" in
+*'"tool":"markgen"'*) echo '{"version":1,"name":"markgen"}' ;;
+*'"tool":"docgen"'*) echo '{"version":1,"name":"docgen","capabilities":["validate"]}' ;;
+*) echo '{"version":1,"tools":["markgen","docgen"]}' ;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(c.dir, "shgen"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Info(context.Background(), "shgen", false); err != nil {
+		t.Fatalf("Info() error = %v", err)
+	}
+	for _, want := range []string{"tools:       markgen, docgen", "  docgen\n    interfaces:  ValidatableTool"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Info() output missing %q:\n%s", want, out.String())
+		}
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	devgenrules "github.com/tlipoca9/devgen/cmd/devgen/rules"
@@ -16,6 +18,17 @@ import (
 type RulesCommand struct {
 	registry *genkit.AdapterRegistry
 	log      *genkit.Logger
+
+	// dir is the project directory rules are collected and linted in.
+	// If empty, uses the working directory.
+	dir string
+
+	// projectPatterns are the packages the project rule is built from.
+	projectPatterns []string
+
+	// agentConfigs are the [rules.agents.<name>] tables of devgen.toml,
+	// read by collectRules.
+	agentConfigs map[string]genkit.AgentRulesConfig
 }
 
 // NewRulesCommand creates a new RulesCommand with the adapter registry.
@@ -26,10 +39,29 @@ func NewRulesCommand(log *genkit.Logger) *RulesCommand {
 	}
 }
 
+// SetDir sets the project directory whose devgen.toml, rule sources and
+// packages the rules are collected from.
+func (c *RulesCommand) SetDir(dir string) *RulesCommand {
+	c.dir = dir
+	return c
+}
+
+// SetProjectPatterns enables the project rule, built from the annotated
+// declarations of the packages matching patterns.
+func (c *RulesCommand) SetProjectPatterns(patterns []string) *RulesCommand {
+	c.projectPatterns = patterns
+	return c
+}
+
 // Execute runs the rules command with the specified agent and write mode.
 // If write is false, rules are printed to stdout (preview mode).
 // If write is true, rules are written to agent-specific directory.
 func (c *RulesCommand) Execute(ctx context.Context, agent string, write bool) error {
+	// Register the adapters of devgen.toml before looking up the agent
+	if _, err := c.loadConfig(); err != nil {
+		return err
+	}
+
 	// Get adapter
 	adapter, ok := c.registry.Get(agent)
 	if !ok {
@@ -57,11 +89,19 @@ func (c *RulesCommand) Execute(ctx context.Context, agent string, write bool) er
 
 // ExecuteAll runs the rules command for all supported agents.
 // Only supports write mode (preview mode for all agents would be too verbose).
+// Agents that would create files shared with other tools, such as AGENTS.md,
+// CLAUDE.md or .github/copilot-instructions.md, are skipped unless the project
+// already has their rule files or configures them, see writtenByAll.
 func (c *RulesCommand) ExecuteAll(ctx context.Context, write bool) error {
 	if !write {
 		return fmt.Errorf("--agent all requires -w/--write flag")
 	}
 
+	cfg, err := c.loadConfig()
+	if err != nil {
+		return err
+	}
+
 	// Collect rules once
 	rules, err := c.collectRules(ctx)
 	if err != nil {
@@ -74,17 +114,236 @@ func (c *RulesCommand) ExecuteAll(ctx context.Context, write bool) error {
 	}
 
 	// Write rules for all agents
-	agents := c.registry.List()
-	for _, agentName := range agents {
+	var skipped []string
+	for _, agentName := range c.registry.List() {
 		adapter, ok := c.registry.Get(agentName)
 		if !ok {
 			continue
 		}
+		changes, err := c.planRules(adapter, rules)
+		if err != nil {
+			return fmt.Errorf("write rules for %s: %w", agentName, err)
+		}
+		if !writtenByAll(adapter, changes, cfg) {
+			skipped = append(skipped, agentName)
+			continue
+		}
 		if err := c.writeRules(adapter, rules); err != nil {
 			return fmt.Errorf("write rules for %s: %w", agentName, err)
 		}
 	}
 
+	if len(skipped) > 0 {
+		c.log.Info("Skipped %s: run 'devgen rules --agent <name> -w' once to create their rule files",
+			strings.Join(skipped, ", "))
+	}
+	return nil
+}
+
+// writtenByAll reports whether --agent all writes the rule files of an
+// adapter: adapters that keep all their files in a directory of their own,
+// and adapters whose rule files exist on disk or that are configured in
+// devgen.toml.
+func writtenByAll(adapter genkit.AgentAdapter, changes []ruleChange, cfg *genkit.Config) bool {
+	if _, ok := cfg.Rules.Agents[adapter.Name()]; ok {
+		return true
+	}
+	for _, ac := range cfg.Rules.Adapters {
+		if ac.Name == adapter.Name() {
+			return true
+		}
+	}
+	if slices.ContainsFunc(changes, func(ch ruleChange) bool { return ch.Have != "" }) {
+		return true
+	}
+
+	dir := adapter.OutputDir()
+	if dir == "." {
+		return false
+	}
+	for _, ch := range changes {
+		if !strings.HasPrefix(ch.Path, dir+"/") {
+			return false
+		}
+	}
+	return true
+}
+
+// Check compares the rule files of an agent with the files on disk and
+// prints a diff for every file that is out of date. It returns an error if
+// any file is out of date. With agent "all", agents without any rule file
+// on disk are skipped, so only the outputs a project keeps are checked.
+func (c *RulesCommand) Check(ctx context.Context, agent string) error {
+	if _, err := c.loadConfig(); err != nil {
+		return err
+	}
+	agents := []string{agent}
+	if agent == "all" {
+		agents = c.registry.List()
+	}
+	var adapters []genkit.AgentAdapter
+	for _, name := range agents {
+		adapter, ok := c.registry.Get(name)
+		if !ok {
+			return fmt.Errorf("unknown agent %q, available agents: %s", name, strings.Join(c.registry.List(), ", "))
+		}
+		adapters = append(adapters, adapter)
+	}
+
+	rules, err := c.collectRules(ctx)
+	if err != nil {
+		return fmt.Errorf("collect rules: %w", err)
+	}
+
+	var stale []string
+	for _, adapter := range adapters {
+		changes, err := c.planRules(adapter, rules)
+		if err != nil {
+			return fmt.Errorf("check rules for %s: %w", adapter.Name(), err)
+		}
+		if agent == "all" && !slices.ContainsFunc(changes, func(ch ruleChange) bool { return ch.Have != "" }) {
+			continue
+		}
+		for _, change := range changes {
+			if change.Have != change.Want {
+				fmt.Print(unifiedDiff(change.Path, change.Have, change.Want))
+				stale = append(stale, change.Path)
+			}
+		}
+	}
+
+	if len(stale) > 0 {
+		c.log.Error("%v rule file(s) are out of date", len(stale))
+		for _, name := range stale {
+			c.log.Item("%s", name)
+		}
+		return fmt.Errorf("rule files are out of date, run 'devgen rules --agent %s -w' to update them", agent)
+	}
+	c.log.Done("Rule files are up to date")
+	return nil
+}
+
+// defaultRuleSourceDir is where Import writes rule source files if
+// rules.source_dir is not set.
+const defaultRuleSourceDir = ".devgen/rules"
+
+// Import converts the rule files of the agent from into rule source files
+// in rules.source_dir, or .devgen/rules if it is not set. If write is false,
+// the source files are printed to stdout. Existing source files are kept.
+func (c *RulesCommand) Import(from string, write bool) error {
+	cfg, err := c.loadConfig()
+	if err != nil {
+		return err
+	}
+	adapter, ok := c.registry.Get(from)
+	if !ok {
+		return fmt.Errorf("unknown agent %q, available agents: %s", from, strings.Join(c.registry.List(), ", "))
+	}
+	importer, ok := adapter.(genkit.ImportingAdapter)
+	if !ok {
+		var supported []string
+		for _, name := range c.registry.List() {
+			a, _ := c.registry.Get(name)
+			if _, ok := a.(genkit.ImportingAdapter); ok {
+				supported = append(supported, name)
+			}
+		}
+		return fmt.Errorf("agent %s does not support import, supported agents: %s", from, strings.Join(supported, ", "))
+	}
+
+	root, err := c.root()
+	if err != nil {
+		return err
+	}
+	imported, skipped, err := genkit.ImportRules(importer, root)
+	if err != nil {
+		return fmt.Errorf("import rules from %s: %w", from, err)
+	}
+	for _, name := range skipped {
+		c.log.Warn("Skipped %s: generated by devgen", name)
+	}
+	if len(imported) == 0 {
+		c.log.Warn("No rule files found in %s", adapter.OutputDir())
+		return nil
+	}
+
+	dir := cfg.Rules.GetSourceDir()
+	if dir == "" {
+		dir = defaultRuleSourceDir
+	}
+	// Like collectSourcedRules, a relative source_dir is relative to the project
+	sourceDir := dir
+	if !filepath.IsAbs(sourceDir) {
+		sourceDir = filepath.Join(root, dir)
+	}
+	var written []string
+	for i, ir := range imported {
+		content, err := genkit.FormatRuleFile(ir.Rule)
+		if err != nil {
+			return fmt.Errorf("format %s: %w", ir.Path, err)
+		}
+		name := filepath.Join(dir, ir.Rule.Name+".md")
+		if !write {
+			if i > 0 {
+				fmt.Println("\n" + strings.Repeat("=", 80) + "\n")
+			}
+			fmt.Printf("# File: %s (from %s)\n\n", filepath.ToSlash(name), ir.Path)
+			fmt.Print(content)
+			continue
+		}
+		target := filepath.Join(sourceDir, ir.Rule.Name+".md")
+		if _, err := os.Stat(target); err == nil {
+			c.log.Warn("Skipped %s: %s already exists", ir.Path, name)
+			continue
+		}
+		if err := os.MkdirAll(sourceDir, 0755); err != nil {
+			return fmt.Errorf("create directory %s: %w", dir, err)
+		}
+		if err := os.WriteFile(target, []byte(content), 0644); err != nil {
+			return fmt.Errorf("write %s: %w", name, err)
+		}
+		written = append(written, name)
+	}
+	if !write {
+		return nil
+	}
+
+	c.log.Done("Imported %v rule(s) from %s into %s", len(written), from, dir)
+	for _, name := range written {
+		c.log.Item("%s", name)
+	}
+	if !cfg.Rules.HasSourceDir() {
+		c.log.Info("Set source_dir under [rules] in devgen.toml to %s to generate rules from them", dir)
+	}
+	return nil
+}
+
+// Lint checks the rules of all sources, see genkit.LintRuleDir and
+// genkit.LintRules, and returns an error if any issue is found. Globs are
+// matched against the files under the working directory.
+func (c *RulesCommand) Lint(ctx context.Context) error {
+	rules, issues, err := c.collectSourcedRules(ctx, true)
+	if err != nil {
+		return fmt.Errorf("collect rules: %w", err)
+	}
+	root, err := c.root()
+	if err != nil {
+		return err
+	}
+	ruleIssues, err := genkit.LintRules(rules, root)
+	if err != nil {
+		return err
+	}
+	issues = append(issues, ruleIssues...)
+
+	if len(issues) > 0 {
+		c.log.Error("%v rule issue(s) found", len(issues))
+		for _, issue := range issues {
+			c.log.Item("%s", issue)
+		}
+		return fmt.Errorf("rule lint failed")
+	}
+	c.log.Done("Linted %v rule(s), no issues found", len(rules))
 	return nil
 }
 
@@ -93,30 +352,121 @@ func (c *RulesCommand) ListAgents() []string {
 	return c.registry.List()
 }
 
+// root returns the project directory, see dir.
+func (c *RulesCommand) root() (string, error) {
+	if c.dir != "" {
+		return c.dir, nil
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("get working directory: %w", err)
+	}
+	return dir, nil
+}
+
+// loadConfig loads devgen.toml from the project directory and registers the
+// adapters it defines. A missing or unreadable config is treated as empty,
+// an invalid adapter definition is an error.
+func (c *RulesCommand) loadConfig() (*genkit.Config, error) {
+	dir, err := c.root()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := genkit.LoadConfig(dir)
+	if err != nil {
+		cfg = &genkit.Config{}
+	}
+	if err := c.registry.LoadAdapters(cfg.Rules.Adapters); err != nil {
+		return nil, fmt.Errorf("rules.adapters: %w", err)
+	}
+	return cfg, nil
+}
+
 // collectRules gathers rules from all sources:
 // 1. Project-level rules from .devgen/rules/ directory
 // 2. Built-in devgen rules (if enabled)
 // 3. Plugin rules (from tools implementing RuleTool)
+// 4. The project rule (if enabled), from tools implementing ProjectRuleTool
 func (c *RulesCommand) collectRules(ctx context.Context) ([]genkit.Rule, error) {
-	var allRules []genkit.Rule
+	sourced, _, err := c.collectSourcedRules(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]genkit.Rule, len(sourced))
+	for i, sr := range sourced {
+		rules[i] = sr.Rule
+	}
+	return rules, nil
+}
+
+// collectSourcedRules gathers the rules of collectRules with their sources.
+// With lint, problems in project rule source files are returned as issues
+// instead of errors.
+func (c *RulesCommand) collectSourcedRules(ctx context.Context, lint bool) ([]genkit.SourcedRule, []genkit.RuleIssue, error) {
+	var allRules []genkit.SourcedRule
+	var issues []genkit.RuleIssue
 
 	// Load config
-	configSearchDir, err := os.Getwd()
+	configSearchDir, err := c.root()
 	if err != nil {
-		return nil, fmt.Errorf("get working directory: %w", err)
+		return nil, nil, err
 	}
 
-	cfg, err := genkit.LoadConfig(configSearchDir)
+	cfg, err := c.loadConfig()
 	if err != nil {
-		cfg = &genkit.Config{}
+		return nil, nil, err
+	}
+	for name := range cfg.Rules.Agents {
+		if _, ok := c.registry.Get(name); !ok {
+			return nil, nil, fmt.Errorf("rules.agents: unknown agent %q, available agents: %s",
+				name, strings.Join(c.registry.List(), ", "))
+		}
+	}
+	c.agentConfigs = cfg.Rules.Agents
+
+	// Tools are loaded first, since templated project rules describe them
+	loader := genkit.NewPluginLoader("")
+	defer func() { _ = loader.Close() }()
+	tools, err := loader.LoadTools(ctx, cfg, builtinTools)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load plugins: %w", err)
 	}
 
 	// 1. Load project-level rules from source directory (only if explicitly configured)
 	if cfg.Rules.HasSourceDir() {
 		sourceDir := cfg.Rules.GetSourceDir()
-		projectRules, err := genkit.LoadRulesFromDir(sourceDir)
+		if !filepath.IsAbs(sourceDir) {
+			sourceDir = filepath.Join(c.dir, sourceDir)
+		}
+		data, err := genkit.NewRuleTemplateData(configSearchDir, cfg, tools)
 		if err != nil {
-			return nil, fmt.Errorf("load project rules from %s: %w", sourceDir, err)
+			return nil, nil, fmt.Errorf("prepare rule template data: %w", err)
+		}
+		opts := genkit.RuleLoadOptions{Data: data}
+		var projectRules []genkit.SourcedRule
+		if lint {
+			var fileIssues []genkit.RuleIssue
+			projectRules, fileIssues, err = genkit.LintRuleDir(sourceDir, opts)
+			if err != nil {
+				return nil, nil, fmt.Errorf("load project rules from %s: %w", sourceDir, err)
+			}
+			issues = append(issues, fileIssues...)
+			for _, sr := range projectRules {
+				if err := c.checkRuleAgents([]genkit.Rule{sr.Rule}); err != nil {
+					issues = append(issues, genkit.RuleIssue{Source: sr.Source, Message: err.Error()})
+				}
+			}
+		} else {
+			rules, err := genkit.LoadRulesFromDir(sourceDir, opts)
+			if err != nil {
+				return nil, nil, fmt.Errorf("load project rules from %s: %w", sourceDir, err)
+			}
+			if err := c.checkRuleAgents(rules); err != nil {
+				return nil, nil, fmt.Errorf("load project rules from %s: %w", sourceDir, err)
+			}
+			for _, rule := range rules {
+				projectRules = append(projectRules, genkit.SourcedRule{Source: filepath.Join(sourceDir, rule.Name+".md"), Rule: rule})
+			}
 		}
 		if len(projectRules) > 0 {
 			c.log.Info("Loaded %v project rule(s) from %s", len(projectRules), sourceDir)
@@ -126,94 +476,211 @@ func (c *RulesCommand) collectRules(ctx context.Context) ([]genkit.Rule, error)
 
 	// 2. Add devgen's own rules (if enabled)
 	if cfg.Rules.ShouldIncludeBuiltin() {
-		allRules = append(allRules, devgenRules()...)
+		for _, rule := range devgenRules() {
+			allRules = append(allRules, genkit.SourcedRule{Source: "devgen", Rule: rule})
+		}
 	}
 
-	// 3. Collect rules from plugins
-	tools := make([]genkit.Tool, 0, len(builtinTools)+len(cfg.Plugins))
-	toolNames := make(map[string]bool)
-
-	// Load external plugins first
-	if len(cfg.Plugins) > 0 {
-		loader := genkit.NewPluginLoader("")
-		pluginTools, err := loader.LoadPlugins(ctx, cfg)
-		if err != nil {
-			return nil, fmt.Errorf("load plugins: %w", err)
-		}
-		for _, tool := range pluginTools {
-			tools = append(tools, tool)
-			toolNames[tool.Name()] = true
+	// 3. Collect rules from plugins and built-in tools
+	// Collect rules from tools that implement RuleTool
+	for _, tool := range tools {
+		if rt, ok := tool.(genkit.RuleTool); ok {
+			for _, rule := range rt.Rules() {
+				allRules = append(allRules, genkit.SourcedRule{Source: "tool " + tool.Name(), Rule: rule})
+			}
 		}
 	}
 
-	// Add built-in tools
-	for _, tool := range builtinTools {
-		if !toolNames[tool.Name()] {
-			tools = append(tools, tool)
+	// 4. Build the project rule from the annotated declarations
+	patterns := c.projectPatterns
+	if len(patterns) == 0 && cfg.Rules.Project {
+		patterns = []string{"./..."}
+	}
+	if len(patterns) > 0 {
+		gen := genkit.New(genkit.Options{Dir: c.dir})
+		if err := gen.Load(patterns...); err != nil {
+			return nil, nil, fmt.Errorf("load packages for the project rule: %w", err)
+		}
+		if rule, ok := genkit.BuildProjectRule(gen, tools, configSearchDir); ok {
+			c.log.Info("Built project rule from %v package(s)", len(gen.Packages))
+			allRules = append(allRules, genkit.SourcedRule{Source: "project rule", Rule: rule})
+		} else {
+			c.log.Warn("No annotated declarations found for the project rule in %s", strings.Join(patterns, " "))
 		}
 	}
 
-	// Collect rules from tools that implement RuleTool
-	for _, tool := range tools {
-		if rt, ok := tool.(genkit.RuleTool); ok {
-			rules := rt.Rules()
-			allRules = append(allRules, rules...)
+	return allRules, issues, nil
+}
+
+// checkRuleAgents reports agents named by rules that have no adapter.
+func (c *RulesCommand) checkRuleAgents(rules []genkit.Rule) error {
+	for _, rule := range rules {
+		for _, agent := range slices.Concat(rule.Agents, rule.ExcludeAgents) {
+			if _, ok := c.registry.Get(agent); !ok {
+				return fmt.Errorf("rule %s: unknown agent %q, available agents: %s",
+					rule.Name, agent, strings.Join(c.registry.List(), ", "))
+			}
 		}
 	}
+	return nil
+}
 
-	return allRules, nil
+// renderRules applies the budget of an agent to rules, warning about rules
+// over budget, and renders them.
+func (c *RulesCommand) renderRules(adapter genkit.AgentAdapter, rules []genkit.Rule) ([]genkit.RuleFile, error) {
+	rules, warnings := genkit.BudgetRules(adapter, rules, c.agentConfigs[adapter.Name()])
+	for _, warning := range warnings {
+		c.log.Warn("%s", warning)
+	}
+	return genkit.RenderRules(adapter, rules)
 }
 
 // preview prints rules to stdout without writing files.
 func (c *RulesCommand) preview(adapter genkit.AgentAdapter, rules []genkit.Rule) error {
-	for i, rule := range rules {
+	files, err := c.renderRules(adapter, rules)
+	if err != nil {
+		return err
+	}
+	for i, file := range files {
 		if i > 0 {
 			fmt.Println("\n" + strings.Repeat("=", 80) + "\n")
 		}
+		fmt.Printf("# File: %s\n\n", file.Path)
+		fmt.Print(file.Content)
+	}
+	return nil
+}
+
+// ruleChange is the content a rule file has on disk and the content it
+// should have. An empty Want means the file should be removed.
+type ruleChange struct {
+	Path string // slash-separated, relative to the project root
+	Have string
+	Want string
+}
+
+// planRules merges the rule files of an agent with the files on disk. The
+// result also covers files in the adapter's OutputDir that contain managed
+// sections but are no longer generated.
+func (c *RulesCommand) planRules(adapter genkit.AgentAdapter, rules []genkit.Rule) ([]ruleChange, error) {
+	files, err := c.renderRules(adapter, rules)
+	if err != nil {
+		return nil, err
+	}
+	root, err := c.root()
+	if err != nil {
+		return nil, err
+	}
 
-		filename, content, err := adapter.Transform(rule)
+	var changes []ruleChange
+	generated := make(map[string]bool)
+	for _, file := range files {
+		generated[file.Path] = true
+		have, err := readRuleFile(root, file.Path)
+		if err != nil {
+			return nil, err
+		}
+		want, err := genkit.MergeRuleFile(have, file.Content)
 		if err != nil {
-			return fmt.Errorf("transform rule %s: %w", rule.Name, err)
+			return nil, fmt.Errorf("merge %s: %w", file.Path, err)
 		}
+		changes = append(changes, ruleChange{Path: file.Path, Have: have, Want: want})
+	}
 
-		fmt.Printf("# File: %s/%s\n\n", adapter.OutputDir(), filename)
-		fmt.Print(content)
+	// Stale files are only looked for in directories devgen owns; the
+	// project root is shared with everything else.
+	dir := adapter.OutputDir()
+	if dir == "." {
+		return changes, nil
 	}
-	return nil
+	entries, err := os.ReadDir(filepath.Join(root, filepath.FromSlash(dir)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return changes, nil
+		}
+		return nil, fmt.Errorf("read directory %s: %w", dir, err)
+	}
+	for _, entry := range entries {
+		name := path.Join(dir, entry.Name())
+		if !entry.Type().IsRegular() || generated[name] {
+			continue
+		}
+		have, err := readRuleFile(root, name)
+		if err != nil {
+			return nil, err
+		}
+		if !genkit.HasManagedSections(have) {
+			continue
+		}
+		want, err := genkit.MergeRuleFile(have, "")
+		if err != nil {
+			return nil, fmt.Errorf("merge %s: %w", name, err)
+		}
+		changes = append(changes, ruleChange{Path: name, Have: have, Want: want})
+	}
+	return changes, nil
 }
 
-// writeRules writes rules to agent-specific directory.
-func (c *RulesCommand) writeRules(adapter genkit.AgentAdapter, rules []genkit.Rule) error {
-	outputDir := adapter.OutputDir()
+// readRuleFile returns the content of the rule file name, relative to root,
+// or an empty string if it does not exist.
+func readRuleFile(root, name string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(name)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("read file %s: %w", name, err)
+	}
+	return string(data), nil
+}
 
-	// Create output directory
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return fmt.Errorf("create directory %s: %w", outputDir, err)
+// writeRules writes rules to agent-specific files. The generated content is
+// merged into the managed sections of existing files, and files left without
+// content are removed.
+func (c *RulesCommand) writeRules(adapter genkit.AgentAdapter, rules []genkit.Rule) error {
+	changes, err := c.planRules(adapter, rules)
+	if err != nil {
+		return err
+	}
+	root, err := c.root()
+	if err != nil {
+		return err
 	}
 
 	c.log.Info("Generating rules for %s...", adapter.Name())
 
-	// Transform and write each rule
-	for _, rule := range rules {
-		filename, content, err := adapter.Transform(rule)
-		if err != nil {
-			return fmt.Errorf("transform rule %s: %w", rule.Name, err)
+	var written, removed []string
+	for _, change := range changes {
+		name := filepath.Join(root, filepath.FromSlash(change.Path))
+		if change.Want == "" {
+			if change.Have != "" {
+				if err := os.Remove(name); err != nil {
+					return fmt.Errorf("remove file %s: %w", name, err)
+				}
+				removed = append(removed, change.Path)
+			}
+			continue
 		}
-
-		filepath := filepath.Join(outputDir, filename)
-		if err := os.WriteFile(filepath, []byte(content), 0644); err != nil {
-			return fmt.Errorf("write file %s: %w", filepath, err)
+		if change.Want != change.Have {
+			if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+				return fmt.Errorf("create directory %s: %w", filepath.Dir(name), err)
+			}
+			if err := os.WriteFile(name, []byte(change.Want), 0644); err != nil {
+				return fmt.Errorf("write file %s: %w", name, err)
+			}
 		}
+		written = append(written, change.Path)
 	}
 
-	c.log.Done("Generated %v rule file(s) in %s", len(rules), outputDir)
-	for _, rule := range rules {
-		filename, _, err := adapter.Transform(rule)
-		if err != nil {
-			continue
+	c.log.Done("Generated %v rule file(s) for %s", len(written), adapter.Name())
+	for _, name := range written {
+		c.log.Item("%s", name)
+	}
+	if len(removed) > 0 {
+		c.log.Done("Removed %v stale rule file(s) for %s", len(removed), adapter.Name())
+		for _, name := range removed {
+			c.log.Item("%s", name)
 		}
-		filepath := filepath.Join(outputDir, filename)
-		c.log.Item("%s", filepath)
 	}
 
 	return nil
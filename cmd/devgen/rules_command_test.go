@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
@@ -690,4 +691,522 @@ func TestRulesCommand_ExecuteAll(t *testing.T) {
 			t.Errorf("ExecuteAll() created no files in %s", dir)
 		}
 	}
+
+	// Files shared with other tools are only written once they exist or
+	// their agent is configured
+	for _, name := range []string{"AGENTS.md", "CLAUDE.md", ".github/copilot-instructions.md"} {
+		if _, err := os.Stat(name); !os.IsNotExist(err) {
+			t.Errorf("ExecuteAll() created %s", name)
+		}
+	}
+	if err := os.WriteFile("AGENTS.md", []byte("# Team notes\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("devgen.toml", []byte("[rules.agents.claude]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.ExecuteAll(ctx, true); err != nil {
+		t.Fatalf("ExecuteAll() error = %v", err)
+	}
+	for _, name := range []string{"AGENTS.md", "CLAUDE.md"} {
+		if data, err := os.ReadFile(name); err != nil || !strings.Contains(string(data), "devgen:begin") {
+			t.Errorf("ExecuteAll() did not write %s: %v", name, err)
+		}
+	}
+}
+
+// TestRulesCommand_WriteRules_ManagedSections tests that hand-written content
+// is kept and stale rule files are removed
+func TestRulesCommand_WriteRules_ManagedSections(t *testing.T) {
+	t.Chdir(t.TempDir())
+	cmd := NewRulesCommand(genkit.NewLogger())
+	ruleA := genkit.Rule{Name: "rule-a", Description: "Rule A", AlwaysApply: true, Content: "# Rule A\n\nFirst."}
+	ruleB := genkit.Rule{Name: "rule-b", Description: "Rule B", AlwaysApply: true, Content: "# Rule B\n\nSecond."}
+
+	agents, _ := cmd.registry.Get("agents")
+	handWritten := "# Team notes\n\nRun make lint before pushing.\n"
+	if err := os.WriteFile("AGENTS.md", []byte(handWritten), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.writeRules(agents, []genkit.Rule{ruleA, ruleB}); err != nil {
+		t.Fatalf("writeRules() error = %v", err)
+	}
+	if err := cmd.writeRules(agents, []genkit.Rule{ruleB}); err != nil {
+		t.Fatalf("writeRules() error = %v", err)
+	}
+	data, err := os.ReadFile("AGENTS.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	if !strings.HasPrefix(content, handWritten) || strings.Contains(content, "Rule A") ||
+		!strings.Contains(content, "<!-- devgen:begin rule-b -->\n## Rule B\n\nSecond.\n<!-- devgen:end -->\n") {
+		t.Errorf("AGENTS.md =\n%s", content)
+	}
+
+	kiro, _ := cmd.registry.Get("kiro")
+	notes := filepath.Join(".kiro", "steering", "notes.md")
+	if err := os.MkdirAll(filepath.Dir(notes), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(notes, []byte("# Notes\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.writeRules(kiro, []genkit.Rule{ruleA, ruleB}); err != nil {
+		t.Fatalf("writeRules() error = %v", err)
+	}
+	if err := cmd.writeRules(kiro, []genkit.Rule{ruleB}); err != nil {
+		t.Fatalf("writeRules() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(".kiro", "steering", "rule-a.md")); !os.IsNotExist(err) {
+		t.Error("writeRules() kept the stale rule-a.md")
+	}
+	for _, name := range []string{"rule-b.md", "notes.md"} {
+		if _, err := os.Stat(filepath.Join(".kiro", "steering", name)); err != nil {
+			t.Errorf("writeRules() removed %s: %v", name, err)
+		}
+	}
+}
+
+// TestRulesCommand_WriteRules_Dir tests that rule files are read and written
+// relative to the project directory, not the working directory
+func TestRulesCommand_WriteRules_Dir(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(t.TempDir())
+	cmd := NewRulesCommand(genkit.NewLogger()).SetDir(dir)
+	rule := genkit.Rule{Name: "rule-a", Description: "Rule A", AlwaysApply: true, Content: "# Rule A"}
+
+	kiro, _ := cmd.registry.Get("kiro")
+	stale := filepath.Join(dir, ".kiro", "steering", "stale.md")
+	if err := os.MkdirAll(filepath.Dir(stale), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(stale, []byte(genkit.ManagedSection("stale", "# Stale")), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.writeRules(kiro, []genkit.Rule{rule}); err != nil {
+		t.Fatalf("writeRules() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".kiro", "steering", "rule-a.md")); err != nil {
+		t.Errorf("writeRules() did not write into the project directory: %v", err)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Error("writeRules() kept the stale rule file in the project directory")
+	}
+	if _, err := os.Stat(".kiro"); !os.IsNotExist(err) {
+		t.Error("writeRules() wrote into the working directory")
+	}
+}
+
+// TestRulesCommand_Check tests detecting out-of-date rule files
+func TestRulesCommand_Check(t *testing.T) {
+	t.Chdir(t.TempDir())
+	cmd := NewRulesCommand(genkit.NewLogger())
+
+	// Agents without rule files on disk are skipped with "all".
+	if err := cmd.Check(context.Background(), "all"); err != nil {
+		t.Fatalf("Check(all) without rule files error = %v", err)
+	}
+	if err := cmd.Check(context.Background(), "kiro"); err == nil {
+		t.Error("Check(kiro) without rule files should fail")
+	}
+
+	if err := cmd.Execute(context.Background(), "kiro", true); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if err := cmd.Check(context.Background(), "all"); err != nil {
+		t.Fatalf("Check(all) after writing error = %v", err)
+	}
+
+	file := filepath.Join(".kiro", "steering", "devgen.md")
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte(strings.Replace(string(data), "Code Generation Toolkit", "Toolkit", 1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = cmd.Check(context.Background(), "all")
+	if err == nil || !strings.Contains(err.Error(), "devgen rules --agent all -w") {
+		t.Errorf("Check(all) with modified file error = %v", err)
+	}
+}
+
+// TestRulesCommand_CollectRules_ProjectRule tests building the project rule
+// from the annotated declarations of the built-in tools
+func TestRulesCommand_CollectRules_ProjectRule(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	files := map[string]string{
+		"go.mod": "module example.com/shop\n\ngo 1.24\n",
+		"order/order.go": `package order
+
+// OrderStatus is the status of an order.
+// enumgen:@enum(string, json)
+type OrderStatus int
+
+const (
+	OrderStatusPending OrderStatus = iota + 1
+	// enumgen:@name(Shipped)
+	OrderStatusSent
+)
+
+// Order is an order.
+// validategen:@validate
+type Order struct {
+	// validategen:@required
+	ID string
+	// validategen:@gte(1)
+	Quantity int
+}
+
+// OrderDTO is the API representation of an order.
+type OrderDTO struct {
+	ID string
+}
+
+// Converter converts orders.
+// convertgen:@converter
+type Converter interface {
+	// convertgen:@ignore(Quantity)
+	ToDTO(o *Order) *OrderDTO
+}
+
+// Repository stores orders.
+// delegatorgen:@delegator
+type Repository interface {
+	// delegatorgen:@cache(ttl=10m)
+	Get(id string) (*Order, error)
+	Save(o *Order) error
+}
+`,
+		"tools/tools.go": "package tools\n",
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cmd := NewRulesCommand(genkit.NewLogger()).SetProjectPatterns([]string{"./..."})
+	rules, err := cmd.collectRules(context.Background())
+	if err != nil {
+		t.Fatalf("collectRules() error = %v", err)
+	}
+	var project *genkit.Rule
+	for i := range rules {
+		if rules[i].Name == genkit.ProjectRuleName {
+			project = &rules[i]
+		}
+	}
+	if project == nil {
+		t.Fatal("collectRules() did not build the project rule")
+	}
+	if len(project.Globs) != 1 || project.Globs[0] != "order/*.go" {
+		t.Errorf("Globs = %v", project.Globs)
+	}
+	for _, want := range []string{
+		"| `OrderStatusPending` | `1` | `Pending` |",
+		"| `OrderStatusSent` | `2` | `Shipped` |",
+		"| `Quantity` | `int` | `@gte(1)` |",
+		"- `ToDTO(*Order) *OrderDTO`: ignores `Quantity`",
+		"Builder: `NewRepositoryDelegator` with `WithCache`.",
+		"- `Save`: passed through",
+	} {
+		if !strings.Contains(project.Content, want) {
+			t.Errorf("project rule missing %q:\n%s", want, project.Content)
+		}
+	}
+}
+
+// TestRulesCommand_CollectRules_Template tests templated project rules
+func TestRulesCommand_CollectRules_Template(t *testing.T) {
+	t.Chdir(t.TempDir())
+	files := map[string]string{
+		"go.mod": "module example.com/shop\n\ngo 1.24\n",
+		"devgen.toml": `[rules]
+source_dir = ".devgen/rules"
+include_builtin = false
+
+[tools.enumgen.options]
+name_case = "snake"
+`,
+		".devgen/rules/enums.md": `---
+description: Enum conventions
+template: true
+excludeAgents: [copilot]
+---
+
+# Enums in {{ .Module }}
+
+Names use {{ index (.Tool "enumgen").Options "name_case" }} case.
+{{ range (.Tool "enumgen").Annotations }}{{ if eq .Name "enum" }}Annotate enums with enumgen:@{{ .Name }}.{{ end }}{{ end }}
+`,
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cmd := NewRulesCommand(genkit.NewLogger())
+	rules, err := cmd.collectRules(context.Background())
+	if err != nil {
+		t.Fatalf("collectRules() error = %v", err)
+	}
+	if len(rules) == 0 || rules[0].Name != "enums" {
+		t.Fatalf("collectRules() = %+v", rules)
+	}
+	want := "# Enums in example.com/shop\n\nNames use snake case.\nAnnotate enums with enumgen:@enum."
+	if rules[0].Content != want {
+		t.Errorf("Content = %q, want %q", rules[0].Content, want)
+	}
+	if rules[0].ForAgent("copilot") {
+		t.Error("rule should be excluded for copilot")
+	}
+
+	if err := os.WriteFile(".devgen/rules/enums.md", []byte("---\nagents: [vim]\n---\n# Enums\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cmd.collectRules(context.Background()); err == nil || !strings.Contains(err.Error(), `rule enums: unknown agent "vim"`) {
+		t.Errorf("collectRules() with unknown agent error = %v", err)
+	}
+}
+
+func TestRulesCommand_WriteRules_Budget(t *testing.T) {
+	t.Chdir(t.TempDir())
+	files := map[string]string{
+		"go.mod": "module example.com/shop\n\ngo 1.24\n",
+		"devgen.toml": `[rules]
+source_dir = ".devgen/rules"
+include_builtin = false
+
+[rules.agents.windsurf]
+max_chars = 100
+split = true
+`,
+		".devgen/rules/guide.md": "---\ndescription: Guide\nglobs: [\"**/*.go\"]\n---\n# Guide\n\n## Testing\n<!-- devgen:globs **/*_test.go -->\n\nUse ginkgo.\n\n## Errors\n\n" +
+			strings.Repeat("Wrap errors. ", 10) + "\n",
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cmd := NewRulesCommand(genkit.NewLogger())
+	if err := cmd.Execute(context.Background(), "windsurf", true); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	for _, name := range []string{"guide.md", "guide-testing.md", "guide-errors.md"} {
+		if _, err := os.Stat(filepath.Join(".windsurf/rules", name)); err != nil {
+			t.Errorf("%s not written: %v", name, err)
+		}
+	}
+	data, err := os.ReadFile(".windsurf/rules/guide-testing.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "globs: **/*_test.go") {
+		t.Errorf("guide-testing.md =\n%s", data)
+	}
+
+	if err := os.WriteFile("devgen.toml", []byte("[rules.agents.vim]\nmax_chars = 100\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cmd.collectRules(context.Background()); err == nil || !strings.Contains(err.Error(), `rules.agents: unknown agent "vim"`) {
+		t.Errorf("collectRules() with unknown agent error = %v", err)
+	}
+}
+
+func TestRulesCommand_Execute_ConfigAdapter(t *testing.T) {
+	t.Chdir(t.TempDir())
+	files := map[string]string{
+		"devgen.toml": `[rules]
+source_dir = ".devgen/rules"
+include_builtin = false
+
+[[rules.adapters]]
+name = "zed"
+output_dir = ".zed/rules"
+frontmatter = """
+---
+description: {{ .Description }}
+globs: {{ .Globs }}
+---
+"""
+`,
+		".devgen/rules/style.md": "---\ndescription: Style\nglobs: [\"**/*.go\"]\nagents: [zed]\n---\n# Style\n",
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cmd := NewRulesCommand(genkit.NewLogger())
+	if err := cmd.Execute(context.Background(), "zed", true); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	data, err := os.ReadFile(".zed/rules/style.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "---\ndescription: Style\nglobs: **/*.go\n---\n\n") {
+		t.Errorf("style.md =\n%s", data)
+	}
+	if !slices.Contains(cmd.ListAgents(), "zed") {
+		t.Errorf("ListAgents() = %v", cmd.ListAgents())
+	}
+}
+
+func TestRulesCommand_Import(t *testing.T) {
+	t.Chdir(t.TempDir())
+	files := map[string]string{
+		"devgen.toml":                "[rules]\nsource_dir = \"rules\"\ninclude_builtin = false\n",
+		".kiro/steering/release.md":  "---\ndescription: Release workflow\ninclusion: fileMatch\nfileMatchPattern: ['Makefile', '**/*.sh']\n---\n\n# Release\n",
+		".kiro/steering/enumgen.md":  "---\ninclusion: always\n---\n\n" + genkit.ManagedSection("enumgen", "# enumgen"),
+		".kiro/steering/existing.md": "---\ninclusion: always\n---\n\n# Existing\n",
+		"rules/existing.md":          "# Kept\n",
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cmd := NewRulesCommand(genkit.NewLogger())
+	if err := cmd.Import("kiro", true); err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if _, err := os.Stat("rules/enumgen.md"); err == nil {
+		t.Error("files generated by devgen should not be imported")
+	}
+	if data, _ := os.ReadFile("rules/existing.md"); string(data) != "# Kept\n" {
+		t.Errorf("existing source file was overwritten:\n%s", data)
+	}
+
+	// The imported source generates the same rule for Cursor.
+	rules, err := cmd.collectRules(context.Background())
+	if err != nil {
+		t.Fatalf("collectRules() error = %v", err)
+	}
+	idx := slices.IndexFunc(rules, func(r genkit.Rule) bool { return r.Name == "release" })
+	if idx < 0 {
+		t.Fatalf("collectRules() = %+v", rules)
+	}
+	release := rules[idx]
+	if release.Description != "Release workflow" || !slices.Equal(release.Globs, []string{"Makefile", "**/*.sh"}) ||
+		release.Content != "# Release" {
+		t.Errorf("imported rule = %+v", release)
+	}
+
+	if err := cmd.Import("agents", true); err == nil || !strings.Contains(err.Error(), "does not support import") {
+		t.Errorf("Import(agents) error = %v", err)
+	}
+}
+
+// TestRulesCommand_Import_Dir tests importing from and into the project
+// directory instead of the working directory
+func TestRulesCommand_Import_Dir(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(t.TempDir())
+	files := map[string]string{
+		"devgen.toml":               "[rules]\nsource_dir = \"rules\"\n",
+		".kiro/steering/release.md": "---\ninclusion: always\n---\n\n# Release\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := NewRulesCommand(genkit.NewLogger()).SetDir(dir).Import("kiro", true); err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "rules", "release.md")); err != nil {
+		t.Errorf("Import() did not write into source_dir of the project: %v", err)
+	}
+	if _, err := os.Stat("rules"); !os.IsNotExist(err) {
+		t.Error("Import() wrote into the working directory")
+	}
+}
+
+func TestRulesCommand_Lint(t *testing.T) {
+	t.Chdir(t.TempDir())
+	files := map[string]string{
+		"devgen.toml":                  "[rules]\nsource_dir = \"rules\"\n",
+		"main.go":                      "package main\n",
+		"rules/ok.md":                  "---\ndescription: OK\nglobs: ['**/*.go', '{main,cmd}.go']\n---\n\n# OK\n",
+		"rules/always.md":              "---\nalwaysApply: true\ntemplate: true\nmaxChars: 100\n---\n\n# {{ .Module }}\n",
+		"rules/typo.md":                "---\ndescription: Typo\nglob: ['*.go']\nalwaysapply: true\n---\n\n# Typo\n",
+		"rules/globs.md":               "---\ndescription: Globs\nglobs: ['api/**', 'cmd/{a,b']\n---\n\n# Globs\n",
+		"rules/silent.md":              "---\nglobs: ['*.go']\n---\n\n# Silent\n",
+		"rules/broken.md":              "---\nglobs: *.go\n---\n\n# Broken\n",
+		"rules/exclusive.md":           "---\nalwaysApply: true\nagents: [nope]\n---\n\n# Exclusive\n",
+		"rules/devgen-tool-enumgen.md": "---\nalwaysApply: true\n---\n\n# Shadows the tool rule\n",
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cmd := NewRulesCommand(genkit.NewLogger())
+	rules, issues, err := cmd.collectSourcedRules(context.Background(), true)
+	if err != nil {
+		t.Fatalf("collectSourcedRules() error = %v", err)
+	}
+	ruleIssues, err := genkit.LintRules(rules, ".")
+	if err != nil {
+		t.Fatalf("LintRules() error = %v", err)
+	}
+	var got []string
+	for _, issue := range append(issues, ruleIssues...) {
+		got = append(got, issue.String())
+	}
+	want := []string{
+		`rules/broken.md: broken: parse frontmatter: `,
+		`rules/exclusive.md: rule exclusive: unknown agent "nope"`,
+		`rules/typo.md: typo: unknown frontmatter key "glob"`,
+		`rules/typo.md: typo: unknown frontmatter key "alwaysapply"`,
+		`rules/globs.md: globs: glob "api/**" matches no file`,
+		`rules/globs.md: globs: invalid glob "cmd/{a,b": unmatched '{'`,
+		`rules/silent.md: silent: description is empty`,
+		`tool enumgen: devgen-tool-enumgen: duplicate rule name, also defined by rules/devgen-tool-enumgen.md`,
+	}
+	for _, w := range want {
+		if !slices.ContainsFunc(got, func(g string) bool { return strings.HasPrefix(g, w) }) {
+			t.Errorf("missing issue %q", w)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("issues =\n%s", strings.Join(got, "\n"))
+	}
+
+	if err := cmd.Lint(context.Background()); err == nil {
+		t.Error("Lint() should fail with issues")
+	}
 }
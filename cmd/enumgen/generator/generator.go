@@ -4,6 +4,7 @@ package generator
 import (
 	"fmt"
 	"strings"
+	"unicode"
 
 	"github.com/tlipoca9/devgen/cmd/enumgen/rules"
 	"github.com/tlipoca9/devgen/genkit"
@@ -20,6 +21,19 @@ const (
 	ErrCodeNameMissingParam = "E004"
 )
 
+// Values of the name_case option, the style of the JSON names of values
+// without @name.
+const (
+	// NameCasePreserve keeps the value name without the type prefix: "InProgress".
+	NameCasePreserve = "preserve"
+	// NameCaseSnake derives snake_case names: "in_progress".
+	NameCaseSnake = "snake"
+	// NameCaseCamel derives camelCase names: "inProgress".
+	NameCaseCamel = "camel"
+	// NameCaseKebab derives kebab-case names: "in-progress".
+	NameCaseKebab = "kebab"
+)
+
 // GenerateOption represents an enum generation option.
 // enumgen:@enum(string, json, text, sql)
 type GenerateOption int
@@ -54,7 +68,10 @@ const (
 )
 
 // Generator generates enum helper methods.
-type Generator struct{}
+type Generator struct {
+	// nameCase is applied to the JSON names of values without @name.
+	nameCase string
+}
 
 // New creates a new Generator.
 func New() *Generator {
@@ -66,6 +83,27 @@ func (eg *Generator) Name() string {
 	return ToolName
 }
 
+// OptionsSchema implements genkit.OptionsTool.
+func (eg *Generator) OptionsSchema() []genkit.OptionSpec {
+	return []genkit.OptionSpec{
+		{
+			Name:    "name_case",
+			Type:    genkit.OptionEnum,
+			Default: NameCasePreserve,
+			Values:  []string{NameCasePreserve, NameCaseSnake, NameCaseCamel, NameCaseKebab},
+			Doc:     "未使用 @name 的枚举值在 JSON 中的命名风格：preserve、snake、camel、kebab（不影响 String/Text/SQL）",
+		},
+	}
+}
+
+// SetOptions implements genkit.OptionsTool.
+func (eg *Generator) SetOptions(opts map[string]any) error {
+	if s, ok := opts["name_case"].(string); ok {
+		eg.nameCase = s
+	}
+	return nil
+}
+
 // Config returns the tool configuration for VSCode extension integration.
 func (eg *Generator) Config() genkit.ToolConfig {
 	return eg.config()
@@ -195,7 +233,7 @@ func (eg *Generator) Validate(gen *genkit.Generator, _ *genkit.Logger) []genkit.
 			if !genkit.HasAnnotation(enum.Doc, ToolName, "enum") {
 				continue
 			}
-			eg.validateEnum(c, enum)
+			eg.validateEnum(gen, c, enum)
 		}
 	}
 
@@ -203,7 +241,9 @@ func (eg *Generator) Validate(gen *genkit.Generator, _ *genkit.Logger) []genkit.
 }
 
 // validateEnum validates a single enum and collects diagnostics.
-func (eg *Generator) validateEnum(c *genkit.DiagnosticCollector, enum *genkit.Enum) {
+// Suggested fixes are computed against the sources of gen; a nil gen
+// reads them from disk.
+func (eg *Generator) validateEnum(gen *genkit.Generator, c *genkit.DiagnosticCollector, enum *genkit.Enum) {
 	typeName := enum.Name
 
 	// Check underlying type
@@ -219,20 +259,30 @@ func (eg *Generator) validateEnum(c *genkit.DiagnosticCollector, enum *genkit.En
 	// For string types, @name annotation is not supported
 	if isStringType {
 		for _, v := range enum.Values {
-			if genkit.HasAnnotation(v.Doc, ToolName, "name") {
+			if ann := genkit.GetAnnotation(v.Doc, ToolName, "name"); ann != nil {
 				c.Errorf(ErrCodeNameOnStringType, v.Pos,
 					"@name annotation is not supported for string underlying type (on %s)", v.Name)
+				if edit, ok := gen.DeleteLineContaining(v.Pos, ann.Raw); ok {
+					c.WithFix("remove "+ann.Raw, edit)
+				}
 			}
 		}
 		return
 	}
 
 	// For non-string types, check for @name issues
-	nameSet := make(map[string]string) // name -> value name
+	nameSet := make(map[string]string)     // name -> value name
+	jsonNameSet := make(map[string]string) // JSON name -> value name
+	enumAnn := genkit.GetAnnotation(enum.Doc, ToolName, "enum")
+	jsonCase := eg.jsonCase(enum) && enumAnn != nil && enumAnn.Has(GenerateOptionJSON.String())
 	for _, v := range enum.Values {
 		ann := genkit.GetAnnotation(v.Doc, ToolName, "name")
 		if ann != nil && len(ann.Flags) == 0 {
 			c.Error(ErrCodeNameMissingParam, "@name annotation requires a name parameter", v.Pos)
+			fixed := fmt.Sprintf("%s:@name(%s)", ToolName, TrimPrefix(v.Name, typeName))
+			if edit, ok := gen.ReplaceText(v.Pos, ann.Raw, fixed); ok {
+				c.WithFix("replace "+ann.Raw+" with "+fixed, edit)
+			}
 			continue
 		}
 
@@ -242,6 +292,16 @@ func (eg *Generator) validateEnum(c *genkit.DiagnosticCollector, enum *genkit.En
 				"duplicate @name %q, already used by %s", name, existing)
 		}
 		nameSet[name] = v.Name
+
+		if !jsonCase {
+			continue
+		}
+		jsonName := eg.jsonName(v, typeName)
+		if existing, ok := jsonNameSet[jsonName]; ok {
+			c.Errorf(ErrCodeDuplicateName, v.Pos,
+				"duplicate JSON name %q with name_case %s, already used by %s", jsonName, eg.nameCase, existing)
+		}
+		jsonNameSet[jsonName] = v.Name
 	}
 }
 
@@ -322,7 +382,7 @@ func (eg *Generator) WriteHeader(g *genkit.GeneratedFile, pkgName string) {
 func (eg *Generator) GenerateEnum(g *genkit.GeneratedFile, enum *genkit.Enum) error {
 	// Validate first using collector
 	c := genkit.NewDiagnosticCollector(ToolName)
-	eg.validateEnum(c, enum)
+	eg.validateEnum(nil, c, enum)
 	if c.HasErrors() {
 		// Return first error message
 		for _, d := range c.Collect() {
@@ -347,6 +407,9 @@ func (eg *Generator) GenerateEnum(g *genkit.GeneratedFile, enum *genkit.Enum) er
 	// Check if underlying type is string
 	isStringType := enum.UnderlyingType == "string"
 
+	// name_case only applies to JSON, which then has its own names
+	jsonCase := genJSON && eg.jsonCase(enum)
+
 	// 1. Enum type methods (IsValid, String, MarshalJSON, etc.) - at the top
 
 	// IsValid is always generated
@@ -388,9 +451,15 @@ func (eg *Generator) GenerateEnum(g *genkit.GeneratedFile, enum *genkit.Enum) er
 			Name:    "MarshalJSON",
 			Results: genkit.GoResults{{Type: "[]byte"}, {Type: "error"}},
 		}, " {")
-		if isStringType {
+		switch {
+		case isStringType:
 			g.P("return ", genkit.GoImportPath("encoding/json").Ident("Marshal"), "(string(x))")
-		} else {
+		case jsonCase:
+			g.P("if name, ok := ", enumsVar, ".jsonNames[x]; ok {")
+			g.P("return ", genkit.GoImportPath("encoding/json").Ident("Marshal"), "(name)")
+			g.P("}")
+			g.P("return ", genkit.GoImportPath("encoding/json").Ident("Marshal"), "(", enumsVar, ".Name(x))")
+		default:
 			g.P("return ", genkit.GoImportPath("encoding/json").Ident("Marshal"), "(", enumsVar, ".Name(x))")
 		}
 		g.P("}")
@@ -407,10 +476,17 @@ func (eg *Generator) GenerateEnum(g *genkit.GeneratedFile, enum *genkit.Enum) er
 		g.P("if err := ", genkit.GoImportPath("encoding/json").Ident("Unmarshal"), "(data, &s); err != nil {")
 		g.P("return err")
 		g.P("}")
-		g.P("v, err := ", enumsVar, ".Parse(s)")
-		g.P("if err != nil {")
-		g.P("return err")
-		g.P("}")
+		if jsonCase {
+			g.P("v, ok := ", enumsVar, ".byJSONName[s]")
+			g.P("if !ok {")
+			g.P("return ", genkit.GoImportPath("fmt").Ident("Errorf"), "(\"invalid ", typeName, ": %q\", s)")
+			g.P("}")
+		} else {
+			g.P("v, err := ", enumsVar, ".Parse(s)")
+			g.P("if err != nil {")
+			g.P("return err")
+			g.P("}")
+		}
 		g.P("*x = v")
 		g.P("return nil")
 		g.P("}")
@@ -530,6 +606,19 @@ func (eg *Generator) GenerateEnum(g *genkit.GeneratedFile, enum *genkit.Enum) er
 			g.P(fmt.Sprintf("%q", name), ": ", v.Name, ",")
 		}
 		g.P("},")
+
+		if jsonCase {
+			g.P("jsonNames: map[", typeName, "]string{")
+			for _, v := range enum.Values {
+				g.P(v.Name, ": ", fmt.Sprintf("%q", eg.jsonName(v, typeName)), ",")
+			}
+			g.P("},")
+			g.P("byJSONName: map[string]", typeName, "{")
+			for _, v := range enum.Values {
+				g.P(fmt.Sprintf("%q", eg.jsonName(v, typeName)), ": ", v.Name, ",")
+			}
+			g.P("},")
+		}
 	}
 	g.P("}")
 
@@ -543,6 +632,10 @@ func (eg *Generator) GenerateEnum(g *genkit.GeneratedFile, enum *genkit.Enum) er
 	} else {
 		g.P("names  map[", typeName, "]string")
 		g.P("byName map[string]", typeName)
+		if jsonCase {
+			g.P("jsonNames  map[", typeName, "]string")
+			g.P("byJSONName map[string]", typeName)
+		}
 	}
 	g.P("}")
 
@@ -667,6 +760,22 @@ func GetValueName(v *genkit.EnumValue, typeName string) string {
 	return getValueNameFromAnnotation(ann, v.Name, typeName)
 }
 
+// jsonCase reports whether name_case changes the JSON names of enum. Values
+// of enums with a string underlying type are their own JSON names.
+func (eg *Generator) jsonCase(enum *genkit.Enum) bool {
+	return eg.nameCase != "" && eg.nameCase != NameCasePreserve && enum.UnderlyingType != "string"
+}
+
+// jsonName returns the JSON name of v: its @name, or its name converted to
+// name_case.
+func (eg *Generator) jsonName(v *genkit.EnumValue, typeName string) string {
+	ann := genkit.GetAnnotation(v.Doc, ToolName, "name")
+	if ann != nil && len(ann.Flags) > 0 {
+		return ann.Flags[0]
+	}
+	return ConvertNameCase(TrimPrefix(v.Name, typeName), eg.nameCase)
+}
+
 // getValueNameFromAnnotation extracts the name from annotation or falls back to TrimPrefix.
 // This is the core logic shared by validation and generation.
 func getValueNameFromAnnotation(ann *genkit.Annotation, valueName, typeName string) string {
@@ -676,6 +785,55 @@ func getValueNameFromAnnotation(ann *genkit.Annotation, valueName, typeName stri
 	return TrimPrefix(valueName, typeName)
 }
 
+// ConvertNameCase converts a Go identifier such as "InProgress" to the
+// given case. Acronyms are kept together: "HTTPServer" -> "http_server".
+// Unknown cases and NameCasePreserve return name unchanged.
+func ConvertNameCase(name, c string) string {
+	var sep string
+	switch c {
+	case NameCaseSnake:
+		sep = "_"
+	case NameCaseKebab:
+		sep = "-"
+	case NameCaseCamel:
+	default:
+		return name
+	}
+
+	words := splitWords(name)
+	for i, w := range words {
+		w = strings.ToLower(w)
+		if c == NameCaseCamel && i > 0 {
+			w = strings.ToUpper(w[:1]) + w[1:]
+		}
+		words[i] = w
+	}
+	return strings.Join(words, sep)
+}
+
+// splitWords splits an identifier at case changes and underscores.
+// Digits stay with the preceding word: "Level2Cache" -> ["Level2", "Cache"].
+func splitWords(name string) []string {
+	var words []string
+	runes := []rune(name)
+	start := 0
+	for i := 1; i <= len(runes); i++ {
+		if i < len(runes) && runes[i] != '_' {
+			cur, prev := runes[i], runes[i-1]
+			boundary := unicode.IsUpper(cur) && (unicode.IsLower(prev) || unicode.IsDigit(prev) ||
+				(unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1])))
+			if !boundary {
+				continue
+			}
+		}
+		if word := strings.Trim(string(runes[start:i]), "_"); word != "" {
+			words = append(words, word)
+		}
+		start = i
+	}
+	return words
+}
+
 // WriteTestHeader writes the test file header.
 func (eg *Generator) WriteTestHeader(g *genkit.GeneratedFile, pkgName string) {
 	g.P("// Code generated by ", ToolName, ". DO NOT EDIT.")
@@ -768,6 +926,9 @@ func (eg *Generator) GenerateEnumTest(g *genkit.GeneratedFile, enum *genkit.Enum
 		g.P("}{")
 		for _, v := range enum.Values {
 			name := GetValueName(v, typeName)
+			if eg.jsonCase(enum) {
+				name = eg.jsonName(v, typeName)
+			}
 			if isStringType {
 				g.P("{name: \"", v.Name, "\", value: ", v.Name, ", wantJSON: `\"` + string(", v.Name, ") + `\"`},")
 			} else {
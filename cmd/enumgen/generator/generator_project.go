@@ -0,0 +1,70 @@
+package generator
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+
+	"github.com/tlipoca9/devgen/genkit"
+)
+
+// ProjectRule implements genkit.ProjectRuleTool.
+// It lists the enums of the loaded packages with their values.
+func (eg *Generator) ProjectRule(gen *genkit.Generator) genkit.ProjectRule {
+	var pr genkit.ProjectRule
+	var sb strings.Builder
+	for _, pkg := range gen.Packages {
+		enums := eg.FindEnums(pkg)
+		if len(enums) == 0 {
+			continue
+		}
+		pr.Packages = append(pr.Packages, pkg)
+		for _, enum := range enums {
+			eg.describeEnum(&sb, enum)
+		}
+	}
+	if sb.Len() == 0 {
+		return genkit.ProjectRule{}
+	}
+
+	pr.Content = "## Enums (enumgen)\n\n" +
+		"Use the constants below instead of literal values. Every enum has `IsValid()` and a `<Type>Enums` " +
+		"variable listing its values; the options name the other generated methods.\n" + sb.String()
+	return pr
+}
+
+// describeEnum writes the section of an enum in the project rule.
+func (eg *Generator) describeEnum(sb *strings.Builder, enum *genkit.Enum) {
+	fmt.Fprintf(sb, "\n### `%s` (package `%s`)\n\n", enum.Name, enum.Pkg.PkgPath)
+	fmt.Fprintf(sb, "Underlying type `%s`", enum.UnderlyingType)
+	if ann := genkit.GetAnnotation(enum.Doc, ToolName, "enum"); ann != nil && len(ann.Flags) > 0 {
+		fmt.Fprintf(sb, ", options: `%s`", strings.Join(ann.Flags, "`, `"))
+	}
+	sb.WriteString(".\n\n")
+
+	isStringType := enum.UnderlyingType == "string"
+	if isStringType {
+		sb.WriteString("| Constant | Value |\n|----------|-------|\n")
+	} else {
+		sb.WriteString("| Constant | Value | Name |\n|----------|-------|------|\n")
+	}
+	for _, v := range enum.Values {
+		value := constValue(enum.Pkg, v.Name, v.Value)
+		if isStringType {
+			fmt.Fprintf(sb, "| `%s` | `%s` |\n", v.Name, value)
+			continue
+		}
+		fmt.Fprintf(sb, "| `%s` | `%s` | `%s` |\n", v.Name, value, GetValueName(v, enum.Name))
+	}
+}
+
+// constValue returns the value of the constant name, falling back to the
+// expression in the source if the package has no type information.
+func constValue(pkg *genkit.Package, name, expr string) string {
+	if pkg.TypesPkg != nil {
+		if c, ok := pkg.TypesPkg.Scope().Lookup(name).(*types.Const); ok {
+			return c.Val().ExactString()
+		}
+	}
+	return expr
+}
@@ -86,6 +86,83 @@ var _ = Describe("Generator", func() {
 		})
 	})
 
+	Describe("ConvertNameCase", func() {
+		It("should convert identifiers to the given case", func() {
+			Expect(generator.ConvertNameCase("InProgress", generator.NameCaseSnake)).To(Equal("in_progress"))
+			Expect(generator.ConvertNameCase("InProgress", generator.NameCaseCamel)).To(Equal("inProgress"))
+			Expect(generator.ConvertNameCase("InProgress", generator.NameCaseKebab)).To(Equal("in-progress"))
+			Expect(generator.ConvertNameCase("InProgress", generator.NameCasePreserve)).To(Equal("InProgress"))
+		})
+
+		It("should keep acronyms and digits together", func() {
+			Expect(generator.ConvertNameCase("HTTPServer", generator.NameCaseSnake)).To(Equal("http_server"))
+			Expect(generator.ConvertNameCase("Level2Cache", generator.NameCaseKebab)).To(Equal("level2-cache"))
+			Expect(generator.ConvertNameCase("UserID", generator.NameCaseCamel)).To(Equal("userId"))
+			Expect(generator.ConvertNameCase("Already_Snake", generator.NameCaseSnake)).To(Equal("already_snake"))
+		})
+	})
+
+	Describe("Options", func() {
+		It("should apply name_case to names without @name", func() {
+			opts, err := genkit.ValidateOptions(gen.OptionsSchema(), map[string]any{"name_case": "snake"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gen.SetOptions(opts)).To(Succeed())
+
+			g := genkit.New()
+			pkg := &genkit.Package{Name: "testpkg", PkgPath: "testpkg", Dir: "/src/testpkg"}
+			enum := &genkit.Enum{
+				Name:           "Status",
+				Doc:            "enumgen:@enum(string, json)",
+				Pkg:            pkg,
+				UnderlyingType: "int",
+				Values: []*genkit.EnumValue{
+					{Name: "StatusInProgress", Value: "1"},
+					{Name: "StatusDone", Value: "2", Doc: "enumgen:@name(Finished)"},
+				},
+			}
+			gf := g.NewGeneratedFile("/src/testpkg/status_enum.go", pkg.GoImportPath())
+			gen.WriteHeader(gf, pkg.Name)
+			Expect(gen.GenerateEnum(gf, enum)).To(Succeed())
+
+			files, err := g.DryRun()
+			Expect(err).NotTo(HaveOccurred())
+			code := string(files["/src/testpkg/status_enum.go"])
+			Expect(code).To(MatchRegexp(`StatusInProgress: +"in_progress",`))
+			Expect(code).To(MatchRegexp(`"Finished": +StatusDone,`))
+			Expect(code).To(ContainSubstring("StatusEnums.byJSONName[s]"))
+
+			// String and Names keep the names of GetValueName
+			Expect(code).To(MatchRegexp(`StatusInProgress: +"InProgress",`))
+			Expect(generator.GetValueName(enum.Values[0], "Status")).To(Equal("InProgress"))
+		})
+
+		It("should report values with the same JSON name", func() {
+			Expect(gen.SetOptions(map[string]any{"name_case": "snake"})).To(Succeed())
+			pkg := &genkit.Package{Name: "testpkg", PkgPath: "testpkg", Dir: "/src/testpkg"}
+			enum := &genkit.Enum{
+				Name:           "Level",
+				Doc:            "enumgen:@enum(json)",
+				Pkg:            pkg,
+				UnderlyingType: "int",
+				Values: []*genkit.EnumValue{
+					{Name: "LevelHTTPServer", Value: "1"},
+					{Name: "LevelHttpServer", Value: "2"},
+				},
+			}
+			pkg.Enums = []*genkit.Enum{enum}
+			g := genkit.New()
+			g.Packages = []*genkit.Package{pkg}
+			diags := gen.Validate(g, nil)
+			Expect(diags).To(HaveLen(1))
+			Expect(diags[0].Message).To(ContainSubstring(`duplicate JSON name "http_server"`))
+		})
+
+		It("should reject unknown name cases", func() {
+			_, err := genkit.ValidateOptions(gen.OptionsSchema(), map[string]any{"name_case": "pascal"})
+			Expect(err).To(MatchError(ContainSubstring("expected one of: preserve, snake, camel, kebab")))
+		})
+	})
+
 	Describe("FindEnums", func() {
 		It("should find enums with annotation", func() {
 			pkg := &genkit.Package{
@@ -0,0 +1,15 @@
+package generator_test
+
+import (
+	"testing"
+
+	"github.com/tlipoca9/devgen/cmd/enumgen/generator"
+	"github.com/tlipoca9/devgen/genkit/genkittest"
+)
+
+func TestGolden(t *testing.T) {
+	genkittest.Run(t, generator.New(), "testdata/basic")
+	genkittest.Run(t, generator.New(), "testdata/name_case", genkittest.Options{
+		ToolOptions: map[string]any{"name_case": "kebab"},
+	})
+}
@@ -0,0 +1,13 @@
+package basic
+
+// Status represents the lifecycle of an order.
+// enumgen:@enum(string, json, text)
+type Status int
+
+const (
+	// StatusPending is waiting for payment.
+	StatusPending Status = iota + 1
+	// enumgen:@name(Paid)
+	StatusPaid
+	StatusShipped
+)
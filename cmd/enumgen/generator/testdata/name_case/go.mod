@@ -0,0 +1,3 @@
+module example.com/namecase
+
+go 1.21
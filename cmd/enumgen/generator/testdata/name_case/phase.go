@@ -0,0 +1,12 @@
+package namecase
+
+// Phase is a build phase.
+// enumgen:@enum(string, json)
+type Phase int
+
+const (
+	PhaseInProgress Phase = iota + 1
+	PhaseDone
+	// enumgen:@name(custom_name)
+	PhaseCustom
+)
@@ -6,6 +6,16 @@ import "github.com/tlipoca9/devgen/genkit"
 // ToolName is the name of this tool, used in annotations.
 const ToolName = "validategen"
 
+// Error styles for the generated Validate() method.
+const (
+	// ErrorStyleJoined returns a single error with all messages joined by "; ".
+	ErrorStyleJoined = "joined"
+
+	// ErrorStyleErrors returns errors.Join of one error per message, so
+	// callers can iterate the individual errors.
+	ErrorStyleErrors = "errors"
+)
+
 // Config returns the tool configuration for VSCode extension integration.
 func Config() genkit.ToolConfig {
 	return genkit.ToolConfig{
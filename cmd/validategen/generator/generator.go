@@ -13,13 +13,15 @@ import (
 
 // Generator generates Validate() methods for structs.
 type Generator struct {
-	pkgIndex map[genkit.GoImportPath]*genkit.Package
+	pkgIndex   map[genkit.GoImportPath]*genkit.Package
+	errorStyle string
 }
 
 // New creates a new Generator.
 func New() *Generator {
 	return &Generator{
-		pkgIndex: make(map[genkit.GoImportPath]*genkit.Package),
+		pkgIndex:   make(map[genkit.GoImportPath]*genkit.Package),
+		errorStyle: ErrorStyleJoined,
 	}
 }
 
@@ -33,6 +35,27 @@ func (vg *Generator) Config() genkit.ToolConfig {
 	return Config()
 }
 
+// OptionsSchema implements genkit.OptionsTool.
+func (vg *Generator) OptionsSchema() []genkit.OptionSpec {
+	return []genkit.OptionSpec{
+		{
+			Name:    "error_style",
+			Type:    genkit.OptionEnum,
+			Default: ErrorStyleJoined,
+			Values:  []string{ErrorStyleJoined, ErrorStyleErrors},
+			Doc:     "Validate() 返回的错误形式：joined 为单个以 \"; \" 拼接的错误，errors 为 errors.Join 组合的多个错误；定义了 postValidate 的类型由 postValidate 构造错误，不受此选项影响",
+		},
+	}
+}
+
+// SetOptions implements genkit.OptionsTool.
+func (vg *Generator) SetOptions(opts map[string]any) error {
+	if style, ok := opts["error_style"].(string); ok {
+		vg.errorStyle = style
+	}
+	return nil
+}
+
 // Rules implements genkit.RuleTool.
 func (vg *Generator) Rules() []genkit.Rule {
 	return []genkit.Rule{
@@ -344,7 +367,15 @@ func (vg *Generator) GenerateValidate(
 		g.P("return x.postValidate(errs)")
 	} else {
 		g.P("if len(errs) > 0 {")
-		g.P("return ", genkit.GoImportPath("fmt").Ident("Errorf"), "(\"%s\", ", genkit.GoImportPath("strings").Ident("Join"), "(errs, \"; \"))")
+		if vg.errorStyle == ErrorStyleErrors {
+			g.P("errList := make([]error, 0, len(errs))")
+			g.P("for _, msg := range errs {")
+			g.P("errList = append(errList, ", genkit.GoImportPath("errors").Ident("New"), "(msg))")
+			g.P("}")
+			g.P("return ", genkit.GoImportPath("errors").Ident("Join"), "(errList...)")
+		} else {
+			g.P("return ", genkit.GoImportPath("fmt").Ident("Errorf"), "(\"%s\", ", genkit.GoImportPath("strings").Ident("Join"), "(errs, \"; \"))")
+		}
 		g.P("}")
 		g.P("return nil")
 	}
@@ -524,6 +555,11 @@ func (vg *Generator) Validate(gen *genkit.Generator, _ *genkit.Logger) []genkit.
 				continue
 			}
 			vg.validateType(c, typ, pkg)
+			if vg.errorStyle == ErrorStyleErrors && vg.hasPostValidateMethod(typ) {
+				c.Warningf(WarnCodeErrorStyleIgnored, pkg.Fset.Position(typ.TypeSpec.Pos()),
+					"error_style %q is ignored for %s, its postValidate method builds the returned error",
+					vg.errorStyle, typ.Name)
+			}
 		}
 	}
 
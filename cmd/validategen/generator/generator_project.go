@@ -0,0 +1,66 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tlipoca9/devgen/genkit"
+)
+
+// ProjectRule implements genkit.ProjectRuleTool.
+// It lists the validated structs of the loaded packages with the rules of
+// their fields.
+func (vg *Generator) ProjectRule(gen *genkit.Generator) genkit.ProjectRule {
+	var pr genkit.ProjectRule
+	var sb strings.Builder
+	for _, pkg := range gen.Packages {
+		typs := vg.FindTypes(pkg)
+		if len(typs) == 0 {
+			continue
+		}
+		pr.Packages = append(pr.Packages, pkg)
+		for _, typ := range typs {
+			vg.describeType(&sb, typ)
+		}
+	}
+	if sb.Len() == 0 {
+		return genkit.ProjectRule{}
+	}
+
+	pr.Content = "## Validated Structs (validategen)\n\n" +
+		"The generated `Validate()` method of these structs checks the constraints below. " +
+		"Keep new values within them, and add validation annotations to new fields instead of hand-written checks.\n" +
+		sb.String()
+	return pr
+}
+
+// describeType writes the section of a validated struct in the project rule.
+func (vg *Generator) describeType(sb *strings.Builder, typ *genkit.Type) {
+	fmt.Fprintf(sb, "\n### `%s` (package `%s`)\n\n", typ.Name, typ.Pkg.PkgPath)
+
+	var rows []string
+	for _, field := range typ.Fields {
+		vrules := vg.parseFieldAnnotations(field)
+		if len(vrules) == 0 {
+			continue
+		}
+		constraints := make([]string, 0, len(vrules))
+		for _, vrule := range vrules {
+			if vrule.Param != "" {
+				constraints = append(constraints, "`@"+vrule.Name+"("+vrule.Param+")`")
+			} else {
+				constraints = append(constraints, "`@"+vrule.Name+"`")
+			}
+		}
+		rows = append(rows, fmt.Sprintf("| `%s` | `%s` | %s |", field.Name, field.Type, strings.Join(constraints, " ")))
+	}
+	if len(rows) == 0 {
+		sb.WriteString("No field constraints.\n")
+	} else {
+		sb.WriteString("| Field | Type | Constraints |\n|-------|------|-------------|\n")
+		sb.WriteString(strings.Join(rows, "\n") + "\n")
+	}
+	if vg.hasPostValidateMethod(typ) {
+		sb.WriteString("\n`Validate()` also calls the hand-written `postValidate` method.\n")
+	}
+}
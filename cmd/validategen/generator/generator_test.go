@@ -1808,6 +1808,79 @@ type NoPostVal struct {
 			})
 		})
 
+		Describe("Error style option", func() {
+			It("should return errors.Join when error_style is errors", func() {
+				testFile := filepath.Join(tempDir, "errstyle.go")
+				content := `package testpkg
+
+// ErrStyle uses joined errors.
+// validategen:@validate
+type ErrStyle struct {
+	// validategen:@required
+	Name string
+}
+`
+				err := os.WriteFile(testFile, []byte(content), 0644)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = gen.SetOptions(map[string]any{"error_style": generator.ErrorStyleErrors})
+				Expect(err).NotTo(HaveOccurred())
+
+				err = gk.Load(".")
+				Expect(err).NotTo(HaveOccurred())
+
+				err = gen.ProcessPackage(gk, gk.Packages[0])
+				Expect(err).NotTo(HaveOccurred())
+
+				files, err := gk.DryRun()
+				Expect(err).NotTo(HaveOccurred())
+
+				for _, content := range files {
+					code := string(content)
+					Expect(code).To(ContainSubstring("errors.New(msg)"))
+					Expect(code).To(ContainSubstring("return errors.Join(errList...)"))
+					Expect(code).NotTo(ContainSubstring("strings.Join(errs"))
+				}
+			})
+
+			It("should warn that error_style is ignored with postValidate", func() {
+				testFile := filepath.Join(tempDir, "errstylepost.go")
+				content := `package testpkg
+
+// ErrStylePost builds its own error.
+// validategen:@validate
+type ErrStylePost struct {
+	// validategen:@required
+	Name string
+}
+
+func (x ErrStylePost) postValidate(errs []string) error {
+	return nil
+}
+`
+				err := os.WriteFile(testFile, []byte(content), 0644)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = gen.SetOptions(map[string]any{"error_style": generator.ErrorStyleErrors})
+				Expect(err).NotTo(HaveOccurred())
+
+				err = gk.Load(".")
+				Expect(err).NotTo(HaveOccurred())
+
+				diagnostics := gen.Validate(gk, genkit.NewLoggerWithWriter(io.Discard))
+				Expect(diagnostics).To(HaveLen(1))
+				Expect(diagnostics[0].Severity).To(Equal(genkit.DiagnosticWarning))
+				Expect(diagnostics[0].Code).To(Equal(generator.WarnCodeErrorStyleIgnored))
+				Expect(diagnostics[0].Message).To(ContainSubstring("ErrStylePost"))
+			})
+
+			It("should reject unknown error styles", func() {
+				_, err := genkit.ValidateOptions(gen.OptionsSchema(), map[string]any{"error_style": "list"})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("expected one of: joined, errors"))
+			})
+		})
+
 		Describe("Multiple validations on same field", func() {
 			It("should generate all validations for a field", func() {
 				testFile := filepath.Join(tempDir, "multi.go")
@@ -24,6 +24,8 @@ const (
 	ErrCodeMethodNotFound      = "E010"
 	ErrCodeInvalidRegex        = "E011"
 	ErrCodeInvalidOneofValue   = "E012"
+
+	WarnCodeErrorStyleIgnored = "W001" // error_style has no effect because postValidate builds the error
 )
 
 // fmtSprintf returns the fmt.Sprintf identifier for code generation.
@@ -53,6 +53,9 @@ func (p *GoPluginGen) Run(gen *genkit.Generator, log *genkit.Logger) error {
 	return nil
 }
 
+// GenkitVersion declares the genkit API versions this plugin supports.
+var GenkitVersion = ">= v0.4"
+
 var Tool genkit.Tool = &GoPluginGen{}
 
 func main() {}
@@ -1,5 +1,12 @@
 package genkit
 
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
 // AgentAdapter transforms rules for a specific AI assistant.
 // Different AI assistants (Kiro, CodeBuddy, Cursor, etc.) require different
 // frontmatter formats and directory structures. Adapters handle these differences
@@ -39,3 +46,94 @@ type AgentAdapter interface {
 	//   3. Combining frontmatter with the rule content
 	Transform(rule Rule) (filename string, content string, err error)
 }
+
+// AggregatingAdapter is implemented by adapters of agents that read several
+// rules from a single file, such as AGENTS.md, instead of one file per rule.
+// Transform renders a single rule the same way, so the adapter can still be
+// used like any other AgentAdapter.
+type AggregatingAdapter interface {
+	AgentAdapter
+
+	// Aggregate renders all rules into the files of the agent.
+	Aggregate(rules []Rule) ([]RuleFile, error)
+}
+
+// RuleFile is a rule file generated for an agent.
+type RuleFile struct {
+	// Path is the slash-separated file path relative to the project root.
+	Path string
+
+	// Content is the file content. The output of every rule is wrapped in a
+	// managed section, see MergeRuleFile.
+	Content string
+}
+
+// RenderRules renders rules into the files of an agent: one file per rule
+// in the adapter's OutputDir, or the files of an AggregatingAdapter.
+// The rules are wrapped in managed sections named after them. Rules not
+// meant for the agent, see Rule.ForAgent, are skipped.
+func RenderRules(adapter AgentAdapter, rules []Rule) ([]RuleFile, error) {
+	rules = slices.DeleteFunc(slices.Clone(rules), func(r Rule) bool { return !r.ForAgent(adapter.Name()) })
+	if aa, ok := adapter.(AggregatingAdapter); ok {
+		return aa.Aggregate(rules)
+	}
+
+	files := make([]RuleFile, 0, len(rules))
+	for _, rule := range rules {
+		filename, content, err := adapter.Transform(rule)
+		if err != nil {
+			return nil, fmt.Errorf("transform rule %s: %w", rule.Name, err)
+		}
+		files = append(files, RuleFile{
+			Path:    path.Join(adapter.OutputDir(), filename),
+			Content: managedRuleContent(rule.Name, content),
+		})
+	}
+	return files, nil
+}
+
+// aggregateRules combines rules into a single Markdown document. Each rule
+// becomes a managed level-2 section: the headings of its content are demoted
+// by one level, and rules limited to some files say so below their heading.
+func aggregateRules(title string, rules []Rule) string {
+	var sb strings.Builder
+	sb.WriteString("# " + title + "\n")
+
+	for _, rule := range rules {
+		var section strings.Builder
+		content := demoteHeadings(strings.TrimSpace(rule.Content))
+		heading, body := "## "+rule.Name, content
+		if strings.HasPrefix(content, "## ") {
+			heading, body, _ = strings.Cut(content, "\n")
+		}
+		section.WriteString(heading + "\n\n")
+		if !rule.AlwaysApply && len(rule.Globs) > 0 {
+			fmt.Fprintf(&section, "_Applies to files matching %s._\n\n", "`"+strings.Join(rule.Globs, "`, `")+"`")
+		}
+		section.WriteString(strings.TrimSpace(body))
+		sb.WriteString("\n" + ManagedSection(rule.Name, section.String()))
+	}
+	return sb.String()
+}
+
+// demoteHeadings moves every Markdown heading outside code blocks one level
+// down, up to level 6.
+func demoteHeadings(content string) string {
+	lines := strings.Split(content, "\n")
+	inFence := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence || !strings.HasPrefix(line, "#") {
+			continue
+		}
+		level := len(line) - len(strings.TrimLeft(line, "#"))
+		if level < 6 && (len(line) == level || line[level] == ' ') {
+			lines[i] = "#" + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
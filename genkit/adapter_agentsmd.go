@@ -0,0 +1,28 @@
+package genkit
+
+// AgentsMDAdapter writes all rules into a single AGENTS.md file in the
+// project root, read by the agents that follow the AGENTS.md convention.
+//
+// Each rule becomes a section; rules limited to some files name their globs
+// below the section heading, since AGENTS.md has no frontmatter.
+type AgentsMDAdapter struct{}
+
+// Name returns "agents".
+func (a *AgentsMDAdapter) Name() string {
+	return "agents"
+}
+
+// OutputDir returns ".", the project root.
+func (a *AgentsMDAdapter) OutputDir() string {
+	return "."
+}
+
+// Transform renders a single rule as AGENTS.md.
+func (a *AgentsMDAdapter) Transform(rule Rule) (string, string, error) {
+	return "AGENTS.md", aggregateRules("AGENTS.md", []Rule{rule}), nil
+}
+
+// Aggregate renders all rules into AGENTS.md.
+func (a *AgentsMDAdapter) Aggregate(rules []Rule) ([]RuleFile, error) {
+	return []RuleFile{{Path: "AGENTS.md", Content: aggregateRules("AGENTS.md", rules)}}, nil
+}
@@ -0,0 +1,25 @@
+package genkit
+
+// ClaudeAdapter writes all rules into a single CLAUDE.md file in the
+// project root. The layout is the same as AGENTS.md.
+type ClaudeAdapter struct{}
+
+// Name returns "claude".
+func (c *ClaudeAdapter) Name() string {
+	return "claude"
+}
+
+// OutputDir returns ".", the project root.
+func (c *ClaudeAdapter) OutputDir() string {
+	return "."
+}
+
+// Transform renders a single rule as CLAUDE.md.
+func (c *ClaudeAdapter) Transform(rule Rule) (string, string, error) {
+	return "CLAUDE.md", aggregateRules("CLAUDE.md", []Rule{rule}), nil
+}
+
+// Aggregate renders all rules into CLAUDE.md.
+func (c *ClaudeAdapter) Aggregate(rules []Rule) ([]RuleFile, error) {
+	return []RuleFile{{Path: "CLAUDE.md", Content: aggregateRules("CLAUDE.md", rules)}}, nil
+}
@@ -0,0 +1,50 @@
+package genkit
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ClineAdapter transforms rules for Cline.
+// Cline reads every Markdown file in .clinerules. Rules limited to some
+// files declare them in a 'paths' frontmatter list; rules without
+// frontmatter are always active.
+//
+// Frontmatter format:
+//
+//	---
+//	paths:
+//	  - "**/*.go"
+//	  - "**/devgen.toml"
+//	---
+type ClineAdapter struct{}
+
+// Name returns "cline".
+func (c *ClineAdapter) Name() string {
+	return "cline"
+}
+
+// OutputDir returns ".clinerules".
+func (c *ClineAdapter) OutputDir() string {
+	return ".clinerules"
+}
+
+// Transform converts a Rule to Cline format. Only rules that do not always
+// apply and have globs get frontmatter; the description becomes a comment
+// since Cline has no description field.
+func (c *ClineAdapter) Transform(rule Rule) (string, string, error) {
+	var sb strings.Builder
+	if !rule.AlwaysApply && len(rule.Globs) > 0 {
+		sb.WriteString("---\npaths:\n")
+		for _, glob := range rule.Globs {
+			fmt.Fprintf(&sb, "  - %q\n", glob)
+		}
+		sb.WriteString("---\n\n")
+	}
+	if rule.Description != "" {
+		fmt.Fprintf(&sb, "<!-- %s -->\n\n", rule.Description)
+	}
+	sb.WriteString(rule.Content)
+
+	return rule.Name + ".md", sb.String(), nil
+}
@@ -0,0 +1,143 @@
+package genkit
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// Glob formats of AdapterConfig.GlobsFormat.
+const (
+	// GlobsFormatComma joins globs with ", ", e.g. **/*.go, **/devgen.toml
+	GlobsFormatComma = "comma"
+
+	// GlobsFormatYAML formats globs as a YAML flow sequence, e.g.
+	// ['**/*.go', '**/devgen.toml']
+	GlobsFormatYAML = "yaml"
+)
+
+// AdapterConfig defines an agent adapter in devgen.toml, for assistants
+// without a built-in adapter:
+//
+//	[[rules.adapters]]
+//	name = "zed"
+//	output_dir = ".zed/rules"
+//	extension = ".md"
+//	globs_format = "comma"
+//	frontmatter = """
+//	---
+//	description: {{ .Description }}
+//	{{- if .Globs }}
+//	globs: {{ .Globs }}
+//	{{- end }}
+//	alwaysApply: {{ .AlwaysApply }}
+//	---
+//	"""
+type AdapterConfig struct {
+	// Name is the agent name used with --agent.
+	Name string `toml:"name"`
+
+	// OutputDir is the directory of the rule files, relative to the project
+	// root. It must not be absolute or leave the project with "..".
+	OutputDir string `toml:"output_dir"`
+
+	// Extension is the extension of the rule files. Default: ".md"
+	Extension string `toml:"extension"`
+
+	// Frontmatter is a Go template written before the rule content. It is
+	// executed with the rule's Name, Description, Globs (formatted as
+	// GlobsFormat, empty if the rule has none) and AlwaysApply. Empty means
+	// no frontmatter.
+	Frontmatter string `toml:"frontmatter"`
+
+	// GlobsFormat is GlobsFormatComma or GlobsFormatYAML. Default: "comma"
+	GlobsFormat string `toml:"globs_format"`
+}
+
+// ConfigAdapter is an AgentAdapter defined by an AdapterConfig.
+type ConfigAdapter struct {
+	cfg         AdapterConfig
+	frontmatter *template.Template
+}
+
+// configAdapterData is the data of the frontmatter template of a
+// ConfigAdapter.
+type configAdapterData struct {
+	Name        string
+	Description string
+	Globs       string
+	AlwaysApply bool
+}
+
+// NewConfigAdapter returns the adapter defined by cfg.
+func NewConfigAdapter(cfg AdapterConfig) (*ConfigAdapter, error) {
+	if cfg.Name == "" {
+		return nil, fmt.Errorf("adapter name is required")
+	}
+	if cfg.Name == "all" {
+		return nil, fmt.Errorf("adapter name %q is reserved", cfg.Name)
+	}
+	if cfg.OutputDir == "" {
+		return nil, fmt.Errorf("adapter %s: output_dir is required", cfg.Name)
+	}
+	if !filepath.IsLocal(cfg.OutputDir) {
+		return nil, fmt.Errorf("adapter %s: output_dir %q must be a relative path inside the project", cfg.Name, cfg.OutputDir)
+	}
+	if cfg.Extension == "" {
+		cfg.Extension = ".md"
+	} else if !strings.HasPrefix(cfg.Extension, ".") {
+		cfg.Extension = "." + cfg.Extension
+	}
+	switch cfg.GlobsFormat {
+	case "":
+		cfg.GlobsFormat = GlobsFormatComma
+	case GlobsFormatComma, GlobsFormatYAML:
+	default:
+		return nil, fmt.Errorf("adapter %s: unknown globs_format %q, want %q or %q",
+			cfg.Name, cfg.GlobsFormat, GlobsFormatComma, GlobsFormatYAML)
+	}
+
+	tmpl, err := template.New(cfg.Name).Funcs(ruleTemplateFuncs).Option("missingkey=error").Parse(cfg.Frontmatter)
+	if err != nil {
+		return nil, fmt.Errorf("adapter %s: parse frontmatter: %w", cfg.Name, err)
+	}
+	return &ConfigAdapter{cfg: cfg, frontmatter: tmpl}, nil
+}
+
+// Name returns the configured name.
+func (c *ConfigAdapter) Name() string {
+	return c.cfg.Name
+}
+
+// OutputDir returns the configured output directory.
+func (c *ConfigAdapter) OutputDir() string {
+	return c.cfg.OutputDir
+}
+
+// Transform writes the rule content after the executed frontmatter template,
+// separated by a blank line.
+func (c *ConfigAdapter) Transform(rule Rule) (string, string, error) {
+	data := configAdapterData{
+		Name:        rule.Name,
+		Description: rule.Description,
+		AlwaysApply: rule.AlwaysApply,
+	}
+	if len(rule.Globs) > 0 {
+		if c.cfg.GlobsFormat == GlobsFormatYAML {
+			data.Globs = formatPatternsYAML(rule.Globs)
+		} else {
+			data.Globs = formatGlobsComma(rule.Globs)
+		}
+	}
+
+	var sb strings.Builder
+	if err := c.frontmatter.Execute(&sb, data); err != nil {
+		return "", "", fmt.Errorf("adapter %s: execute frontmatter: %w", c.cfg.Name, err)
+	}
+	content := rule.Content
+	if frontmatter := strings.TrimSpace(sb.String()); frontmatter != "" {
+		content = frontmatter + "\n\n" + content
+	}
+	return rule.Name + c.cfg.Extension, content, nil
+}
@@ -0,0 +1,91 @@
+package genkit
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// CopilotAdapter transforms rules for GitHub Copilot.
+// Rules that always apply are combined into the repository-wide
+// .github/copilot-instructions.md; every other rule becomes a path-specific
+// .github/instructions/<name>.instructions.md file with an 'applyTo' glob.
+//
+// Frontmatter format of instructions files:
+//
+//	---
+//	description: Brief description of the rule
+//	applyTo: "**/*.go,**/devgen.toml"
+//	---
+//
+// Instructions files without 'applyTo' are only used when attached manually.
+type CopilotAdapter struct{}
+
+// copilotInstructionsFile is the repository-wide instructions file.
+const copilotInstructionsFile = ".github/copilot-instructions.md"
+
+// Name returns "copilot".
+func (c *CopilotAdapter) Name() string {
+	return "copilot"
+}
+
+// OutputDir returns ".github/instructions".
+func (c *CopilotAdapter) OutputDir() string {
+	return ".github/instructions"
+}
+
+// Transform converts a Rule to a Copilot instructions file. A rule that
+// always applies gets applyTo "**".
+func (c *CopilotAdapter) Transform(rule Rule) (string, string, error) {
+	globs := rule.Globs
+	if rule.AlwaysApply {
+		globs = []string{"**"}
+	}
+
+	var frontmatter string
+	if len(globs) > 0 {
+		frontmatter = fmt.Sprintf(`---
+description: %s
+applyTo: "%s"
+---
+
+`, rule.Description, strings.Join(globs, ","))
+	} else {
+		frontmatter = fmt.Sprintf(`---
+description: %s
+---
+
+`, rule.Description)
+	}
+
+	return rule.Name + ".instructions.md", frontmatter + rule.Content, nil
+}
+
+// Aggregate writes the rules that always apply to
+// .github/copilot-instructions.md and the others to instructions files.
+// The repository-wide file is shared with hand-written instructions.
+func (c *CopilotAdapter) Aggregate(rules []Rule) ([]RuleFile, error) {
+	var files []RuleFile
+	var always []Rule
+	for _, rule := range rules {
+		if rule.AlwaysApply {
+			always = append(always, rule)
+			continue
+		}
+		filename, content, err := c.Transform(rule)
+		if err != nil {
+			return nil, fmt.Errorf("transform rule %s: %w", rule.Name, err)
+		}
+		files = append(files, RuleFile{
+			Path:    path.Join(c.OutputDir(), filename),
+			Content: managedRuleContent(rule.Name, content),
+		})
+	}
+	// The instructions file is rendered even without rules, so that sections
+	// of rules which no longer always apply are removed from it.
+	files = append(files, RuleFile{
+		Path:    copilotInstructionsFile,
+		Content: aggregateRules("Copilot Instructions", always),
+	})
+	return files, nil
+}
@@ -1,6 +1,9 @@
 package genkit
 
-import "sort"
+import (
+	"fmt"
+	"sort"
+)
 
 // AdapterRegistry manages available agent adapters.
 // It provides a central registry for all supported AI agents,
@@ -10,7 +13,8 @@ type AdapterRegistry struct {
 }
 
 // NewAdapterRegistry creates a new registry with built-in adapters.
-// The registry is pre-populated with adapters for Kiro, CodeBuddy, and Cursor.
+// The registry is pre-populated with adapters for Kiro, CodeBuddy, Cursor,
+// GitHub Copilot, Windsurf, Cline, AGENTS.md and CLAUDE.md.
 func NewAdapterRegistry() *AdapterRegistry {
 	registry := &AdapterRegistry{
 		adapters: make(map[string]AgentAdapter),
@@ -20,6 +24,11 @@ func NewAdapterRegistry() *AdapterRegistry {
 	registry.Register(&KiroAdapter{})
 	registry.Register(&CodeBuddyAdapter{})
 	registry.Register(&CursorAdapter{})
+	registry.Register(&CopilotAdapter{})
+	registry.Register(&WindsurfAdapter{})
+	registry.Register(&ClineAdapter{})
+	registry.Register(&AgentsMDAdapter{})
+	registry.Register(&ClaudeAdapter{})
 
 	return registry
 }
@@ -48,7 +57,7 @@ func (r *AdapterRegistry) Get(name string) (AgentAdapter, bool) {
 // List returns all registered adapter names in alphabetical order.
 // This is useful for displaying available agents to users.
 //
-// Example output: ["codebuddy", "cursor", "kiro"]
+// Example output: ["agents", "claude", "cline", "codebuddy", "copilot", ...]
 func (r *AdapterRegistry) List() []string {
 	names := make([]string, 0, len(r.adapters))
 	for name := range r.adapters {
@@ -57,3 +66,21 @@ func (r *AdapterRegistry) List() []string {
 	sort.Strings(names)
 	return names
 }
+
+// LoadAdapters registers the adapters defined in devgen.toml, see
+// AdapterConfig. Like Register, they replace adapters of the same name.
+func (r *AdapterRegistry) LoadAdapters(configs []AdapterConfig) error {
+	seen := make(map[string]bool)
+	for _, cfg := range configs {
+		adapter, err := NewConfigAdapter(cfg)
+		if err != nil {
+			return err
+		}
+		if seen[cfg.Name] {
+			return fmt.Errorf("adapter %s is defined more than once", cfg.Name)
+		}
+		seen[cfg.Name] = true
+		r.Register(adapter)
+	}
+	return nil
+}
@@ -215,13 +215,170 @@ func TestCursorAdapter(t *testing.T) {
 }
 
 // TestAdapterRegistry tests the adapter registry
+// TestCopilotAdapter tests the Copilot adapter
+func TestCopilotAdapter(t *testing.T) {
+	adapter := &CopilotAdapter{}
+
+	t.Run("Transform", func(t *testing.T) {
+		filename, content, err := adapter.Transform(testRule)
+		if err != nil {
+			t.Fatalf("Transform() error = %v", err)
+		}
+		if filename != "test-rule.instructions.md" {
+			t.Errorf("filename = %q", filename)
+		}
+		if !strings.Contains(content, `applyTo: "**/*.go,**/test.toml"`) {
+			t.Errorf("content missing applyTo:\n%s", content)
+		}
+
+		_, content, _ = adapter.Transform(testRuleNoGlobs)
+		if strings.Contains(content, "applyTo") {
+			t.Errorf("rule without globs has applyTo:\n%s", content)
+		}
+	})
+
+	t.Run("Aggregate", func(t *testing.T) {
+		files, err := adapter.Aggregate([]Rule{testRule, testRuleAlways})
+		if err != nil {
+			t.Fatalf("Aggregate() error = %v", err)
+		}
+		if len(files) != 2 {
+			t.Fatalf("Aggregate() = %+v", files)
+		}
+		if files[0].Path != ".github/instructions/test-rule.instructions.md" {
+			t.Errorf("files[0].Path = %q", files[0].Path)
+		}
+		if files[1].Path != ".github/copilot-instructions.md" || !strings.Contains(files[1].Content, "## Always Rule") {
+			t.Errorf("files[1] = %+v", files[1])
+		}
+	})
+}
+
+// TestAgentsMDAdapter tests aggregating rules into AGENTS.md
+func TestAgentsMDAdapter(t *testing.T) {
+	files, err := RenderRules(&AgentsMDAdapter{}, []Rule{testRule, testRuleAlways, {Name: "plain", Content: "Just text.\n\n# Heading\n\n```\n# not a heading\n```"}})
+	if err != nil {
+		t.Fatalf("RenderRules() error = %v", err)
+	}
+	if len(files) != 1 || files[0].Path != "AGENTS.md" {
+		t.Fatalf("RenderRules() = %+v", files)
+	}
+
+	content := files[0].Content
+	for _, want := range []string{
+		"# AGENTS.md\n",
+		"## Test Rule\n\n_Applies to files matching `**/*.go`, `**/test.toml`._\n\nThis is test content.\n",
+		"## Always Rule\n\nThis rule always applies.\n",
+		"## plain\n\nJust text.\n\n## Heading\n\n```\n# not a heading\n```\n",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("AGENTS.md missing %q:\n%s", want, content)
+		}
+	}
+	if strings.Count(content, "_Applies to") != 1 {
+		t.Errorf("only rules limited by globs should name them:\n%s", content)
+	}
+}
+
+// TestWindsurfAdapter tests the Windsurf adapter triggers
+func TestWindsurfAdapter(t *testing.T) {
+	adapter := &WindsurfAdapter{}
+	for _, tt := range []struct {
+		rule Rule
+		want string
+	}{
+		{testRule, "trigger: glob\ndescription: Test rule for unit testing\nglobs: **/*.go, **/test.toml\n"},
+		{testRuleAlways, "trigger: always_on\n"},
+		{testRuleNoGlobs, "trigger: model_decision\n"},
+	} {
+		filename, content, err := adapter.Transform(tt.rule)
+		if err != nil {
+			t.Fatalf("Transform() error = %v", err)
+		}
+		if filename != tt.rule.Name+".md" || !strings.Contains(content, tt.want) {
+			t.Errorf("Transform(%s) = %q:\n%s", tt.rule.Name, filename, content)
+		}
+	}
+}
+
+// TestClineAdapter tests the Cline adapter
+func TestClineAdapter(t *testing.T) {
+	adapter := &ClineAdapter{}
+
+	_, content, err := adapter.Transform(testRule)
+	if err != nil {
+		t.Fatalf("Transform() error = %v", err)
+	}
+	if !strings.HasPrefix(content, "---\npaths:\n  - \"**/*.go\"\n  - \"**/test.toml\"\n---\n") {
+		t.Errorf("content = %s", content)
+	}
+
+	_, content, _ = adapter.Transform(testRuleAlways)
+	if strings.HasPrefix(content, "---") {
+		t.Errorf("always-apply rule should have no frontmatter:\n%s", content)
+	}
+}
+
+func TestConfigAdapter(t *testing.T) {
+	adapter, err := NewConfigAdapter(AdapterConfig{
+		Name:        "zed",
+		OutputDir:   ".zed/rules",
+		Extension:   "mdx",
+		GlobsFormat: GlobsFormatYAML,
+		Frontmatter: "---\ndescription: {{ .Description }}\n{{- if .Globs }}\nglobs: {{ .Globs }}\n{{- end }}\nalways: {{ .AlwaysApply }}\n---\n",
+	})
+	if err != nil {
+		t.Fatalf("NewConfigAdapter() error = %v", err)
+	}
+	filename, content, err := adapter.Transform(testRule)
+	if err != nil {
+		t.Fatalf("Transform() error = %v", err)
+	}
+	want := "---\ndescription: Test rule for unit testing\nglobs: ['**/*.go', '**/test.toml']\nalways: false\n---\n\n" + testRule.Content
+	if filename != "test-rule.mdx" || content != want {
+		t.Errorf("Transform() = %q:\n%s\nwant\n%s", filename, content, want)
+	}
+	if _, content, _ = adapter.Transform(testRuleNoGlobs); strings.Contains(content, "globs:") {
+		t.Errorf("rule without globs:\n%s", content)
+	}
+
+	for _, cfg := range []AdapterConfig{
+		{OutputDir: ".x"},
+		{Name: "x"},
+		{Name: "all", OutputDir: ".x"},
+		{Name: "x", OutputDir: ".x", GlobsFormat: "json"},
+		{Name: "x", OutputDir: ".x", Frontmatter: "{{ .Description"},
+		{Name: "x", OutputDir: "/etc/rules"},
+		{Name: "x", OutputDir: "../rules"},
+		{Name: "x", OutputDir: ".x/../../rules"},
+	} {
+		if _, err := NewConfigAdapter(cfg); err == nil {
+			t.Errorf("NewConfigAdapter(%+v) should fail", cfg)
+		}
+	}
+}
+
 func TestAdapterRegistry(t *testing.T) {
+	t.Run("LoadAdapters", func(t *testing.T) {
+		registry := NewAdapterRegistry()
+		if err := registry.LoadAdapters([]AdapterConfig{{Name: "zed", OutputDir: ".zed/rules"}}); err != nil {
+			t.Fatalf("LoadAdapters() error = %v", err)
+		}
+		if adapter, ok := registry.Get("zed"); !ok || adapter.OutputDir() != ".zed/rules" {
+			t.Errorf("Get(zed) = %v, %v", adapter, ok)
+		}
+		dup := []AdapterConfig{{Name: "zed", OutputDir: ".a"}, {Name: "zed", OutputDir: ".b"}}
+		if err := registry.LoadAdapters(dup); err == nil {
+			t.Error("LoadAdapters() with duplicate names should fail")
+		}
+	})
+
 	t.Run("NewAdapterRegistry", func(t *testing.T) {
 		registry := NewAdapterRegistry()
 
 		// Check built-in adapters are registered
 		names := registry.List()
-		expected := []string{"codebuddy", "cursor", "kiro"}
+		expected := []string{"agents", "claude", "cline", "codebuddy", "copilot", "cursor", "kiro", "windsurf"}
 
 		if len(names) != len(expected) {
 			t.Errorf("List() returned %d adapters, want %d", len(names), len(expected))
@@ -376,3 +533,25 @@ func TestFormatGlobsComma(t *testing.T) {
 		})
 	}
 }
+
+// TestRenderRules_Agents tests skipping rules not meant for an agent
+func TestRenderRules_Agents(t *testing.T) {
+	onlyCursor := Rule{Name: "only-cursor", Agents: []string{"cursor"}, Content: "Cursor only."}
+	notCursor := Rule{Name: "not-cursor", ExcludeAgents: []string{"cursor"}, Content: "Everyone else."}
+
+	files, err := RenderRules(&CursorAdapter{}, []Rule{onlyCursor, notCursor, testRule})
+	if err != nil {
+		t.Fatalf("RenderRules() error = %v", err)
+	}
+	if len(files) != 2 || files[0].Path != ".cursor/rules/only-cursor.mdc" || files[1].Path != ".cursor/rules/test-rule.mdc" {
+		t.Errorf("RenderRules(cursor) = %+v", files)
+	}
+
+	files, err = RenderRules(&AgentsMDAdapter{}, []Rule{onlyCursor, notCursor})
+	if err != nil {
+		t.Fatalf("RenderRules() error = %v", err)
+	}
+	if content := files[0].Content; strings.Contains(content, "Cursor only.") || !strings.Contains(content, "Everyone else.") {
+		t.Errorf("RenderRules(agents) =\n%s", content)
+	}
+}
@@ -0,0 +1,67 @@
+package genkit
+
+import "fmt"
+
+// WindsurfAdapter transforms rules for Windsurf.
+// Windsurf uses YAML frontmatter with a 'trigger' that selects when a rule
+// is activated.
+//
+// Frontmatter format:
+//
+//	---
+//	trigger: glob
+//	description: Brief description of the rule
+//	globs: **/*.go, **/devgen.toml
+//	---
+//
+// Trigger types:
+//   - "always_on": Rule is always included
+//   - "glob": Rule is included for files matching globs
+//   - "model_decision": The model decides based on the description
+type WindsurfAdapter struct{}
+
+// Name returns "windsurf".
+func (w *WindsurfAdapter) Name() string {
+	return "windsurf"
+}
+
+// OutputDir returns ".windsurf/rules".
+func (w *WindsurfAdapter) OutputDir() string {
+	return ".windsurf/rules"
+}
+
+// Budget returns the 6000 characters Windsurf reads of a rule file.
+func (w *WindsurfAdapter) Budget() RuleBudget {
+	return RuleBudget{Chars: 6000}
+}
+
+// Transform converts a Rule to Windsurf format with YAML frontmatter.
+func (w *WindsurfAdapter) Transform(rule Rule) (string, string, error) {
+	var frontmatter string
+	switch {
+	case rule.AlwaysApply:
+		frontmatter = fmt.Sprintf(`---
+trigger: always_on
+description: %s
+---
+
+`, rule.Description)
+	case len(rule.Globs) > 0:
+		frontmatter = fmt.Sprintf(`---
+trigger: glob
+description: %s
+globs: %s
+---
+
+`, rule.Description, formatGlobsComma(rule.Globs))
+	default:
+		frontmatter = fmt.Sprintf(`---
+trigger: model_decision
+description: %s
+---
+
+`, rule.Description)
+	}
+
+	return rule.Name + ".md", frontmatter + rule.Content, nil
+}
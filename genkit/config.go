@@ -19,6 +19,9 @@ type Config struct {
 
 	// Rules contains AI rules configuration.
 	Rules RulesConfig `toml:"rules"`
+
+	// Diagnostics contains severity overrides and suppression settings.
+	Diagnostics DiagnosticsConfig `toml:"diagnostics"`
 }
 
 // RulesConfig defines AI rules generation configuration.
@@ -30,6 +33,18 @@ type RulesConfig struct {
 	// IncludeBuiltin indicates whether to include devgen's built-in rules.
 	// Default: true
 	IncludeBuiltin *bool `toml:"include_builtin"`
+
+	// Project indicates whether to generate the project-specific rule from
+	// the annotated declarations of the packages in ./..., see
+	// BuildProjectRule. Default: false
+	Project bool `toml:"project"`
+
+	// Agents configures the rules of single agents by adapter name, e.g.
+	// [rules.agents.windsurf].
+	Agents map[string]AgentRulesConfig `toml:"agents"`
+
+	// Adapters defines adapters of further agents, see AdapterConfig.
+	Adapters []AdapterConfig `toml:"adapters"`
 }
 
 // HasSourceDir returns true if a source directory is explicitly configured.
@@ -58,12 +73,59 @@ type PluginConfig struct {
 	// Path is the path to the plugin.
 	// For "source" type: Go package directory path
 	// For "plugin" type: path to .so file
+	// For "exec" type: path to the plugin executable
+	// For "wasm" type: path to the .wasm module
+	// When Module is set, Path is the package directory within the module.
 	Path string `toml:"path"`
 
+	// Module is the module path of a plugin fetched through the Go module
+	// cache or GOPROXY instead of a local path (e.g., "example.com/gen/markgen").
+	// The module is built into the plugin cache according to Type.
+	Module string `toml:"module"`
+
+	// Version is the module version (e.g., "v1.2.3"). If empty, the version
+	// required by the project's go.mod is used.
+	Version string `toml:"version"`
+
 	// Type specifies how to load the plugin.
 	// - "source": compile Go source code at runtime (default)
 	// - "plugin": load as Go plugin (.so)
+	// - "exec": run an executable speaking the JSON plugin protocol
+	// - "wasm": run a WebAssembly module speaking the JSON plugin protocol
 	Type PluginType `toml:"type"`
+
+	// MemoryLimitMB limits the memory of "wasm" plugins in MiB.
+	// Default: 256
+	MemoryLimitMB int `toml:"memory_limit_mb"`
+
+	// Timeout limits each request to an "exec" or "wasm" plugin, e.g. "30s".
+	// For "exec" plugins it only applies to run and validate requests.
+	// Default: 30s for "wasm" plugins, none for "exec" plugins
+	Timeout string `toml:"timeout"`
+
+	// Options are passed to the plugin if it implements OptionsTool.
+	// They override [tools.<name>.options]. For a tool set, configure each
+	// tool in [tools.<name>.options] instead.
+	Options map[string]any `toml:"options"`
+
+	// Tools selects a subset of the tools of a plugin that exports a tool
+	// set, e.g. ["markgen", "docgen"]. Default: all tools.
+	Tools []string `toml:"tools"`
+
+	// OverrideBuiltin allows the plugin's tools to replace built-in tools
+	// of the same name. Without it, such a collision is an error.
+	OverrideBuiltin bool `toml:"override_builtin"`
+
+	// AllowedPaths lists files and directories, relative to devgen.toml,
+	// the plugin's tools may write to in addition to the directories of
+	// the packages they process, e.g. ["docs/generated"].
+	AllowedPaths []string `toml:"allowed_paths"`
+
+	// dir is the directory of the devgen.toml that declared the plugin.
+	dir string
+
+	// locked is the devgen.lock entry of a module plugin.
+	locked *LockedPlugin
 }
 
 // PluginType defines how a plugin is loaded.
@@ -75,6 +137,14 @@ const (
 
 	// PluginTypePlugin loads a pre-compiled Go plugin (.so).
 	PluginTypePlugin PluginType = "plugin"
+
+	// PluginTypeExec runs a plugin executable that exchanges JSON messages
+	// over stdin/stdout. See the pluginsdk package.
+	PluginTypeExec PluginType = "exec"
+
+	// PluginTypeWasm runs a WebAssembly (WASI) module in an embedded runtime.
+	// The module speaks the same protocol as PluginTypeExec.
+	PluginTypeWasm PluginType = "wasm"
 )
 
 // ToolConfig defines configuration for a specific tool.
@@ -84,6 +154,10 @@ type ToolConfig struct {
 
 	// Annotations defines the annotations supported by this tool.
 	Annotations []AnnotationConfig `toml:"annotations"`
+
+	// Options are passed to the tool if it implements OptionsTool.
+	// The accepted keys are declared by the tool's OptionsSchema.
+	Options map[string]any `toml:"options"`
 }
 
 // AnnotationConfig defines a single annotation's metadata.
@@ -166,12 +240,30 @@ func LoadConfigFile(path string) (*Config, error) {
 		return nil, fmt.Errorf("parse config %s: %w", path, err)
 	}
 
+	if err := cfg.Diagnostics.Validate(); err != nil {
+		return nil, fmt.Errorf("parse config %s: %w", path, err)
+	}
+
+	lock, err := LoadPluginLock(path)
+	if err != nil {
+		return nil, err
+	}
+
 	// Resolve relative paths based on config file location
 	configDir := filepath.Dir(path)
 	for i := range cfg.Plugins {
-		if !filepath.IsAbs(cfg.Plugins[i].Path) {
+		cfg.Plugins[i].dir = configDir
+		if cfg.Plugins[i].IsModule() {
+			// Path is relative to the module root
+			cfg.Plugins[i].locked = lock.Find(cfg.Plugins[i].Name)
+		} else if !filepath.IsAbs(cfg.Plugins[i].Path) {
 			cfg.Plugins[i].Path = filepath.Join(configDir, cfg.Plugins[i].Path)
 		}
+		for j, allowed := range cfg.Plugins[i].AllowedPaths {
+			if !filepath.IsAbs(allowed) {
+				cfg.Plugins[i].AllowedPaths[j] = filepath.Join(configDir, allowed)
+			}
+		}
 		// Default type is source
 		if cfg.Plugins[i].Type == "" {
 			cfg.Plugins[i].Type = PluginTypeSource
@@ -274,12 +366,28 @@ func GetToolConfig(t Tool) ToolConfig {
 }
 
 // MergeToolConfigs merges tool configurations from multiple sources.
-// Later sources override earlier ones for the same tool name.
+// Later sources override earlier ones for the same tool name. Fields left
+// empty in a later source are kept, so a [tools.<name>] table that only
+// sets options does not drop the tool's annotations.
 func MergeToolConfigs(configs ...map[string]ToolConfig) map[string]ToolConfig {
 	result := make(map[string]ToolConfig)
 	for _, cfg := range configs {
 		for name, tc := range cfg {
-			result[name] = tc
+			prev, ok := result[name]
+			if !ok {
+				result[name] = tc
+				continue
+			}
+			if tc.OutputSuffix != "" {
+				prev.OutputSuffix = tc.OutputSuffix
+			}
+			if len(tc.Annotations) > 0 {
+				prev.Annotations = tc.Annotations
+			}
+			if len(tc.Options) > 0 {
+				prev.Options = tc.Options
+			}
+			result[name] = prev
 		}
 	}
 	return result
@@ -0,0 +1,238 @@
+package genkit
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreDirective is the comment prefix that suppresses diagnostics.
+//
+// Format:
+//
+//	//devgen:ignore CODE[,CODE...] reason
+//
+// CODE is either a bare diagnostic code ("E001"), which matches any tool,
+// or a rule ID ("enumgen/E001"), which matches a single tool.
+// The directive applies to its own line and to the line that follows the
+// comment block it belongs to, so it can be placed in a doc comment or as a
+// trailing comment.
+const IgnoreDirective = "//devgen:ignore"
+
+// Diagnostic codes reported by devgen itself.
+const (
+	// CodeUnusedIgnore reports a //devgen:ignore directive that suppressed nothing.
+	CodeUnusedIgnore = "unused-ignore"
+
+	// CodeInvalidIgnore reports a malformed //devgen:ignore directive.
+	CodeInvalidIgnore = "invalid-ignore"
+)
+
+// SeverityOff disables a diagnostic entirely when used in a severity override.
+const SeverityOff DiagnosticSeverity = "off"
+
+// DiagnosticsConfig configures how diagnostics are reported.
+//
+// Example devgen.toml:
+//
+//	[diagnostics.severity]
+//	"delegatorgen/W001" = "error"   # promote a single tool's code
+//	"E002" = "warning"              # demote a code for every tool
+//	"golangcilint/unused" = "off"   # drop entirely
+type DiagnosticsConfig struct {
+	// Severity overrides the severity of diagnostics by rule ID ("tool/code")
+	// or bare code. Values: "error", "warning", "info", "off".
+	Severity map[string]DiagnosticSeverity `toml:"severity"`
+
+	// ReportUnusedIgnores reports //devgen:ignore directives that did not
+	// suppress anything. Default: true
+	ReportUnusedIgnores *bool `toml:"report_unused_ignores"`
+}
+
+// Validate checks that all severity overrides use known severities.
+func (dc *DiagnosticsConfig) Validate() error {
+	for key, sev := range dc.Severity {
+		switch sev {
+		case DiagnosticError, DiagnosticWarning, DiagnosticInfo, SeverityOff:
+		default:
+			return fmt.Errorf("diagnostics.severity[%q]: unknown severity %q (want error, warning, info or off)", key, sev)
+		}
+	}
+	return nil
+}
+
+// ShouldReportUnusedIgnores returns whether unused ignore directives are reported, defaulting to true.
+func (dc *DiagnosticsConfig) ShouldReportUnusedIgnores() bool {
+	if dc.ReportUnusedIgnores == nil {
+		return true
+	}
+	return *dc.ReportUnusedIgnores
+}
+
+// severityFor returns the overridden severity for d, if any.
+// A rule ID override takes precedence over a bare code override.
+func (dc *DiagnosticsConfig) severityFor(d Diagnostic) (DiagnosticSeverity, bool) {
+	if sev, ok := dc.Severity[d.RuleID()]; ok {
+		return sev, true
+	}
+	if d.Code != "" {
+		if sev, ok := dc.Severity[d.Code]; ok {
+			return sev, true
+		}
+	}
+	return "", false
+}
+
+// Suppression is a parsed //devgen:ignore directive.
+type Suppression struct {
+	Codes  []string
+	Reason string
+	File   string
+	Line   int // line of the directive
+	Target int // line following the comment block
+	text   string
+	used   bool
+}
+
+// matches reports whether the suppression covers diagnostic d.
+func (s *Suppression) matches(d Diagnostic) bool {
+	if d.Line != s.Line && d.Line != s.Target {
+		return false
+	}
+	if !sameFile(s.File, d.File) {
+		return false
+	}
+	for _, code := range s.Codes {
+		if code == d.RuleID() || (d.Code != "" && code == d.Code) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameFile compares an absolute source path with a diagnostic path,
+// which may be relative (e.g. golangci-lint reports paths relative to its root).
+func sameFile(abs, file string) bool {
+	if file == "" {
+		return false
+	}
+	abs = filepath.Clean(abs)
+	file = filepath.Clean(file)
+	if filepath.IsAbs(file) {
+		return abs == file
+	}
+	return strings.HasSuffix(abs, string(filepath.Separator)+file)
+}
+
+// Suppressions returns all //devgen:ignore directives in the loaded packages.
+// Malformed directives are returned as diagnostics.
+func (g *Generator) Suppressions() ([]*Suppression, []Diagnostic) {
+	var sups []*Suppression
+	var diags []Diagnostic
+	for _, pkg := range g.Packages {
+		for _, file := range pkg.Syntax {
+			s, d := g.fileSuppressions(file)
+			sups = append(sups, s...)
+			diags = append(diags, d...)
+		}
+	}
+	return sups, diags
+}
+
+func (g *Generator) fileSuppressions(file *ast.File) ([]*Suppression, []Diagnostic) {
+	var sups []*Suppression
+	var diags []Diagnostic
+	for _, cg := range file.Comments {
+		target := g.Fset.Position(cg.End()).Line + 1
+		for _, c := range cg.List {
+			if !strings.HasPrefix(c.Text, IgnoreDirective) {
+				continue
+			}
+			rest := strings.TrimPrefix(c.Text, IgnoreDirective)
+			if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+				continue // a different directive, e.g. //devgen:ignored
+			}
+			pos := g.Fset.Position(c.Pos())
+			fields := strings.Fields(rest)
+			if len(fields) == 0 {
+				diags = append(diags, NewDiagnostic(DiagnosticWarning, "devgen", CodeInvalidIgnore,
+					"devgen:ignore directive requires a code, e.g. //devgen:ignore E001 reason", pos))
+				continue
+			}
+
+			var codes []string
+			for _, code := range strings.Split(fields[0], ",") {
+				if code = strings.TrimSpace(code); code != "" {
+					codes = append(codes, code)
+				}
+			}
+			sups = append(sups, &Suppression{
+				Codes:  codes,
+				Reason: strings.Join(fields[1:], " "),
+				File:   pos.Filename,
+				Line:   pos.Line,
+				Target: target,
+				text:   c.Text,
+			})
+		}
+	}
+	return sups, diags
+}
+
+// ApplyDiagnosticsConfig applies //devgen:ignore directives found in the
+// loaded packages and the severity overrides in cfg to diagnostics.
+// Suppressed diagnostics and diagnostics overridden to "off" are removed.
+// Unused and malformed directives are reported as warnings from the
+// "devgen" tool, unless disabled in cfg.
+func (g *Generator) ApplyDiagnosticsConfig(diagnostics []Diagnostic, cfg DiagnosticsConfig) []Diagnostic {
+	sups, own := g.Suppressions()
+
+	var kept []Diagnostic
+	for _, d := range diagnostics {
+		suppressed := false
+		for _, s := range sups {
+			if s.matches(d) {
+				s.used = true
+				suppressed = true
+			}
+		}
+		if !suppressed {
+			kept = append(kept, d)
+		}
+	}
+
+	if cfg.ShouldReportUnusedIgnores() {
+		for _, s := range sups {
+			if s.used {
+				continue
+			}
+			d := Diagnostic{
+				Severity: DiagnosticWarning,
+				Message:  fmt.Sprintf("unused devgen:ignore directive for %s", strings.Join(s.Codes, ", ")),
+				File:     s.File,
+				Line:     s.Line,
+				Tool:     "devgen",
+				Code:     CodeUnusedIgnore,
+			}
+			pos := token.Position{Filename: s.File, Line: s.Line}
+			if edit, ok := g.DeleteLineContaining(pos, s.text); ok {
+				d.Fixes = []SuggestedFix{{Message: "remove unused devgen:ignore directive", Edits: []TextEdit{edit}}}
+			}
+			own = append(own, d)
+		}
+	}
+
+	result := make([]Diagnostic, 0, len(kept)+len(own))
+	for _, d := range append(kept, own...) {
+		if sev, ok := cfg.severityFor(d); ok {
+			if sev == SeverityOff {
+				continue
+			}
+			d.Severity = sev
+		}
+		result = append(result, d)
+	}
+	return result
+}
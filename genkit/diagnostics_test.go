@@ -0,0 +1,119 @@
+package genkit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func loadTestPackage(t *testing.T, src string) (*Generator, string) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/test\n\ngo 1.21\n"), 0644); err != nil {
+		t.Fatalf("WriteFile error: %v", err)
+	}
+	file := filepath.Join(dir, "model.go")
+	if err := os.WriteFile(file, []byte(src), 0644); err != nil {
+		t.Fatalf("WriteFile error: %v", err)
+	}
+
+	gen := New(Options{Dir: dir})
+	if err := gen.Load("."); err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	return gen, file
+}
+
+func TestApplyDiagnosticsConfig_Suppressions(t *testing.T) {
+	gen, file := loadTestPackage(t, `package test
+
+// Status is a status.
+//devgen:ignore enumgen/E001 float enums are intentional
+type Status float64
+
+const A Status = 1 //devgen:ignore W001,E002 trailing
+
+//devgen:ignore E999 suppresses nothing
+const B = 2
+
+//devgen:ignore
+const C = 3
+`)
+
+	diags := []Diagnostic{
+		{Severity: DiagnosticError, Tool: "enumgen", Code: "E001", File: file, Line: 5},
+		{Severity: DiagnosticError, Tool: "validategen", Code: "E001", File: file, Line: 5},
+		{Severity: DiagnosticWarning, Tool: "delegatorgen", Code: "W001", File: file, Line: 7},
+		{Severity: DiagnosticError, Tool: "golangcilint", Code: "E002", File: "model.go", Line: 7},
+		{Severity: DiagnosticError, Tool: "enumgen", Code: "E003", File: file, Line: 7},
+	}
+
+	got := gen.ApplyDiagnosticsConfig(diags, DiagnosticsConfig{})
+
+	var codes []string
+	for _, d := range got {
+		codes = append(codes, d.RuleID())
+	}
+	want := []string{"validategen/E001", "enumgen/E003", "devgen/invalid-ignore", "devgen/unused-ignore"}
+	if len(codes) != len(want) {
+		t.Fatalf("ApplyDiagnosticsConfig() = %v, want %v", codes, want)
+	}
+	for _, w := range want {
+		found := false
+		for _, c := range codes {
+			if c == w {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("ApplyDiagnosticsConfig() = %v, missing %s", codes, w)
+		}
+	}
+}
+
+func TestApplyDiagnosticsConfig_SeverityOverrides(t *testing.T) {
+	gen, file := loadTestPackage(t, `package test
+
+//devgen:ignore E999 suppresses nothing
+const A = 1
+`)
+
+	off := false
+	cfg := DiagnosticsConfig{
+		Severity: map[string]DiagnosticSeverity{
+			"delegatorgen/W001": DiagnosticError,
+			"E002":              DiagnosticWarning,
+			"enumgen/E002":      DiagnosticInfo,
+			"golangcilint/lll":  SeverityOff,
+		},
+		ReportUnusedIgnores: &off,
+	}
+	diags := []Diagnostic{
+		{Severity: DiagnosticWarning, Tool: "delegatorgen", Code: "W001", File: file, Line: 1},
+		{Severity: DiagnosticError, Tool: "validategen", Code: "E002", File: file, Line: 1},
+		{Severity: DiagnosticError, Tool: "enumgen", Code: "E002", File: file, Line: 1},
+		{Severity: DiagnosticWarning, Tool: "golangcilint", Code: "lll", File: file, Line: 1},
+	}
+
+	got := gen.ApplyDiagnosticsConfig(diags, cfg)
+	if len(got) != 3 {
+		t.Fatalf("ApplyDiagnosticsConfig() returned %d diagnostics, want 3: %+v", len(got), got)
+	}
+	want := []DiagnosticSeverity{DiagnosticError, DiagnosticWarning, DiagnosticInfo}
+	for i, d := range got {
+		if d.Severity != want[i] {
+			t.Errorf("%s severity = %s, want %s", d.RuleID(), d.Severity, want[i])
+		}
+	}
+}
+
+func TestDiagnosticsConfig_Validate(t *testing.T) {
+	cfg := DiagnosticsConfig{Severity: map[string]DiagnosticSeverity{"E001": "fatal"}}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should reject unknown severity")
+	}
+	cfg = DiagnosticsConfig{Severity: map[string]DiagnosticSeverity{"E001": SeverityOff}}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
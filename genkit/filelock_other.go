@@ -0,0 +1,10 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package genkit
+
+// lockFile is a no-op on platforms without flock. Concurrent builds of the
+// same plugin are still safe, since outputs are renamed into place, but
+// may compile it more than once.
+func lockFile(path string) (unlock func(), err error) {
+	return func() {}, nil
+}
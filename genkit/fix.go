@@ -0,0 +1,287 @@
+package genkit
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"os"
+	"sort"
+	"strings"
+)
+
+// TextEdit replaces a range of source text.
+// Positions use the same convention as Diagnostic: lines and columns are
+// 1-based and columns count bytes. The end position is exclusive.
+// An empty range inserts NewText; an empty NewText deletes the range.
+type TextEdit struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	EndLine int    `json:"endLine"`
+	EndCol  int    `json:"endColumn"`
+	NewText string `json:"newText"`
+}
+
+// SuggestedFix is a machine-applicable fix for a diagnostic.
+type SuggestedFix struct {
+	// Message describes the fix, e.g. "replace ttl=5min with ttl=5m".
+	Message string     `json:"message"`
+	Edits   []TextEdit `json:"edits"`
+}
+
+// ReplaceText returns an edit that replaces the first occurrence of old with
+// newText, searching the source of pos.Filename from pos.Line upwards through
+// the comment lines directly above it. This locates annotations in the doc
+// comment of the reported declaration, and never in an earlier declaration.
+// The source is read with ReadFile, so unsaved editor buffers are used.
+// It returns false if old cannot be found.
+func (g *Generator) ReplaceText(pos token.Position, old, newText string) (TextEdit, bool) {
+	lines, err := g.readLines(pos.Filename)
+	if err != nil {
+		return TextEdit{}, false
+	}
+	return replaceText(lines, pos, old, newText)
+}
+
+// ReplaceAnnotationArg returns an edit that replaces the value of the key=value
+// argument of ann with value. ann is located like ReplaceText locates text,
+// and only the value is replaced, so other arguments with the same value are
+// left alone. It returns false if ann or the argument cannot be found.
+func (g *Generator) ReplaceAnnotationArg(pos token.Position, ann *Annotation, key, value string) (TextEdit, bool) {
+	start, end, ok := annotationArgRange(ann.Raw, key)
+	if !ok {
+		return TextEdit{}, false
+	}
+	edit, ok := g.ReplaceText(pos, ann.Raw, value)
+	if !ok {
+		return TextEdit{}, false
+	}
+	edit.EndCol = edit.Column + end
+	edit.Column += start
+	return edit, true
+}
+
+// DeleteLineContaining returns an edit that removes the comment line containing
+// text, searching from pos.Line upwards like ReplaceText. If the comment trails
+// code on the same line, only the comment is removed.
+func (g *Generator) DeleteLineContaining(pos token.Position, text string) (TextEdit, bool) {
+	lines, err := g.readLines(pos.Filename)
+	if err != nil {
+		return TextEdit{}, false
+	}
+	edit, ok := replaceText(lines, pos, text, "")
+	if !ok {
+		return TextEdit{}, false
+	}
+	line := lines[edit.Line-1]
+	before := strings.TrimRight(line[:edit.Column-1], " \t")
+	after := line[edit.EndCol-1:]
+	if strings.TrimSpace(after) != "" {
+		// Text is followed by more content, remove only the text itself
+		return edit, true
+	}
+
+	code := strings.TrimRight(strings.TrimSuffix(before, "//"), " \t")
+	if code == "" {
+		edit.Column = 1
+		edit.EndLine = edit.Line + 1
+		edit.EndCol = 1
+		return edit, true
+	}
+	edit.Column = len(code) + 1
+	edit.EndCol = len(line) + 1
+	return edit, true
+}
+
+// replaceText implements ReplaceText on the lines of a file.
+func replaceText(lines []string, pos token.Position, old, newText string) (TextEdit, bool) {
+	if old == "" {
+		return TextEdit{}, false
+	}
+	start := pos.Line
+	if start > len(lines) {
+		start = len(lines)
+	}
+	for line := start; line >= 1; line-- {
+		if line < start && !strings.HasPrefix(strings.TrimSpace(lines[line-1]), "//") {
+			break
+		}
+		if idx := strings.Index(lines[line-1], old); idx >= 0 {
+			return TextEdit{
+				File:    pos.Filename,
+				Line:    line,
+				Column:  idx + 1,
+				EndLine: line,
+				EndCol:  idx + len(old) + 1,
+				NewText: newText,
+			}, true
+		}
+	}
+	return TextEdit{}, false
+}
+
+// annotationArgRange returns the byte range of the value of the key=value
+// argument in raw annotation text, without surrounding spaces and quotes.
+func annotationArgRange(raw, key string) (start, end int, ok bool) {
+	open := strings.IndexByte(raw, '(')
+	if open < 0 || !strings.HasSuffix(raw, ")") {
+		return 0, 0, false
+	}
+	offset := open + 1
+	for _, arg := range strings.Split(raw[offset:len(raw)-1], ",") {
+		k, _, found := strings.Cut(arg, "=")
+		if found && strings.TrimSpace(k) == key {
+			start = offset + len(k) + 1
+			end = offset + len(arg)
+			for start < end && strings.ContainsRune(" \t\"'", rune(raw[start])) {
+				start++
+			}
+			for end > start && strings.ContainsRune(" \t\"'", rune(raw[end-1])) {
+				end--
+			}
+			return start, end, true
+		}
+		offset += len(arg) + 1
+	}
+	return 0, 0, false
+}
+
+// ApplyFixes applies the first suggested fix of each diagnostic and returns
+// the new content of every modified file. Fixes whose edits overlap an
+// already accepted edit are skipped and returned as skipped.
+func ApplyFixes(diagnostics []Diagnostic) (map[string][]byte, []SuggestedFix, error) {
+	byFile := make(map[string][]TextEdit)
+	var skipped []SuggestedFix
+
+	for _, d := range diagnostics {
+		if len(d.Fixes) == 0 {
+			continue
+		}
+		fix := d.Fixes[0]
+		if fixOverlaps(byFile, fix) {
+			skipped = append(skipped, fix)
+			continue
+		}
+		for _, e := range fix.Edits {
+			byFile[e.File] = append(byFile[e.File], e)
+		}
+	}
+
+	result := make(map[string][]byte)
+	for file, edits := range byFile {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, nil, fmt.Errorf("read %s: %w", file, err)
+		}
+		updated, err := ApplyEdits(content, edits)
+		if err != nil {
+			return nil, nil, fmt.Errorf("apply fixes to %s: %w", file, err)
+		}
+		result[file] = updated
+	}
+	return result, skipped, nil
+}
+
+// ApplyEdits applies non-overlapping edits to content.
+func ApplyEdits(content []byte, edits []TextEdit) ([]byte, error) {
+	type span struct {
+		start, end int
+		text       string
+	}
+	spans := make([]span, 0, len(edits))
+	for _, e := range edits {
+		start, err := offsetOf(content, e.Line, e.Column)
+		if err != nil {
+			return nil, err
+		}
+		end, err := offsetOf(content, e.EndLine, e.EndCol)
+		if err != nil {
+			return nil, err
+		}
+		if end < start {
+			return nil, fmt.Errorf("invalid edit range %d:%d-%d:%d", e.Line, e.Column, e.EndLine, e.EndCol)
+		}
+		spans = append(spans, span{start, end, e.NewText})
+	}
+
+	// Apply from the end so earlier offsets stay valid
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start > spans[j].start })
+	for i := 1; i < len(spans); i++ {
+		if spans[i].end > spans[i-1].start {
+			return nil, fmt.Errorf("overlapping edits at offset %d", spans[i-1].start)
+		}
+	}
+
+	out := content
+	for _, s := range spans {
+		var buf bytes.Buffer
+		buf.Grow(len(out) - (s.end - s.start) + len(s.text))
+		buf.Write(out[:s.start])
+		buf.WriteString(s.text)
+		buf.Write(out[s.end:])
+		out = buf.Bytes()
+	}
+	return out, nil
+}
+
+// offsetOf converts a 1-based line and byte column to a byte offset.
+// Line len(lines)+1, column 1 addresses the end of content.
+func offsetOf(content []byte, line, col int) (int, error) {
+	if line < 1 || col < 1 {
+		return 0, fmt.Errorf("invalid position %d:%d", line, col)
+	}
+	offset := 0
+	for l := 1; l < line; l++ {
+		idx := bytes.IndexByte(content[offset:], '\n')
+		if idx < 0 {
+			if l == line-1 && col == 1 {
+				return len(content), nil
+			}
+			return 0, fmt.Errorf("line %d out of range", line)
+		}
+		offset += idx + 1
+	}
+	if offset+col-1 > len(content) {
+		return 0, fmt.Errorf("column %d out of range on line %d", col, line)
+	}
+	return offset + col - 1, nil
+}
+
+// fixOverlaps reports whether any edit of fix overlaps an accepted edit.
+func fixOverlaps(accepted map[string][]TextEdit, fix SuggestedFix) bool {
+	for _, e := range fix.Edits {
+		for _, a := range accepted[e.File] {
+			if before(e.Line, e.Column, a.EndLine, a.EndCol) && before(a.Line, a.Column, e.EndLine, e.EndCol) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// before reports whether position (l1, c1) is strictly before (l2, c2).
+func before(l1, c1, l2, c2 int) bool {
+	return l1 < l2 || (l1 == l2 && c1 < c2)
+}
+
+// ReadFile returns the content of path, taken from the overlay if it has
+// one, so fixes are computed against unsaved editor buffers. A nil
+// Generator reads from disk.
+func (g *Generator) ReadFile(path string) ([]byte, error) {
+	if g != nil {
+		if data, ok := g.opts.Overlay[path]; ok {
+			return data, nil
+		}
+	}
+	return os.ReadFile(path)
+}
+
+// readLines reads a file with ReadFile and splits it into lines without
+// trailing newlines.
+func (g *Generator) readLines(path string) ([]string, error) {
+	data, err := g.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(string(data), "\n"), nil
+}
@@ -0,0 +1,153 @@
+package genkit
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const fixTestSource = `package test
+
+const (
+	// enumgen:@name
+	A = 1
+	B = 2 // enumgen:@name(b)
+)
+`
+
+func writeFixTestFile(t *testing.T) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "model.go")
+	if err := os.WriteFile(file, []byte(fixTestSource), 0644); err != nil {
+		t.Fatalf("WriteFile error: %v", err)
+	}
+	return file
+}
+
+func TestReplaceText(t *testing.T) {
+	file := writeFixTestFile(t)
+	gen := New()
+
+	edit, ok := gen.ReplaceText(token.Position{Filename: file, Line: 5}, "enumgen:@name", "enumgen:@name(a)")
+	if !ok {
+		t.Fatal("ReplaceText() did not find text")
+	}
+	if edit.Line != 4 || edit.Column != 5 || edit.EndLine != 4 || edit.EndCol != 18 {
+		t.Errorf("ReplaceText() = %+v", edit)
+	}
+
+	if _, ok := gen.ReplaceText(token.Position{Filename: file, Line: 5}, "missing", "x"); ok {
+		t.Error("ReplaceText() should not find missing text")
+	}
+	if _, ok := gen.ReplaceText(token.Position{Filename: file, Line: 7}, "enumgen:@name(b)", "x"); ok {
+		t.Error("ReplaceText() should stop at the first line that is not a comment")
+	}
+
+	// Unsaved buffers in the overlay take precedence over the file on disk
+	overlay := strings.Replace(fixTestSource, "// enumgen:@name\n", "\n// enumgen:@name\n", 1)
+	gen = New(Options{Overlay: map[string][]byte{file: []byte(overlay)}})
+	if edit, ok := gen.ReplaceText(token.Position{Filename: file, Line: 6}, "enumgen:@name", "x"); !ok || edit.Line != 5 {
+		t.Errorf("ReplaceText() with overlay = %+v, %v, want line 5", edit, ok)
+	}
+}
+
+func TestReplaceAnnotationArg(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "repo.go")
+	src := "package test\n\ntype Repo interface {\n\t// cache:@cache(prefix=5min, ttl = \"5min\")\n\tGet() error\n}\n"
+	if err := os.WriteFile(file, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	gen := New()
+	pos := token.Position{Filename: file, Line: 5}
+	ann := GetAnnotation("cache:@cache(prefix=5min, ttl = \"5min\")", "cache", "cache")
+
+	edit, ok := gen.ReplaceAnnotationArg(pos, ann, "ttl", "5m")
+	if !ok {
+		t.Fatal("ReplaceAnnotationArg() did not find the argument")
+	}
+	got, err := ApplyEdits([]byte(src), []TextEdit{edit})
+	if err != nil {
+		t.Fatalf("ApplyEdits() error = %v", err)
+	}
+	if want := strings.Replace(src, `ttl = "5min"`, `ttl = "5m"`, 1); string(got) != want {
+		t.Errorf("ReplaceAnnotationArg() applied = %q, want %q", got, want)
+	}
+
+	if _, ok := gen.ReplaceAnnotationArg(pos, ann, "key", "x"); ok {
+		t.Error("ReplaceAnnotationArg() should not find a missing argument")
+	}
+}
+
+func TestApplyEdits(t *testing.T) {
+	file := writeFixTestFile(t)
+	pos := func(line int) token.Position { return token.Position{Filename: file, Line: line} }
+
+	gen := New()
+	own, ok := gen.DeleteLineContaining(pos(5), "enumgen:@name")
+	if !ok {
+		t.Fatal("DeleteLineContaining() did not find own-line comment")
+	}
+	trailing, ok := gen.DeleteLineContaining(pos(6), "enumgen:@name(b)")
+	if !ok {
+		t.Fatal("DeleteLineContaining() did not find trailing comment")
+	}
+
+	got, err := ApplyEdits([]byte(fixTestSource), []TextEdit{trailing, own})
+	if err != nil {
+		t.Fatalf("ApplyEdits() error = %v", err)
+	}
+	want := `package test
+
+const (
+	A = 1
+	B = 2
+)
+`
+	if string(got) != want {
+		t.Errorf("ApplyEdits() =\n%s\nwant:\n%s", got, want)
+	}
+
+	if _, err := ApplyEdits([]byte(fixTestSource), []TextEdit{{Line: 100, Column: 1, EndLine: 100, EndCol: 2}}); err == nil {
+		t.Error("ApplyEdits() should reject out-of-range edits")
+	}
+}
+
+func TestApplyFixes_SkipsOverlapping(t *testing.T) {
+	file := writeFixTestFile(t)
+	gen := New()
+	edit, _ := gen.ReplaceText(token.Position{Filename: file, Line: 4}, "enumgen:@name", "enumgen:@name(a)")
+	other, _ := gen.ReplaceText(token.Position{Filename: file, Line: 4}, "@name", "@label")
+
+	diags := []Diagnostic{
+		{Fixes: []SuggestedFix{{Message: "first", Edits: []TextEdit{edit}}}},
+		{Fixes: []SuggestedFix{{Message: "second", Edits: []TextEdit{other}}}},
+		{Message: "no fix"},
+	}
+	files, skipped, err := ApplyFixes(diags)
+	if err != nil {
+		t.Fatalf("ApplyFixes() error = %v", err)
+	}
+	if len(skipped) != 1 || skipped[0].Message != "second" {
+		t.Errorf("skipped = %+v, want second fix", skipped)
+	}
+	if len(files) != 1 {
+		t.Fatalf("ApplyFixes() modified %d files, want 1", len(files))
+	}
+	content, _ := os.ReadFile(file)
+	if string(content) != fixTestSource {
+		t.Error("ApplyFixes() must not write files")
+	}
+}
+
+func TestDiagnosticCollector_WithFix(t *testing.T) {
+	c := NewDiagnosticCollector("test")
+	c.WithFix("ignored", TextEdit{}) // no diagnostic yet
+	c.Error("E001", "bad", token.Position{}).WithFix("fix it", TextEdit{Line: 1, Column: 1, EndLine: 1, EndCol: 1})
+
+	diags := c.Collect()
+	if len(diags) != 1 || len(diags[0].Fixes) != 1 || diags[0].Fixes[0].Message != "fix it" {
+		t.Errorf("Collect() = %+v", diags)
+	}
+}
@@ -50,6 +50,9 @@ type Generator struct {
 
 	generatedFiles []*GeneratedFile
 	opts           Options
+
+	// policy restricts the files created by NewGeneratedFile.
+	policy *WritePolicy
 }
 
 // Options configures the generator.
@@ -68,6 +71,11 @@ type Options struct {
 	// IncludeTests when true, tools should also generate *_test.go files.
 	// Tools can check this option via Generator.IncludeTests() method.
 	IncludeTests bool
+
+	// Overlay maps absolute file paths to contents that replace the files on
+	// disk when loading packages. This is used by editors to validate unsaved
+	// buffers.
+	Overlay map[string][]byte
 }
 
 // New creates a new Generator.
@@ -103,6 +111,7 @@ func (g *Generator) Load(patterns ...string) error {
 		Fset:       g.Fset,
 		Dir:        g.opts.Dir,
 		BuildFlags: buildFlags(g.opts.Tags),
+		Overlay:    g.opts.Overlay,
 	}
 
 	pkgs, err := packages.Load(cfg, patterns...)
@@ -201,13 +210,18 @@ func (g *Generator) NewGeneratedFile(filename string, importPath GoImportPath) *
 		imports:       make(map[GoImportPath]*importInfo),
 		usedPackages:  make(map[GoPackageName]GoImportPath),
 		manualImports: make(map[GoImportPath]GoPackageName),
+		policy:        g.policy,
 	}
 	g.generatedFiles = append(g.generatedFiles, gf)
 	return gf
 }
 
-// Write writes all generated files to disk.
+// Write writes all generated files to disk. If any file violates its
+// write policy, nothing is written and a *WriteError is returned.
 func (g *Generator) Write() error {
+	if err := g.checkWrites(); err != nil {
+		return err
+	}
 	for _, gf := range g.generatedFiles {
 		if gf.skip {
 			continue
@@ -232,7 +246,11 @@ func (g *Generator) Write() error {
 }
 
 // DryRun returns generated content without writing files.
+// Like Write, it fails with a *WriteError on write policy violations.
 func (g *Generator) DryRun() (map[string][]byte, error) {
+	if err := g.checkWrites(); err != nil {
+		return nil, err
+	}
 	result := make(map[string][]byte)
 	for _, gf := range g.generatedFiles {
 		if gf.skip {
@@ -502,6 +520,7 @@ type GeneratedFile struct {
 	usedPackages  map[GoPackageName]GoImportPath
 	manualImports map[GoImportPath]GoPackageName
 	skip          bool
+	policy        *WritePolicy
 }
 
 type importInfo struct {
@@ -886,8 +905,8 @@ type Method struct {
 
 // Param represents a method parameter or return value.
 type Param struct {
-	Name string // parameter name (may be empty for returns)
-	Type string // type as string (e.g., "context.Context", "*User", "error")
+	Name string `json:"name,omitempty"` // parameter name (may be empty for returns)
+	Type string `json:"type"`           // type as string (e.g., "context.Context", "*User", "error")
 }
 
 // Helper functions
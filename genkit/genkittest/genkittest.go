@@ -0,0 +1,368 @@
+// Package genkittest runs genkit tools against testdata modules and compares
+// their output with golden files.
+//
+// A test case is a directory containing a Go module (with its own go.mod)
+// and, next to the inputs, the expected output:
+//
+//	testdata/basic/
+//	    go.mod
+//	    model.go
+//	    model_enum.go.golden   // expected content of the generated model_enum.go
+//	    diagnostics.golden     // expected diagnostics, if any
+//
+// Run loads the module, validates it if the tool implements
+// genkit.ValidatableTool, runs the tool and compares every generated file
+// and the diagnostics with their golden files. The generated files are then
+// type-checked together with the inputs by go vet, so generator bugs that
+// produce invalid code fail the test. Nothing is written to the test case
+// directory.
+//
+// Run the tests with -update to rewrite the golden files:
+//
+//	go test ./... -run TestGolden -update
+//
+// Basic usage:
+//
+//	func TestGolden(t *testing.T) {
+//	    genkittest.RunAll(t, func() genkit.Tool { return generator.New() }, "testdata")
+//	}
+package genkittest
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/tlipoca9/devgen/genkit"
+)
+
+// update rewrites golden files instead of comparing against them.
+var update = flag.Bool("update", false, "update golden files")
+
+// GoldenSuffix is the suffix of golden files of generated files.
+const GoldenSuffix = ".golden"
+
+// DiagnosticsFile is the golden file holding the expected diagnostics.
+const DiagnosticsFile = "diagnostics.golden"
+
+// Options configures a golden test.
+type Options struct {
+	// Patterns are the package patterns to load, relative to the test case
+	// directory. Default: "./..."
+	Patterns []string
+
+	// Tags are build tags to use when loading packages.
+	Tags []string
+
+	// IncludeTests is passed to the generator, see genkit.Options.
+	IncludeTests bool
+
+	// ToolOptions are applied as if set in [tools.<name>.options] of
+	// devgen.toml. Tools implementing genkit.OptionsTool always receive
+	// their defaults.
+	ToolOptions map[string]any
+
+	// SkipVet disables running go vet on the generated files, e.g. for
+	// generators whose output needs dependencies the test module lacks.
+	SkipVet bool
+}
+
+// Result is the outcome of running a tool on a test case.
+type Result struct {
+	// Files maps generated file paths, relative to the test case
+	// directory and slash-separated, to their content.
+	Files map[string][]byte
+
+	// Diagnostics are the diagnostics reported by Validate, with file
+	// paths relative to the test case directory.
+	Diagnostics []genkit.Diagnostic
+
+	// Log is the output the tool logged.
+	Log string
+}
+
+// Run runs tool on the module in dir and compares the result with the
+// golden files in dir. Failures are reported through t; the result is
+// returned for additional assertions.
+func Run(t testing.TB, tool genkit.Tool, dir string, opts ...Options) *Result {
+	t.Helper()
+
+	var o Options
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	if len(o.Patterns) == 0 {
+		o.Patterns = []string{"./..."}
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &genkit.Config{Tools: map[string]genkit.ToolConfig{tool.Name(): {Options: o.ToolOptions}}}
+	if err := genkit.ApplyOptions([]genkit.Tool{tool}, cfg); err != nil {
+		t.Fatalf("tool options: %v", err)
+	}
+
+	gen := genkit.New(genkit.Options{
+		Dir:                  dir,
+		Tags:                 o.Tags,
+		IgnoreGeneratedFiles: true,
+		IncludeTests:         o.IncludeTests,
+	})
+	if err := gen.Load(o.Patterns...); err != nil {
+		t.Fatalf("load %s: %v", dir, err)
+	}
+
+	var logBuf bytes.Buffer
+	log := genkit.NewLoggerWithWriter(&logBuf).SetNoColor(true)
+	result := &Result{Files: make(map[string][]byte)}
+
+	if vt, ok := tool.(genkit.ValidatableTool); ok {
+		for _, d := range vt.Validate(gen, log) {
+			d.File = relPath(dir, d.File)
+			result.Diagnostics = append(result.Diagnostics, d)
+		}
+	}
+	if err := tool.Run(gen, log); err != nil {
+		t.Fatalf("%s: %v\n%s", tool.Name(), err, logBuf.String())
+	}
+	result.Log = logBuf.String()
+
+	files, err := gen.DryRun()
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	for path, content := range files {
+		result.Files[relPath(dir, path)] = content
+	}
+
+	checkGolden(t, dir, result)
+	if !o.SkipVet && len(files) > 0 {
+		vet(t, dir, o, files)
+	}
+	return result
+}
+
+// RunAll runs a golden test for every directory in root as a subtest.
+// newTool is called once per test case, so tool state such as options does
+// not leak between cases.
+func RunAll(t *testing.T, newTool func() genkit.Tool, root string, opts ...Options) {
+	t.Helper()
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ran := false
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		ran = true
+		dir := filepath.Join(root, entry.Name())
+		t.Run(entry.Name(), func(t *testing.T) {
+			Run(t, newTool(), dir, opts...)
+		})
+	}
+	if !ran {
+		t.Fatalf("no test cases found in %s", root)
+	}
+}
+
+// FormatDiagnostics formats diagnostics one per line, sorted by position,
+// as stored in diagnostics.golden.
+func FormatDiagnostics(diags []genkit.Diagnostic) string {
+	sorted := append([]genkit.Diagnostic(nil), diags...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+
+	var sb strings.Builder
+	for _, d := range sorted {
+		message := strings.ReplaceAll(d.Message, "\n", "\n\t")
+		fmt.Fprintf(&sb, "%s:%d:%d: %s: %s: %s\n", d.File, d.Line, d.Column, d.Severity, d.RuleID(), message)
+	}
+	return sb.String()
+}
+
+// checkGolden compares the result with the golden files in dir, or
+// rewrites them with -update.
+func checkGolden(t testing.TB, dir string, result *Result) {
+	t.Helper()
+
+	want := make(map[string][]byte, len(result.Files)+1)
+	for name, content := range result.Files {
+		want[filepath.FromSlash(name)+GoldenSuffix] = content
+	}
+	if len(result.Diagnostics) > 0 {
+		want[DiagnosticsFile] = []byte(FormatDiagnostics(result.Diagnostics))
+	}
+
+	existing, err := goldenFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *update {
+		for name := range existing {
+			if _, ok := want[name]; !ok {
+				if err := os.Remove(filepath.Join(dir, name)); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		for name, content := range want {
+			path := filepath.Join(dir, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, content, 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return
+	}
+
+	for _, name := range sortedKeys(want) {
+		golden, ok := existing[name]
+		if !ok {
+			t.Errorf("%s: missing golden file, got:\n%s\n(run with -update to create it)", name, want[name])
+			continue
+		}
+		if !bytes.Equal(golden, want[name]) {
+			t.Errorf("%s: output differs from golden file (run with -update to accept):\n%s", name, diff(golden, want[name]))
+		}
+	}
+	for _, name := range sortedKeys(existing) {
+		if _, ok := want[name]; !ok {
+			t.Errorf("%s: golden file has no generated counterpart (run with -update to remove it)", name)
+		}
+	}
+}
+
+// goldenFiles returns the golden files in dir, keyed by their path
+// relative to dir.
+func goldenFiles(dir string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, GoldenSuffix) {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[rel] = content
+		return nil
+	})
+	return files, err
+}
+
+// vet runs go vet on the test case with the generated files overlaid, so
+// they are type-checked together with the inputs without touching dir.
+func vet(t testing.TB, dir string, o Options, files map[string][]byte) {
+	t.Helper()
+
+	tmp := t.TempDir()
+	overlay := struct{ Replace map[string]string }{Replace: make(map[string]string)}
+	i := 0
+	for path, content := range files {
+		i++
+		tmpFile := filepath.Join(tmp, fmt.Sprintf("%d_%s", i, filepath.Base(path)))
+		if err := os.WriteFile(tmpFile, content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		overlay.Replace[path] = tmpFile
+	}
+	overlayFile := filepath.Join(tmp, "overlay.json")
+	data, err := json.Marshal(overlay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(overlayFile, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	args := []string{"vet", "-overlay=" + overlayFile}
+	if len(o.Tags) > 0 {
+		args = append(args, "-tags="+strings.Join(o.Tags, ","))
+	}
+	args = append(args, o.Patterns...)
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("go vet on generated code failed: %v\n%s", err, out)
+	}
+}
+
+// diff describes the first difference between want and got with a few
+// lines of context.
+func diff(want, got []byte) string {
+	wantLines := strings.Split(string(want), "\n")
+	gotLines := strings.Split(string(got), "\n")
+
+	line := 0
+	for line < len(wantLines) && line < len(gotLines) && wantLines[line] == gotLines[line] {
+		line++
+	}
+	from := max(line-3, 0)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "first difference at line %d\n", line+1)
+	for i := from; i < line; i++ {
+		fmt.Fprintf(&sb, "  %s\n", wantLines[i])
+	}
+	for i := line; i < min(line+3, len(wantLines)); i++ {
+		fmt.Fprintf(&sb, "- %s\n", wantLines[i])
+	}
+	for i := line; i < min(line+3, len(gotLines)); i++ {
+		fmt.Fprintf(&sb, "+ %s\n", gotLines[i])
+	}
+	return sb.String()
+}
+
+// relPath returns path relative to dir with forward slashes, or path
+// unchanged if it is outside dir.
+func relPath(dir, path string) string {
+	if path == "" || !filepath.IsAbs(path) {
+		return filepath.ToSlash(path)
+	}
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// sortedKeys returns the keys of m in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,185 @@
+package genkittest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/tlipoca9/devgen/genkit"
+)
+
+// stringTool generates a String method for every type annotated with
+// stringgen:@string and warns about unexported ones.
+type stringTool struct {
+	broken bool
+}
+
+func (t *stringTool) Name() string { return "stringgen" }
+
+func (t *stringTool) OptionsSchema() []genkit.OptionSpec {
+	return []genkit.OptionSpec{{Name: "broken", Type: genkit.OptionBool, Default: false}}
+}
+
+func (t *stringTool) SetOptions(opts map[string]any) error {
+	t.broken = opts["broken"].(bool)
+	return nil
+}
+
+func (t *stringTool) Run(gen *genkit.Generator, log *genkit.Logger) error {
+	for _, pkg := range gen.Packages {
+		for _, typ := range pkg.Types {
+			if !genkit.HasAnnotation(typ.Doc, "stringgen", "string") {
+				continue
+			}
+			g := gen.NewGeneratedFile(genkit.OutputPath(pkg.Dir, strings.ToLower(typ.Name)+"_string.go"), pkg.GoImportPath())
+			g.P("// Code generated by stringgen. DO NOT EDIT.")
+			g.P()
+			g.P("package ", pkg.Name)
+			g.P()
+			g.P("func (x ", typ.Name, ") String() string {")
+			if t.broken {
+				g.P("return undefinedName")
+			} else {
+				g.P(`return "`, typ.Name, `"`)
+			}
+			g.P("}")
+		}
+	}
+	return nil
+}
+
+func (t *stringTool) Validate(gen *genkit.Generator, log *genkit.Logger) []genkit.Diagnostic {
+	c := genkit.NewDiagnosticCollector("stringgen")
+	for _, pkg := range gen.Packages {
+		for _, typ := range pkg.Types {
+			if genkit.HasAnnotation(typ.Doc, "stringgen", "string") && !typ.TypeSpec.Name.IsExported() {
+				c.Warning("W001", "String method on unexported type "+typ.Name, gen.Fset.Position(typ.TypeSpec.Name.Pos()))
+			}
+		}
+	}
+	return c.Collect()
+}
+
+// recorder captures failures so tests can assert that Run reports them.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+	runtime.Goexit()
+}
+
+func (r *recorder) Fatal(args ...any) {
+	r.errors = append(r.errors, fmt.Sprint(args...))
+	runtime.Goexit()
+}
+
+// record runs fn with a recorder and returns the reported failures.
+func record(t *testing.T, fn func(tb testing.TB)) []string {
+	r := &recorder{TB: t}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(r)
+	}()
+	<-done
+	return r.errors
+}
+
+// copyCase copies a test case to a temporary directory.
+func copyCase(t *testing.T, name string) string {
+	t.Helper()
+	dst := t.TempDir()
+	src := filepath.Join("testdata", name)
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		data, err := os.ReadFile(filepath.Join(src, e.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dst, e.Name()), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dst
+}
+
+func TestRunAll(t *testing.T) {
+	RunAll(t, func() genkit.Tool { return &stringTool{} }, "testdata")
+}
+
+func TestRunResult(t *testing.T) {
+	result := Run(t, &stringTool{}, "testdata/basic")
+	if _, ok := result.Files["color_string.go"]; !ok || len(result.Files) != 2 {
+		t.Errorf("Files = %v", result.Files)
+	}
+	if len(result.Diagnostics) != 1 || result.Diagnostics[0].File != "model.go" {
+		t.Errorf("Diagnostics = %+v", result.Diagnostics)
+	}
+}
+
+func TestRunReportsMismatch(t *testing.T) {
+	dir := copyCase(t, "basic")
+	golden := filepath.Join(dir, "color_string.go.golden")
+	if err := os.WriteFile(golden, []byte("package basic\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, DiagnosticsFile)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "stale.go.golden"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	errs := strings.Join(record(t, func(tb testing.TB) { Run(tb, &stringTool{}, dir) }), "\n")
+	for _, want := range []string{
+		"color_string.go.golden: output differs from golden file",
+		"diagnostics.golden: missing golden file",
+		"stale.go.golden: golden file has no generated counterpart",
+	} {
+		if !strings.Contains(errs, want) {
+			t.Errorf("Run() errors missing %q:\n%s", want, errs)
+		}
+	}
+}
+
+func TestRunVet(t *testing.T) {
+	dir := copyCase(t, "basic")
+	opts := Options{ToolOptions: map[string]any{"broken": true}}
+
+	errs := strings.Join(record(t, func(tb testing.TB) { Run(tb, &stringTool{}, dir, opts) }), "\n")
+	if !strings.Contains(errs, "go vet on generated code failed") || !strings.Contains(errs, "undefinedName") {
+		t.Errorf("Run() errors = %s, want go vet failure", errs)
+	}
+
+	opts.SkipVet = true
+	errs = strings.Join(record(t, func(tb testing.TB) { Run(tb, &stringTool{}, dir, opts) }), "\n")
+	if strings.Contains(errs, "go vet") {
+		t.Errorf("Run() with SkipVet errors = %s", errs)
+	}
+}
+
+func TestFormatDiagnostics(t *testing.T) {
+	got := FormatDiagnostics([]genkit.Diagnostic{
+		{Severity: genkit.DiagnosticError, Tool: "x", Code: "E1", File: "b.go", Line: 1, Column: 1, Message: "two\nlines"},
+		{Severity: genkit.DiagnosticWarning, Tool: "x", File: "a.go", Line: 3, Column: 2, Message: "first"},
+	})
+	want := "a.go:3:2: warning: x: first\nb.go:1:1: error: x/E1: two\n\tlines\n"
+	if got != want {
+		t.Errorf("FormatDiagnostics() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,11 @@
+package basic
+
+// Color is a color.
+// stringgen:@string
+type Color int
+
+// Shade is not annotated.
+type Shade int
+
+// stringgen:@string
+type tone int
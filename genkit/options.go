@@ -0,0 +1,287 @@
+package genkit
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// OptionsTool extends Tool with options set in devgen.toml.
+// Options come from [tools.<name>.options] and, for plugins, from the
+// [plugins.options] table of the plugin entry:
+//
+//	[tools.delegatorgen.options]
+//	cache_ttl = "10m"
+//
+//	[[plugins]]
+//	name = "mygen"
+//	path = "./plugins/mygen"
+//	[plugins.options]
+//	style = "compact"
+type OptionsTool interface {
+	Tool
+
+	// OptionsSchema declares the accepted options.
+	// Unknown keys and values of the wrong type are reported before
+	// SetOptions is called.
+	OptionsSchema() []OptionSpec
+
+	// SetOptions applies the options. The map contains every option in the
+	// schema: configured values are normalized to the declared type and
+	// missing options are set to their defaults.
+	SetOptions(opts map[string]any) error
+}
+
+// OptionType is the type of a tool option.
+type OptionType string
+
+const (
+	// OptionString accepts a string. Values are passed as string.
+	OptionString OptionType = "string"
+
+	// OptionBool accepts a boolean. Values are passed as bool.
+	OptionBool OptionType = "bool"
+
+	// OptionInt accepts an integer. Values are passed as int.
+	OptionInt OptionType = "int"
+
+	// OptionDuration accepts a duration string such as "5m".
+	// Values are passed as time.Duration.
+	OptionDuration OptionType = "duration"
+
+	// OptionEnum accepts one of OptionSpec.Values. Values are passed as string.
+	OptionEnum OptionType = "enum"
+
+	// OptionList accepts a list of strings. Values are passed as []string.
+	OptionList OptionType = "list"
+)
+
+// OptionSpec declares a single tool option.
+type OptionSpec struct {
+	// Name is the option key in devgen.toml, e.g. "cache_ttl".
+	Name string `json:"name"`
+
+	// Type is the option type.
+	Type OptionType `json:"type"`
+
+	// Default is the value used when the option is not set.
+	Default any `json:"default,omitempty"`
+
+	// Values lists the allowed values for OptionEnum.
+	Values []string `json:"values,omitempty"`
+
+	// Doc describes the option.
+	Doc string `json:"doc,omitempty"`
+}
+
+// ValidateOptions checks opts against schema and returns the normalized
+// options with defaults filled in. All problems are reported together.
+func ValidateOptions(schema []OptionSpec, opts map[string]any) (map[string]any, error) {
+	specs := make(map[string]OptionSpec, len(schema))
+	for _, spec := range schema {
+		specs[spec.Name] = spec
+	}
+
+	var errs []error
+	for _, key := range sortedOptionKeys(opts) {
+		if _, ok := specs[key]; !ok {
+			errs = append(errs, unknownOptionError(key, schema))
+		}
+	}
+
+	result := make(map[string]any, len(schema))
+	for _, spec := range schema {
+		raw, ok := opts[spec.Name]
+		if !ok {
+			if spec.Default == nil {
+				continue
+			}
+			raw = spec.Default
+		}
+		v, err := normalizeOption(spec, raw)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("option %q: %w", spec.Name, err))
+			continue
+		}
+		result[spec.Name] = v
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return result, nil
+}
+
+// ToolOptions returns the options configured for the named tool.
+// Options of a plugin entry override [tools.<name>.options].
+func (c *Config) ToolOptions(name string) map[string]any {
+	opts := make(map[string]any)
+	for k, v := range c.Tools[name].Options {
+		opts[k] = v
+	}
+	for _, p := range c.Plugins {
+		if p.Name != name {
+			continue
+		}
+		for k, v := range p.Options {
+			opts[k] = v
+		}
+	}
+	return opts
+}
+
+// ApplyOptions validates the configured options of every tool and passes
+// them to tools that implement OptionsTool. Options configured for a tool
+// that does not accept options are reported as errors.
+func ApplyOptions(tools []Tool, cfg *Config) error {
+	var errs []error
+	for _, tool := range tools {
+		opts := cfg.ToolOptions(tool.Name())
+		ot, ok := tool.(OptionsTool)
+		if !ok {
+			if len(opts) > 0 {
+				errs = append(errs, fmt.Errorf("tool %s does not accept options, got %s",
+					tool.Name(), strings.Join(sortedOptionKeys(opts), ", ")))
+			}
+			continue
+		}
+
+		normalized, err := ValidateOptions(ot.OptionsSchema(), opts)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("tool %s: %w", tool.Name(), err))
+			continue
+		}
+		if err := ot.SetOptions(normalized); err != nil {
+			errs = append(errs, fmt.Errorf("tool %s: %w", tool.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// normalizeOption converts a raw TOML or JSON value to the declared type.
+func normalizeOption(spec OptionSpec, raw any) (any, error) {
+	switch spec.Type {
+	case OptionString:
+		if s, ok := raw.(string); ok {
+			return s, nil
+		}
+	case OptionBool:
+		if b, ok := raw.(bool); ok {
+			return b, nil
+		}
+	case OptionInt:
+		if n, ok := toInt(raw); ok {
+			return n, nil
+		}
+	case OptionDuration:
+		switch v := raw.(type) {
+		case time.Duration:
+			return v, nil
+		case string:
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid duration %q, expected a value like 5m, 1h, 30s", v)
+			}
+			return d, nil
+		default:
+			// Durations are encoded as nanoseconds in JSON
+			if n, ok := toInt(raw); ok {
+				return time.Duration(n), nil
+			}
+		}
+	case OptionEnum:
+		if s, ok := raw.(string); ok {
+			for _, allowed := range spec.Values {
+				if s == allowed {
+					return s, nil
+				}
+			}
+			return nil, fmt.Errorf("invalid value %q, expected one of: %s", s, strings.Join(spec.Values, ", "))
+		}
+	case OptionList:
+		switch v := raw.(type) {
+		case []string:
+			return v, nil
+		case []any:
+			list := make([]string, 0, len(v))
+			for _, item := range v {
+				s, ok := item.(string)
+				if !ok {
+					return nil, fmt.Errorf("expected a list of strings, got %T element", item)
+				}
+				list = append(list, s)
+			}
+			return list, nil
+		}
+	default:
+		return nil, fmt.Errorf("unsupported option type %q", spec.Type)
+	}
+	return nil, fmt.Errorf("expected %s, got %T", spec.Type, raw)
+}
+
+// toInt converts integer values decoded from TOML (int64) or JSON (float64).
+func toInt(raw any) (int, bool) {
+	switch v := raw.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		if v == math.Trunc(v) {
+			return int(v), true
+		}
+	}
+	return 0, false
+}
+
+// unknownOptionError reports an unknown key, suggesting a close match.
+func unknownOptionError(key string, schema []OptionSpec) error {
+	if len(schema) == 0 {
+		return fmt.Errorf("unknown option %q, no options are supported", key)
+	}
+	names := make([]string, 0, len(schema))
+	best, bestDist := "", 3
+	for _, spec := range schema {
+		names = append(names, spec.Name)
+		if d := editDistance(key, spec.Name); d < bestDist {
+			best, bestDist = spec.Name, d
+		}
+	}
+	if best != "" {
+		return fmt.Errorf("unknown option %q, did you mean %q?", key, best)
+	}
+	return fmt.Errorf("unknown option %q, supported options: %s", key, strings.Join(names, ", "))
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func sortedOptionKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
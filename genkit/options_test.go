@@ -0,0 +1,135 @@
+package genkit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+var testOptionsSchema = []OptionSpec{
+	{Name: "cache_ttl", Type: OptionDuration, Default: "5m"},
+	{Name: "style", Type: OptionEnum, Default: "joined", Values: []string{"joined", "errors"}},
+	{Name: "max", Type: OptionInt, Default: 10},
+	{Name: "verbose", Type: OptionBool},
+	{Name: "tags", Type: OptionList},
+}
+
+// optionsTestTool records the options it receives.
+type optionsTestTool struct {
+	name string
+	opts map[string]any
+}
+
+func (t *optionsTestTool) Name() string                         { return t.name }
+func (t *optionsTestTool) Run(*Generator, *Logger) error        { return nil }
+func (t *optionsTestTool) OptionsSchema() []OptionSpec          { return testOptionsSchema }
+func (t *optionsTestTool) SetOptions(opts map[string]any) error { t.opts = opts; return nil }
+
+type plainTestTool struct{ name string }
+
+func (t *plainTestTool) Name() string                  { return t.name }
+func (t *plainTestTool) Run(*Generator, *Logger) error { return nil }
+
+func TestValidateOptions(t *testing.T) {
+	var cfg Config
+	_, err := toml.Decode(`
+[tools.testgen.options]
+cache_ttl = "10m"
+max = 3
+tags = ["a", "b"]
+`, &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts, err := ValidateOptions(testOptionsSchema, cfg.Tools["testgen"].Options)
+	if err != nil {
+		t.Fatalf("ValidateOptions() error = %v", err)
+	}
+	if opts["cache_ttl"] != 10*time.Minute {
+		t.Errorf("cache_ttl = %v, want 10m", opts["cache_ttl"])
+	}
+	if opts["max"] != 3 {
+		t.Errorf("max = %#v, want int 3", opts["max"])
+	}
+	if opts["style"] != "joined" {
+		t.Errorf("style = %v, want default joined", opts["style"])
+	}
+	if tags, ok := opts["tags"].([]string); !ok || len(tags) != 2 {
+		t.Errorf("tags = %#v, want []string{a, b}", opts["tags"])
+	}
+	if _, ok := opts["verbose"]; ok {
+		t.Error("verbose without default should be omitted")
+	}
+
+	// JSON decoding produces float64 numbers and nanosecond durations
+	opts, err = ValidateOptions(testOptionsSchema, map[string]any{"cache_ttl": float64(time.Second), "max": float64(7)})
+	if err != nil {
+		t.Fatalf("ValidateOptions() error = %v", err)
+	}
+	if opts["cache_ttl"] != time.Second || opts["max"] != 7 {
+		t.Errorf("JSON options = %v", opts)
+	}
+}
+
+func TestValidateOptionsErrors(t *testing.T) {
+	_, err := ValidateOptions(testOptionsSchema, map[string]any{
+		"cache_tll": "1m",
+		"style":     "list",
+		"max":       "ten",
+		"unrelated": true,
+	})
+	if err == nil {
+		t.Fatal("ValidateOptions() should fail")
+	}
+	for _, want := range []string{
+		`unknown option "cache_tll", did you mean "cache_ttl"?`,
+		`unknown option "unrelated", supported options: cache_ttl, style, max, verbose, tags`,
+		`option "style": invalid value "list", expected one of: joined, errors`,
+		`option "max": expected int, got string`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error = %v\nwant it to contain %q", err, want)
+		}
+	}
+
+	if _, err := ValidateOptions(testOptionsSchema, map[string]any{"cache_ttl": "5 minutes"}); err == nil ||
+		!strings.Contains(err.Error(), `invalid duration "5 minutes"`) {
+		t.Errorf("invalid duration error = %v", err)
+	}
+}
+
+func TestApplyOptions(t *testing.T) {
+	cfg := &Config{
+		Tools: map[string]ToolConfig{
+			"testgen":  {Options: map[string]any{"max": int64(1), "style": "errors"}},
+			"plaingen": {Options: map[string]any{"x": 1}},
+		},
+		Plugins: []PluginConfig{{Name: "testgen", Options: map[string]any{"max": int64(2)}}},
+	}
+	tool := &optionsTestTool{name: "testgen"}
+
+	err := ApplyOptions([]Tool{tool, &plainTestTool{name: "plaingen"}}, cfg)
+	if err == nil || !strings.Contains(err.Error(), "tool plaingen does not accept options, got x") {
+		t.Errorf("ApplyOptions() error = %v", err)
+	}
+	if tool.opts["max"] != 2 || tool.opts["style"] != "errors" {
+		t.Errorf("options = %v, want plugin max=2 overriding tools max and style=errors", tool.opts)
+	}
+
+	if err := ApplyOptions([]Tool{&plainTestTool{name: "othergen"}}, cfg); err != nil {
+		t.Errorf("ApplyOptions() without options error = %v", err)
+	}
+}
+
+func TestMergeToolConfigsKeepsAnnotations(t *testing.T) {
+	builtin := map[string]ToolConfig{"testgen": {OutputSuffix: "_gen.go", Annotations: []AnnotationConfig{{Name: "gen"}}}}
+	file := map[string]ToolConfig{"testgen": {Options: map[string]any{"max": 1}}}
+
+	merged := MergeToolConfigs(builtin, file)["testgen"]
+	if merged.OutputSuffix != "_gen.go" || len(merged.Annotations) != 1 || merged.Options["max"] != 1 {
+		t.Errorf("MergeToolConfigs() = %+v", merged)
+	}
+}
@@ -2,13 +2,18 @@
 package genkit
 
 import (
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"plugin"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -19,8 +24,17 @@ type PluginLoader struct {
 	// cacheDir is the directory for compiled plugin cache.
 	cacheDir string
 
-	// loaded tracks loaded plugins to avoid duplicate loading.
-	loaded map[string]Tool
+	// loaded tracks the tools of loaded plugins to avoid duplicate loading.
+	loaded map[string][]Tool
+
+	// goEnvs caches the toolchain settings per build directory.
+	goEnvs map[string]string
+
+	// policies holds the write policy of every selected plugin tool.
+	policies map[string]*WritePolicy
+
+	// closers release the resources of loaded plugins, see Close.
+	closers []func() error
 
 	mu sync.Mutex
 }
@@ -33,28 +47,67 @@ func NewPluginLoader(cacheDir string) *PluginLoader {
 	}
 	return &PluginLoader{
 		cacheDir: cacheDir,
-		loaded:   make(map[string]Tool),
+		loaded:   make(map[string][]Tool),
+		policies: make(map[string]*WritePolicy),
+	}
+}
+
+// Close releases the resources held by loaded plugins, such as the runtimes
+// of WebAssembly plugins. Tools loaded by pl must not be used afterwards.
+func (pl *PluginLoader) Close() error {
+	pl.mu.Lock()
+	closers := pl.closers
+	pl.closers = nil
+	pl.loaded = make(map[string][]Tool)
+	pl.mu.Unlock()
+
+	var errs []error
+	for _, c := range closers {
+		errs = append(errs, c())
 	}
+	return errors.Join(errs...)
 }
 
-// LoadPlugin loads a plugin based on its configuration.
+// LoadPlugin loads a plugin that provides a single tool, either because
+// it exports one or because its configuration selects one.
 func (pl *PluginLoader) LoadPlugin(ctx context.Context, cfg PluginConfig) (Tool, error) {
+	tools, err := pl.LoadPluginTools(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(tools) != 1 {
+		return nil, fmt.Errorf("plugin %s provides %d tools (%s), select one with tools = [...]",
+			cfg.Name, len(tools), strings.Join(toolNames(tools), ", "))
+	}
+	return tools[0], nil
+}
+
+// LoadPluginTools loads a plugin and returns its tools. A plugin exports
+// a single tool or a tool set; PluginConfig.Tools selects a subset of the
+// set.
+func (pl *PluginLoader) LoadPluginTools(ctx context.Context, cfg PluginConfig) ([]Tool, error) {
 	pl.mu.Lock()
 	defer pl.mu.Unlock()
 
 	// Check if already loaded
-	if tool, ok := pl.loaded[cfg.Name]; ok {
-		return tool, nil
+	if tools, ok := pl.loaded[cfg.Name]; ok {
+		return pl.selectTools(cfg, tools)
 	}
 
-	var tool Tool
+	var tools []Tool
 	var err error
 
-	switch cfg.Type {
-	case PluginTypeSource, "":
-		tool, err = pl.loadSourcePlugin(ctx, cfg)
-	case PluginTypePlugin:
-		tool, err = pl.loadGoPlugin(cfg)
+	switch {
+	case cfg.IsModule():
+		tools, err = pl.loadModulePlugin(ctx, cfg)
+	case cfg.Type == PluginTypeSource || cfg.Type == "":
+		tools, err = pl.loadSourcePlugin(ctx, cfg)
+	case cfg.Type == PluginTypePlugin:
+		tools, err = pl.loadGoPlugin(cfg)
+	case cfg.Type == PluginTypeExec:
+		tools, err = pl.loadExecPlugin(ctx, cfg)
+	case cfg.Type == PluginTypeWasm:
+		tools, err = pl.loadWasmPlugin(ctx, cfg)
 	default:
 		return nil, fmt.Errorf("unknown plugin type: %s", cfg.Type)
 	}
@@ -63,166 +116,258 @@ func (pl *PluginLoader) LoadPlugin(ctx context.Context, cfg PluginConfig) (Tool,
 		return nil, err
 	}
 
-	pl.loaded[cfg.Name] = tool
-	return tool, nil
+	pl.loaded[cfg.Name] = tools
+	return pl.selectTools(cfg, tools)
+}
+
+// WritePolicy returns the write policy of a loaded plugin tool, or nil for
+// tools not loaded from a plugin, such as built-in tools.
+func (pl *PluginLoader) WritePolicy(tool string) *WritePolicy {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	return pl.policies[tool]
+}
+
+// selectTools selects the configured tools of a plugin and records their
+// write policies.
+func (pl *PluginLoader) selectTools(cfg PluginConfig, tools []Tool) ([]Tool, error) {
+	selected, err := selectTools(cfg, tools)
+	if err != nil {
+		return nil, err
+	}
+	for _, tool := range selected {
+		pl.policies[tool.Name()] = &WritePolicy{Tool: tool.Name(), Plugin: cfg.Name, AllowedPaths: cfg.AllowedPaths}
+	}
+	return selected, nil
 }
 
-// LoadPlugins loads all plugins from the configuration.
+// LoadPlugins loads all plugins from the configuration and returns their
+// tools. Two plugins providing a tool of the same name is an error.
 func (pl *PluginLoader) LoadPlugins(ctx context.Context, cfg *Config) ([]Tool, error) {
+	tools, _, err := pl.loadPlugins(ctx, cfg)
+	return tools, err
+}
+
+// LoadTools loads all plugins from the configuration and merges their
+// tools with the built-in tools. Plugin tools come first. A plugin tool
+// with the name of a built-in tool replaces it only if the plugin sets
+// override_builtin = true; otherwise the collision is an error.
+func (pl *PluginLoader) LoadTools(ctx context.Context, cfg *Config, builtin []Tool) ([]Tool, error) {
+	tools, origins, err := pl.loadPlugins(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return mergeTools(tools, origins, builtin)
+}
+
+// loadPlugins loads all plugins and returns their tools together with the
+// plugin that provided each tool, by tool name.
+func (pl *PluginLoader) loadPlugins(ctx context.Context, cfg *Config) ([]Tool, map[string]PluginConfig, error) {
 	var tools []Tool
+	origins := make(map[string]PluginConfig)
 	for _, pluginCfg := range cfg.Plugins {
-		tool, err := pl.LoadPlugin(ctx, pluginCfg)
+		pluginTools, err := pl.LoadPluginTools(ctx, pluginCfg)
 		if err != nil {
-			return nil, fmt.Errorf("load plugin %s: %w", pluginCfg.Name, err)
+			return nil, nil, fmt.Errorf("load plugin %s: %w", pluginCfg.Name, err)
+		}
+		// Plugin options go to the tool named like the plugin, which a tool set may not have
+		if len(pluginCfg.Options) > 0 && !slices.ContainsFunc(pluginTools, func(t Tool) bool { return t.Name() == pluginCfg.Name }) {
+			return nil, nil, fmt.Errorf("plugin %s provides the tools %s, configure their options in [tools.<name>.options] instead of [plugins.options]",
+				pluginCfg.Name, strings.Join(toolNames(pluginTools), ", "))
+		}
+		for _, tool := range pluginTools {
+			if other, ok := origins[tool.Name()]; ok {
+				return nil, nil, fmt.Errorf("tool %s is provided by plugins %s and %s, select a subset with tools = [...]",
+					tool.Name(), other.Name, pluginCfg.Name)
+			}
+			origins[tool.Name()] = pluginCfg
+			tools = append(tools, tool)
 		}
-		tools = append(tools, tool)
 	}
-	return tools, nil
+	return tools, origins, nil
 }
 
-// loadSourcePlugin compiles and loads a Go source plugin.
-func (pl *PluginLoader) loadSourcePlugin(ctx context.Context, cfg PluginConfig) (Tool, error) {
-	// Ensure cache directory exists
-	if err := os.MkdirAll(pl.cacheDir, 0755); err != nil {
-		return nil, fmt.Errorf("create cache dir: %w", err)
+// mergeTools appends the built-in tools not replaced by plugin tools.
+func mergeTools(tools []Tool, origins map[string]PluginConfig, builtin []Tool) ([]Tool, error) {
+	var errs []error
+	merged := append([]Tool(nil), tools...)
+	for _, tool := range builtin {
+		origin, ok := origins[tool.Name()]
+		if !ok {
+			merged = append(merged, tool)
+			continue
+		}
+		if !origin.OverrideBuiltin {
+			errs = append(errs, fmt.Errorf("plugin %s provides tool %s, which collides with the built-in tool; "+
+				"set override_builtin = true to replace it", origin.Name, tool.Name()))
+		}
 	}
-
-	// Check if source path exists
-	srcPath := cfg.Path
-	info, err := os.Stat(srcPath)
-	if err != nil {
-		return nil, fmt.Errorf("source path not found: %s", srcPath)
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
 	}
+	return merged, nil
+}
 
-	// Determine output .so path based on source modification time
-	var modTime time.Time
-	if info.IsDir() {
-		// Get latest modification time from Go files in directory
-		modTime, err = getLatestModTime(srcPath)
-		if err != nil {
-			return nil, err
+// selectTools returns the tools selected by cfg.Tools, in the configured
+// order, or all tools if there is no selection.
+func selectTools(cfg PluginConfig, tools []Tool) ([]Tool, error) {
+	byName := make(map[string]Tool, len(tools))
+	for _, tool := range tools {
+		if _, ok := byName[tool.Name()]; ok {
+			return nil, fmt.Errorf("plugin %s provides tool %s more than once", cfg.Name, tool.Name())
 		}
-	} else {
-		modTime = info.ModTime()
+		byName[tool.Name()] = tool
+	}
+	if len(cfg.Tools) == 0 {
+		return tools, nil
 	}
 
-	// Also consider genkit package modification time for cache invalidation
-	genkitModTime := getGenkitModTime()
-	if genkitModTime.After(modTime) {
-		modTime = genkitModTime
+	selected := make([]Tool, 0, len(cfg.Tools))
+	for _, name := range cfg.Tools {
+		tool, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("plugin %s has no tool %q (available: %s)", cfg.Name, name, strings.Join(toolNames(tools), ", "))
+		}
+		selected = append(selected, tool)
 	}
+	return selected, nil
+}
 
-	soName := fmt.Sprintf("%s_%d.so", cfg.Name, modTime.Unix())
-	soPath := filepath.Join(pl.cacheDir, soName)
+// toolNames returns the names of tools.
+func toolNames(tools []Tool) []string {
+	names := make([]string, len(tools))
+	for i, tool := range tools {
+		names[i] = tool.Name()
+	}
+	return names
+}
 
-	// Check if cached version exists
-	if _, err := os.Stat(soPath); os.IsNotExist(err) {
-		// Compile the plugin
-		if err := pl.compilePlugin(ctx, srcPath, soPath); err != nil {
-			return nil, fmt.Errorf("compile plugin: %w", err)
-		}
+// loadSourcePlugin compiles and loads a Go source plugin.
+func (pl *PluginLoader) loadSourcePlugin(ctx context.Context, cfg PluginConfig) ([]Tool, error) {
+	info, b, err := pl.buildPlugin(ctx, cfg)
+	if err != nil {
+		return nil, err
 	}
 
 	// Load the compiled plugin
-	tool, err := pl.loadGoPluginFile(soPath, cfg.Name)
-	if err != nil {
-		// If plugin version mismatch, remove all cached versions of this plugin and recompile
-		if strings.Contains(err.Error(), "different version") {
-			pl.cleanPluginCache(cfg.Name)
-			// Use current time to ensure unique cache path
-			soName = fmt.Sprintf("%s_%d.so", cfg.Name, time.Now().Unix())
-			soPath = filepath.Join(pl.cacheDir, soName)
-			if err := pl.compilePlugin(ctx, srcPath, soPath); err != nil {
-				return nil, fmt.Errorf("recompile plugin: %w", err)
-			}
-			return pl.loadGoPluginFile(soPath, cfg.Name)
+	tools, err := pl.loadGoPluginFile(info.CachePath, cfg.Name)
+	if err != nil && strings.Contains(err.Error(), "different version") {
+		// The cached build does not match this binary, e.g. after a
+		// toolchain change the key cannot see; rebuild it
+		_ = os.Remove(info.CachePath)
+		if err := pl.buildCached(ctx, *b, info.CachePath); err != nil {
+			return nil, fmt.Errorf("rebuild plugin %s: %w", cfg.Name, err)
 		}
-		return nil, err
+		return pl.reloadGoPluginFile(info.CachePath, cfg.Name)
 	}
-	return tool, nil
+	return tools, err
 }
 
-// cleanPluginCache removes all cached versions of a plugin.
-func (pl *PluginLoader) cleanPluginCache(pluginName string) {
-	entries, err := os.ReadDir(pl.cacheDir)
-	if err != nil {
-		return
-	}
-	prefix := pluginName + "_"
-	for _, entry := range entries {
-		if strings.HasPrefix(entry.Name(), prefix) && strings.HasSuffix(entry.Name(), ".so") {
-			_ = os.Remove(filepath.Join(pl.cacheDir, entry.Name()))
+// reloadGoPluginFile loads a rebuilt .so file. The plugin package
+// remembers failed opens by path, so the file is opened through a
+// uniquely named link.
+func (pl *PluginLoader) reloadGoPluginFile(path, name string) ([]Tool, error) {
+	link := fmt.Sprintf("%s.%d.so", strings.TrimSuffix(path, ".so"), time.Now().UnixNano())
+	if err := os.Link(path, link); err != nil {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(link, data, 0o755); err != nil {
+			return nil, err
 		}
 	}
+	defer os.Remove(link)
+	return pl.loadGoPluginFile(link, name)
 }
 
-// getGenkitModTime returns the modification time of the genkit package.
-// This helps invalidate plugin cache when genkit is modified.
-func getGenkitModTime() time.Time {
-	// Try to find genkit package in the module cache or local directory
-	// First, check if we're in development mode (genkit directory exists nearby)
-	execPath, err := os.Executable()
+// loadGoPlugin loads a pre-compiled Go plugin (.so file).
+func (pl *PluginLoader) loadGoPlugin(cfg PluginConfig) ([]Tool, error) {
+	return pl.loadGoPluginFile(cfg.Path, cfg.Name)
+}
+
+// loadGoPluginFile loads a .so file and extracts its tools.
+func (pl *PluginLoader) loadGoPluginFile(path, name string) ([]Tool, error) {
+	p, err := plugin.Open(path)
 	if err != nil {
-		return time.Time{}
+		return nil, fmt.Errorf("open plugin %s: %w", path, err)
 	}
 
-	// Check common development paths
-	devPaths := []string{
-		filepath.Join(filepath.Dir(execPath), "..", "genkit"),
-		filepath.Join(filepath.Dir(execPath), "genkit"),
+	// Check the declared genkit version before touching the Tool symbol
+	if sym, err := p.Lookup("GenkitVersion"); err == nil {
+		switch v := sym.(type) {
+		case *string:
+			if err := checkPluginVersion(name, *v); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("plugin %s: GenkitVersion has unexpected type %T, want string", name, sym)
+		}
 	}
 
-	// Also check GOPATH/pkg/mod for installed packages
-	if gopath := os.Getenv("GOPATH"); gopath != "" {
-		// This won't catch all cases but helps with some
-		devPaths = append(devPaths, filepath.Join(gopath, "pkg", "mod", "github.com", "tlipoca9", "devgen"))
+	tools, err := lookupPluginTools(p, name)
+	if err != nil {
+		return nil, err
 	}
-
-	var latest time.Time
-	for _, path := range devPaths {
-		if modTime, err := getLatestModTime(path); err == nil {
-			if modTime.After(latest) {
-				latest = modTime
+	for _, tool := range tools {
+		if vt, ok := tool.(VersionedTool); ok {
+			if err := checkPluginVersion(name, vt.GenkitVersion()); err != nil {
+				return nil, err
 			}
 		}
 	}
-	return latest
+	return tools, nil
 }
 
-// compilePlugin compiles Go source to a plugin .so file.
-func (pl *PluginLoader) compilePlugin(ctx context.Context, srcPath, outPath string) error {
-	// Build command: go build -buildmode=plugin -o outPath srcPath
-	cmd := exec.CommandContext(ctx, "go", "build", "-buildmode=plugin", "-o", outPath, srcPath)
-
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
+// lookupPluginTools extracts the tools of a Go plugin from the "Tools"
+// function or variable of a tool set, or falls back to a single tool.
+// A panic while loading is returned as an error, since it usually means the
+// plugin was built against an incompatible genkit.
+func lookupPluginTools(p *plugin.Plugin, name string) (tools []Tool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("plugin %s: panic while loading: %v (was it built for genkit %s?)", name, r, APIVersion)
+		}
+	}()
 
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("go build failed: %w\n%s", err, stderr.String())
+	sym, err := p.Lookup("Tools")
+	if err != nil {
+		tool, err := lookupPluginTool(p, name)
+		if err != nil {
+			return nil, err
+		}
+		return []Tool{tool}, nil
 	}
 
-	return nil
-}
-
-// loadGoPlugin loads a pre-compiled Go plugin (.so file).
-func (pl *PluginLoader) loadGoPlugin(cfg PluginConfig) (Tool, error) {
-	return pl.loadGoPluginFile(cfg.Path, cfg.Name)
-}
-
-// loadGoPluginFile loads a .so file and extracts the Tool.
-func (pl *PluginLoader) loadGoPluginFile(path, name string) (Tool, error) {
-	p, err := plugin.Open(path)
-	if err != nil {
-		return nil, fmt.Errorf("open plugin %s: %w", path, err)
+	switch t := sym.(type) {
+	case func() []Tool:
+		tools = t()
+	case *[]Tool:
+		tools = *t
+	default:
+		return nil, fmt.Errorf("plugin %s: Tools has unexpected type %T, want func() []genkit.Tool", name, sym)
+	}
+	if len(tools) == 0 {
+		return nil, fmt.Errorf("plugin %s: Tools returned no tools", name)
+	}
+	for i, tool := range tools {
+		if tool == nil {
+			return nil, fmt.Errorf("plugin %s: Tools returned a nil tool at index %d", name, i)
+		}
 	}
+	return tools, nil
+}
 
+// lookupPluginTool extracts the Tool from the "Tool" variable or "New"
+// function of a Go plugin.
+func lookupPluginTool(p *plugin.Plugin, name string) (Tool, error) {
 	// Look for exported "Tool" symbol
 	sym, err := p.Lookup("Tool")
 	if err != nil {
 		// Try looking for "New" function
 		newSym, newErr := p.Lookup("New")
 		if newErr != nil {
-			return nil, fmt.Errorf("plugin %s: no 'Tool' variable or 'New' function found", name)
+			return nil, fmt.Errorf("plugin %s: no 'Tools', 'Tool' or 'New' symbol found", name)
 		}
 
 		// Try different function signatures
@@ -250,53 +395,77 @@ func (pl *PluginLoader) loadGoPluginFile(path, name string) (Tool, error) {
 	}
 }
 
-// getLatestModTime returns the latest modification time of Go files in a directory.
-func getLatestModTime(dir string) (time.Time, error) {
-	var latest time.Time
+// sourceGenkitVersion returns the value of a package-level GenkitVersion
+// string constant or variable in the plugin source, or "" if there is none.
+func sourceGenkitVersion(srcPath string) (string, error) {
+	files := []string{srcPath}
+	if info, err := os.Stat(srcPath); err == nil && info.IsDir() {
+		files, err = filepath.Glob(filepath.Join(srcPath, "*.go"))
+		if err != nil {
+			return "", err
+		}
+	}
 
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, parser.SkipObjectResolution)
 		if err != nil {
-			return err
+			// Leave syntax errors to the compiler
+			continue
 		}
-		if !info.IsDir() && strings.HasSuffix(path, ".go") {
-			if info.ModTime().After(latest) {
-				latest = info.ModTime()
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || (gd.Tok != token.VAR && gd.Tok != token.CONST) {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				vs := spec.(*ast.ValueSpec)
+				for i, ident := range vs.Names {
+					if ident.Name != "GenkitVersion" || i >= len(vs.Values) {
+						continue
+					}
+					lit, ok := vs.Values[i].(*ast.BasicLit)
+					if !ok || lit.Kind != token.STRING {
+						return "", fmt.Errorf("%s: GenkitVersion must be a string literal", fset.Position(ident.Pos()))
+					}
+					return strconv.Unquote(lit.Value)
+				}
 			}
 		}
-		return nil
-	})
-
-	if err != nil {
-		return time.Time{}, err
 	}
-	if latest.IsZero() {
-		return time.Time{}, fmt.Errorf("no Go files found in %s", dir)
-	}
-	return latest, nil
+	return "", nil
 }
 
-// CleanCache removes old cached plugins.
+// CleanCache removes cached plugin builds, including compiled wasm
+// modules, that were written more than maxAge ago. A zero maxAge removes
+// the whole cache.
 func (pl *PluginLoader) CleanCache(maxAge time.Duration) error {
-	entries, err := os.ReadDir(pl.cacheDir)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
-		}
-		return err
-	}
-
 	cutoff := time.Now().Add(-maxAge)
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
+	err := filepath.WalkDir(pl.cacheDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
 		}
-		info, err := entry.Info()
+		if d.IsDir() {
+			// Lock files may be held by running builds
+			if d.Name() == ".locks" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := d.Info()
 		if err != nil {
-			continue
+			return nil
 		}
-		if info.ModTime().Before(cutoff) {
-			_ = os.Remove(filepath.Join(pl.cacheDir, entry.Name()))
+		if !info.ModTime().After(cutoff) {
+			_ = os.Remove(path)
 		}
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return nil
 	}
-	return nil
+	return err
 }
@@ -0,0 +1,318 @@
+package genkit
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+)
+
+// pluginBuild describes how a plugin is compiled. The cache key of a build
+// covers everything that can change its output: the sources of the plugin
+// and of the local packages it imports, the go.mod and go.sum of its module,
+// the Go toolchain and environment, the build flags and the genkit version,
+// so cached builds are reused across checkouts and invalidated exactly when
+// one of them changes.
+type pluginBuild struct {
+	// typ is the plugin type, which selects the build mode.
+	typ PluginType
+
+	// src is the Go package directory or single source file.
+	src string
+
+	// env holds extra environment variables for go build, e.g. GOWORK=off.
+	env []string
+}
+
+// dir returns the directory go build runs in.
+func (b pluginBuild) dir() string {
+	if info, err := os.Stat(b.src); err == nil && !info.IsDir() {
+		return filepath.Dir(b.src)
+	}
+	return b.src
+}
+
+// target returns the package argument of go build.
+func (b pluginBuild) target() string {
+	if info, err := os.Stat(b.src); err == nil && !info.IsDir() {
+		return filepath.Base(b.src)
+	}
+	return "."
+}
+
+// flags returns the go build flags for the plugin type.
+func (b pluginBuild) flags() []string {
+	switch b.typ {
+	case PluginTypeSource, "":
+		return []string{"-buildmode=plugin"}
+	default:
+		return nil
+	}
+}
+
+// environ returns the extra environment of the build.
+func (b pluginBuild) environ() []string {
+	env := append([]string(nil), b.env...)
+	if b.typ == PluginTypeWasm {
+		env = append(env, "GOOS=wasip1", "GOARCH=wasm")
+	}
+	return env
+}
+
+// run builds the plugin to outPath.
+func (b pluginBuild) run(ctx context.Context, outPath string) error {
+	args := append([]string{"build", "-o", outPath}, b.flags()...)
+	args = append(args, b.target())
+
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = b.dir()
+	cmd.Env = append(os.Environ(), b.environ()...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("go build failed: %w\n%s", err, stderr.String())
+	}
+	return nil
+}
+
+// buildKey returns the content hash identifying the output of b.
+func (pl *PluginLoader) buildKey(ctx context.Context, b pluginBuild) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "genkit %s\n", APIVersion)
+	if b.typ == PluginTypeSource || b.typ == "" {
+		// Go plugins must be built from the same genkit sources as the
+		// binary that opens them
+		fmt.Fprintf(h, "host %s\n", hostBuildID())
+	}
+	fmt.Fprintf(h, "flags %q\nenv %q\n", b.flags(), b.environ())
+
+	goEnv, err := pl.goEnv(ctx, b)
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprintf(h, "go env\n%s\n", goEnv)
+
+	if err := hashSources(ctx, h, b); err != nil {
+		return "", fmt.Errorf("hash plugin sources: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16], nil
+}
+
+// goEnv returns the toolchain settings that affect the build. The result
+// is cached per directory, since a go.mod toolchain line can select a
+// different Go version.
+func (pl *PluginLoader) goEnv(ctx context.Context, b pluginBuild) (string, error) {
+	cacheKey := b.dir() + "\x00" + strings.Join(b.environ(), "\x00")
+	if env, ok := pl.goEnvs[cacheKey]; ok {
+		return env, nil
+	}
+
+	cmd := exec.CommandContext(ctx, "go", "env", "GOVERSION", "GOOS", "GOARCH", "CGO_ENABLED", "GOFLAGS", "GOEXPERIMENT", "GOWORK")
+	cmd.Dir = b.dir()
+	cmd.Env = append(os.Environ(), b.environ()...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("go env failed: %w\n%s", err, stderr.String())
+	}
+
+	// Workspace builds also depend on go.work
+	env := string(out)
+	lines := strings.Split(strings.TrimSpace(env), "\n")
+	if work := lines[len(lines)-1]; work != "" && work != "off" && filepath.IsAbs(work) {
+		for _, name := range []string{work, work + ".sum"} {
+			if data, err := os.ReadFile(name); err == nil {
+				env += fmt.Sprintf("%s %x\n", filepath.Base(name), sha256.Sum256(data))
+			}
+		}
+	}
+
+	if pl.goEnvs == nil {
+		pl.goEnvs = make(map[string]string)
+	}
+	pl.goEnvs[cacheKey] = env
+	return env, nil
+}
+
+// listedPackage is the part of the go list output that hashSources uses.
+type listedPackage struct {
+	Dir        string
+	Standard   bool
+	GoFiles    []string
+	CgoFiles   []string
+	CFiles     []string
+	HFiles     []string
+	SFiles     []string
+	EmbedFiles []string
+	Module     *struct {
+		Main    bool
+		Replace *struct{ Version string }
+	}
+}
+
+// local reports whether the package is part of a main module or of a
+// module replaced by a local directory, whose files can change in place.
+// Other modules are identified by their version in go.sum.
+func (p *listedPackage) local() bool {
+	if p.Standard || p.Module == nil {
+		return false
+	}
+	return p.Module.Main || (p.Module.Replace != nil && p.Module.Replace.Version == "")
+}
+
+// hashSources writes the files of every local package the plugin imports,
+// directly or transitively, and the go.mod and go.sum of its module to h.
+// Packages are listed with go list in the build environment, so only the
+// files that go into the build are hashed; tests are not.
+func hashSources(ctx context.Context, h hash.Hash, b pluginBuild) error {
+	cmd := exec.CommandContext(ctx, "go", "list", "-e", "-deps",
+		"-json=Dir,Standard,GoFiles,CgoFiles,CFiles,HFiles,SFiles,EmbedFiles,Module", b.target())
+	cmd.Dir = b.dir()
+	cmd.Env = append(os.Environ(), b.environ()...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("go list failed: %w\n%s", err, stderr.String())
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for dec.More() {
+		var pkg listedPackage
+		if err := dec.Decode(&pkg); err != nil {
+			return fmt.Errorf("decode go list output: %w", err)
+		}
+		if !pkg.local() {
+			continue
+		}
+		rel, err := filepath.Rel(b.dir(), pkg.Dir)
+		if err != nil {
+			rel = pkg.Dir
+		}
+		for _, files := range [][]string{pkg.GoFiles, pkg.CgoFiles, pkg.CFiles, pkg.HFiles, pkg.SFiles, pkg.EmbedFiles} {
+			for _, name := range files {
+				if err := hashFile(h, filepath.ToSlash(filepath.Join(rel, name)), filepath.Join(pkg.Dir, name)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if _, modDir, err := findGoMod(b.dir()); err == nil && modDir != "" {
+		if err := hashFile(h, "go.mod", filepath.Join(modDir, "go.mod")); err != nil {
+			return err
+		}
+		gosum := filepath.Join(modDir, "go.sum")
+		if _, err := os.Stat(gosum); err == nil {
+			return hashFile(h, "go.sum", gosum)
+		}
+	}
+	return nil
+}
+
+// hashFile writes the name and content of a file to h.
+func hashFile(h hash.Hash, name, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fmt.Fprintf(h, "file %s\n", name)
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+	_, _ = h.Write([]byte{0})
+	return nil
+}
+
+var (
+	hostBuildIDOnce sync.Once
+	hostBuildIDVal  string
+)
+
+// hostBuildID identifies the running binary. Released builds are identified
+// by their module version and clean VCS builds by their revision; anything
+// else is identified by the content of the executable.
+func hostBuildID() string {
+	hostBuildIDOnce.Do(func() {
+		if info, ok := debug.ReadBuildInfo(); ok {
+			settings := make(map[string]string)
+			for _, s := range info.Settings {
+				settings[s.Key] = s.Value
+			}
+			switch {
+			case settings["vcs.modified"] == "true":
+			case settings["vcs.revision"] != "":
+				hostBuildIDVal = info.Main.Path + " " + settings["vcs.revision"]
+				return
+			case info.Main.Version != "" && info.Main.Version != "(devel)":
+				hostBuildIDVal = info.Main.Path + "@" + info.Main.Version
+				return
+			}
+		}
+
+		exe, err := os.Executable()
+		if err != nil {
+			return
+		}
+		f, err := os.Open(exe)
+		if err != nil {
+			return
+		}
+		defer f.Close()
+		h := sha256.New()
+		if _, err := io.Copy(h, f); err == nil {
+			hostBuildIDVal = hex.EncodeToString(h.Sum(nil))
+		}
+	})
+	return hostBuildIDVal
+}
+
+// buildCached builds b to outPath unless it already exists. Concurrent
+// devgen processes coordinate through a lock file in the .locks directory
+// of the cache, so a plugin is compiled once; the output is written to a
+// temporary file and renamed, so readers never see a partial build.
+// Reusing a build updates its modification time, which CleanCache uses to
+// evict builds that have not been used for a while.
+func (pl *PluginLoader) buildCached(ctx context.Context, b pluginBuild, outPath string) error {
+	if _, err := os.Stat(outPath); err == nil {
+		now := time.Now()
+		_ = os.Chtimes(outPath, now, now)
+		return nil
+	}
+	lockDir := filepath.Join(filepath.Dir(outPath), ".locks")
+	if err := os.MkdirAll(lockDir, 0o755); err != nil {
+		return fmt.Errorf("create cache dir: %w", err)
+	}
+
+	unlock, err := lockFile(filepath.Join(lockDir, filepath.Base(outPath)+".lock"))
+	if err != nil {
+		return fmt.Errorf("lock plugin cache: %w", err)
+	}
+	defer unlock()
+
+	// Another process may have finished the build while we waited
+	if _, err := os.Stat(outPath); err == nil {
+		return nil
+	}
+
+	tmpPath := fmt.Sprintf("%s.%d.tmp%s", strings.TrimSuffix(outPath, filepath.Ext(outPath)), os.Getpid(), filepath.Ext(outPath))
+	defer os.Remove(tmpPath)
+	if err := b.run(ctx, tmpPath); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, outPath)
+}
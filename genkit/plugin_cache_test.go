@@ -0,0 +1,140 @@
+package genkit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBuildKey(t *testing.T) {
+	dir := t.TempDir()
+	writeTestModule(t, dir)
+	loader := NewPluginLoader(t.TempDir())
+	b := modulePluginBuild(PluginTypeExec, dir)
+
+	key := func() string {
+		t.Helper()
+		k, err := loader.buildKey(context.Background(), b)
+		if err != nil {
+			t.Fatalf("buildKey() error = %v", err)
+		}
+		return k
+	}
+	base := key()
+
+	// Touching files or adding tests must not invalidate the cache
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "main.go"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main_test.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := key(); got != base {
+		t.Errorf("buildKey() changed without content changes: %s != %s", got, base)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "go.sum"), []byte("example.com/x v1.0.0 h1:x=\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	withSum := key()
+	if withSum == base {
+		t.Error("buildKey() did not change with go.sum")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "extra.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := key(); got == withSum {
+		t.Error("buildKey() did not change with a new source file")
+	}
+
+	if wasm, _ := loader.buildKey(context.Background(), modulePluginBuild(PluginTypeWasm, dir)); wasm == key() {
+		t.Error("buildKey() did not change with the build environment")
+	}
+
+	// Local packages imported by the plugin are part of the key, files
+	// excluded from the build are not
+	utilDir := filepath.Join(dir, "internal", "util")
+	if err := os.MkdirAll(utilDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(utilDir, "util.go"), []byte("package util\n\nconst N = 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	main := "package main\n\nimport _ \"" + testModulePath + "/internal/util\"\n\nfunc main() {}\n"
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(main), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	withUtil := key()
+	if err := os.WriteFile(filepath.Join(utilDir, "util.go"), []byte("package util\n\nconst N = 2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	changed := key()
+	if changed == withUtil {
+		t.Error("buildKey() did not change with an imported local package")
+	}
+	if err := os.WriteFile(filepath.Join(utilDir, "util_ignored.go"), []byte("//go:build ignore\n\npackage util\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := key(); got != changed {
+		t.Error("buildKey() changed with a file excluded from the build")
+	}
+}
+
+func TestBuildCachedConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	writeTestModule(t, dir)
+	cacheDir := t.TempDir()
+	outPath := filepath.Join(cacheDir, "modgen.exe")
+
+	// Separate loaders stand in for separate devgen processes
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = NewPluginLoader(cacheDir).buildCached(context.Background(), modulePluginBuild(PluginTypeExec, dir), outPath)
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			t.Fatalf("buildCached() error = %v", err)
+		}
+	}
+
+	info, err := os.Stat(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := os.ReadDir(cacheDir)
+	for _, e := range entries {
+		if e.Name() != "modgen.exe" && e.Name() != ".locks" {
+			t.Errorf("unexpected file in cache: %s", e.Name())
+		}
+	}
+
+	// An existing build is reused and marked as recently used
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(outPath, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewPluginLoader(cacheDir).buildCached(context.Background(), modulePluginBuild(PluginTypeExec, dir), outPath); err != nil {
+		t.Fatal(err)
+	}
+	again, err := os.Stat(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !os.SameFile(info, again) {
+		t.Error("buildCached() rebuilt an existing plugin")
+	}
+	if !again.ModTime().After(old) {
+		t.Error("buildCached() did not update the modification time of a reused plugin")
+	}
+}
@@ -0,0 +1,250 @@
+package genkit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+// pluginTransport sends an encoded request to a plugin and returns its
+// encoded response.
+type pluginTransport func(ctx context.Context, input []byte) ([]byte, error)
+
+// protocolTool is a Tool backed by a plugin that speaks the JSON plugin
+// protocol, either as an executable or as a WebAssembly module. The plugin
+// is started once per request, so it needs no Go toolchain or dependency
+// versions in common with devgen.
+type protocolTool struct {
+	// ctx is the context the plugin was loaded with. Run and Validate
+	// requests are cancelled with it, since Tool has no context of its own.
+	ctx  context.Context
+	name string
+	// tool is the name sent with every request to a plugin serving a tool
+	// set; it is empty for single-tool plugins.
+	tool      string
+	transport pluginTransport
+	// timeout limits run and validate requests; zero means no limit.
+	timeout time.Duration
+	desc    *PluginResponse
+	options map[string]any
+}
+
+// newProtocolTool asks a single-tool plugin to describe itself.
+func newProtocolTool(ctx context.Context, name string, transport pluginTransport) (*protocolTool, error) {
+	t := &protocolTool{ctx: ctx, name: name, transport: transport}
+	desc, err := t.call(ctx, &PluginRequest{Method: PluginMethodDescribe})
+	if err != nil {
+		return nil, err
+	}
+	if len(desc.Tools) > 0 {
+		return nil, fmt.Errorf("plugin %s: plugin serves a tool set (%s)", name, strings.Join(desc.Tools, ", "))
+	}
+	if err := t.setDescription(desc); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// newProtocolTools asks the plugin to describe itself. A single-tool
+// plugin becomes one tool named after the plugin; every tool of a tool
+// set is described separately and keeps its own name. Describe requests
+// are not limited by timeout.
+func newProtocolTools(ctx context.Context, plugin string, transport pluginTransport, timeout time.Duration) ([]Tool, error) {
+	probe := &protocolTool{ctx: ctx, name: plugin, transport: transport, timeout: timeout}
+	desc, err := probe.call(ctx, &PluginRequest{Method: PluginMethodDescribe})
+	if err != nil {
+		return nil, err
+	}
+	if len(desc.Tools) == 0 {
+		if err := probe.setDescription(desc); err != nil {
+			return nil, err
+		}
+		return []Tool{probe}, nil
+	}
+
+	tools := make([]Tool, 0, len(desc.Tools))
+	for _, name := range desc.Tools {
+		t := &protocolTool{ctx: ctx, name: name, tool: name, transport: transport, timeout: timeout}
+		desc, err := t.call(ctx, &PluginRequest{Method: PluginMethodDescribe})
+		if err != nil {
+			return nil, fmt.Errorf("plugin %s: %w", plugin, err)
+		}
+		if err := t.setDescription(desc); err != nil {
+			return nil, fmt.Errorf("plugin %s: %w", plugin, err)
+		}
+		tools = append(tools, t)
+	}
+	return tools, nil
+}
+
+// setDescription checks the describe response of the tool and stores it.
+func (t *protocolTool) setDescription(desc *PluginResponse) error {
+	if desc.Name != "" && desc.Name != t.name {
+		return fmt.Errorf("plugin %s: plugin reports name %q", t.name, desc.Name)
+	}
+	if err := checkPluginVersion(t.name, desc.Requires); err != nil {
+		return err
+	}
+	t.desc = desc
+	return nil
+}
+
+// loadExecPlugin starts the plugin executable to describe itself.
+func (pl *PluginLoader) loadExecPlugin(ctx context.Context, cfg PluginConfig) ([]Tool, error) {
+	path, err := exec.LookPath(cfg.Path)
+	if err != nil {
+		return nil, fmt.Errorf("plugin executable not found: %s", cfg.Path)
+	}
+	timeout, err := pluginTimeout(cfg, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return newProtocolTools(ctx, cfg.Name, func(ctx context.Context, input []byte) ([]byte, error) {
+		var stdout, stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, path)
+		cmd.Stdin = bytes.NewReader(input)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, fmt.Errorf("%w\n%s", err, strings.TrimSpace(stderr.String()))
+		}
+		return stdout.Bytes(), nil
+	}, timeout)
+}
+
+// pluginTimeout returns the request timeout configured for a plugin, or
+// fallback if none is configured.
+func pluginTimeout(cfg PluginConfig, fallback time.Duration) (time.Duration, error) {
+	if cfg.Timeout == "" {
+		return fallback, nil
+	}
+	timeout, err := time.ParseDuration(cfg.Timeout)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("invalid timeout %q", cfg.Timeout)
+	}
+	return timeout, nil
+}
+
+// Name implements Tool.
+func (t *protocolTool) Name() string { return t.name }
+
+// Run implements Tool.
+func (t *protocolTool) Run(gen *Generator, log *Logger) error {
+	resp, err := t.call(t.ctx, &PluginRequest{
+		Method:       PluginMethodRun,
+		IncludeTests: gen.IncludeTests(),
+		Packages:     EncodePackages(gen.Packages),
+		Options:      t.options,
+	})
+	if resp != nil && resp.Log != "" {
+		_, _ = log.w.Write([]byte(resp.Log))
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, f := range resp.Files {
+		gf := gen.NewGeneratedFile(f.Path, "")
+		_, _ = gf.Write([]byte(f.Content))
+	}
+	return nil
+}
+
+// Config implements ConfigurableTool.
+func (t *protocolTool) Config() ToolConfig {
+	if t.desc.Config == nil {
+		return ToolConfig{}
+	}
+	return *t.desc.Config
+}
+
+// Validate implements ValidatableTool.
+func (t *protocolTool) Validate(gen *Generator, log *Logger) []Diagnostic {
+	if !t.desc.HasCapability(PluginCapabilityValidate) {
+		return nil
+	}
+	resp, err := t.call(t.ctx, &PluginRequest{
+		Method:       PluginMethodValidate,
+		IncludeTests: gen.IncludeTests(),
+		Packages:     EncodePackages(gen.Packages),
+		Options:      t.options,
+	})
+	if resp != nil && resp.Log != "" {
+		_, _ = log.w.Write([]byte(resp.Log))
+	}
+	if err != nil {
+		return []Diagnostic{{Severity: DiagnosticError, Message: err.Error(), Tool: t.name}}
+	}
+	return resp.Diagnostics
+}
+
+// OptionsSchema implements OptionsTool.
+func (t *protocolTool) OptionsSchema() []OptionSpec {
+	return t.desc.OptionsSchema
+}
+
+// SetOptions implements OptionsTool.
+// The options are sent to the plugin with every run and validate request.
+func (t *protocolTool) SetOptions(opts map[string]any) error {
+	t.options = opts
+	return nil
+}
+
+// GenkitVersion implements VersionedTool.
+func (t *protocolTool) GenkitVersion() string {
+	return t.desc.Requires
+}
+
+// Rules implements RuleTool.
+func (t *protocolTool) Rules() []Rule {
+	return t.desc.Rules
+}
+
+// call sends req to the plugin and decodes its response.
+// The response is returned together with the error when the plugin
+// reported one, so its log output is not lost.
+func (t *protocolTool) call(ctx context.Context, req *PluginRequest) (*PluginResponse, error) {
+	req.Version = PluginProtocolVersion
+	req.Tool = t.tool
+	input, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("encode request: %w", err)
+	}
+
+	if t.timeout > 0 && req.Method != PluginMethodDescribe {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.timeout)
+		defer cancel()
+	}
+	output, err := t.transport(ctx, input)
+	if err != nil {
+		if t.timeout > 0 && errors.Is(err, context.DeadlineExceeded) {
+			err = fmt.Errorf("timed out after %s: %w", t.timeout, err)
+		}
+		return nil, fmt.Errorf("plugin %s %s: %w", t.name, req.Method, err)
+	}
+
+	var resp PluginResponse
+	if err := json.Unmarshal(output, &resp); err != nil {
+		return nil, fmt.Errorf("plugin %s %s: invalid response: %w", t.name, req.Method, err)
+	}
+	if resp.Version != PluginProtocolVersion {
+		return nil, fmt.Errorf("plugin %s speaks protocol version %d, devgen supports version %d",
+			t.name, resp.Version, PluginProtocolVersion)
+	}
+	if resp.Error != "" {
+		return &resp, fmt.Errorf("plugin %s: %s", t.name, resp.Error)
+	}
+	sort.Slice(resp.Files, func(i, j int) bool { return resp.Files[i].Path < resp.Files[j].Path })
+	return &resp, nil
+}
@@ -0,0 +1,185 @@
+package genkit
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// PluginInfo describes where a configured plugin comes from and where its
+// build is cached.
+type PluginInfo struct {
+	// Name is the plugin name from devgen.toml.
+	Name string `json:"name"`
+
+	// Type is the plugin type; "source" if not configured.
+	Type PluginType `json:"type"`
+
+	// Path is the resolved plugin path: the source directory or file, the
+	// prebuilt .so, executable or .wasm module, or the package directory
+	// of a module plugin.
+	Path string `json:"path"`
+
+	// Module and Version identify a module plugin. Version is empty for
+	// modules replaced by a local directory.
+	Module  string `json:"module,omitempty"`
+	Version string `json:"version,omitempty"`
+
+	// CachePath is the cached build of source and module plugins.
+	// It is empty for prebuilt plugins.
+	CachePath string `json:"cache_path,omitempty"`
+
+	// BuiltAt is the modification time of the cached build, or zero if
+	// the plugin has not been built yet. Reusing a build updates it.
+	BuiltAt time.Time `json:"built_at,omitzero"`
+}
+
+// CacheDir returns the directory of the plugin build cache.
+func (pl *PluginLoader) CacheDir() string {
+	return pl.cacheDir
+}
+
+// InspectPlugin resolves a plugin and its cache location without building
+// or loading it. Module plugins may still be downloaded.
+func (pl *PluginLoader) InspectPlugin(ctx context.Context, cfg PluginConfig) (*PluginInfo, error) {
+	info, _, err := pl.resolvePlugin(ctx, cfg)
+	return info, err
+}
+
+// BuildPlugin builds a source or module plugin into the cache without
+// loading it, e.g. to warm the cache in CI. Prebuilt plugins are only
+// resolved.
+func (pl *PluginLoader) BuildPlugin(ctx context.Context, cfg PluginConfig) (*PluginInfo, error) {
+	info, _, err := pl.buildPlugin(ctx, cfg)
+	return info, err
+}
+
+// buildPlugin resolves a plugin and builds it into the cache if needed.
+// The returned build is nil for prebuilt plugins.
+func (pl *PluginLoader) buildPlugin(ctx context.Context, cfg PluginConfig) (*PluginInfo, *pluginBuild, error) {
+	info, b, err := pl.resolvePlugin(ctx, cfg)
+	if err != nil || b == nil {
+		return info, b, err
+	}
+
+	if err := pl.buildCached(ctx, *b, info.CachePath); err != nil {
+		return nil, nil, fmt.Errorf("build plugin %s: %w", cfg.Name, err)
+	}
+	if st, err := os.Stat(info.CachePath); err == nil {
+		info.BuiltAt = st.ModTime()
+	}
+	return info, b, nil
+}
+
+// resolvePlugin returns the info of a plugin and, for plugins built by
+// devgen, how to build it. Source plugins are checked against their
+// declared genkit version first, so an incompatible plugin fails with a
+// clear message instead of a go list or build error.
+func (pl *PluginLoader) resolvePlugin(ctx context.Context, cfg PluginConfig) (*PluginInfo, *pluginBuild, error) {
+	info := &PluginInfo{Name: cfg.Name, Type: cfg.Type, Module: cfg.Module}
+	if info.Type == "" {
+		info.Type = PluginTypeSource
+	}
+
+	var b pluginBuild
+	switch {
+	case cfg.IsModule():
+		if info.Type == PluginTypePlugin {
+			return nil, nil, fmt.Errorf("module plugins must be of type source, exec or wasm, got %s", cfg.Type)
+		}
+		mod, err := pl.ResolveModule(ctx, cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		info.Path = mod.Dir
+		if cfg.Path != "" {
+			info.Path = filepath.Join(mod.Dir, filepath.FromSlash(cfg.Path))
+		}
+		info.Version = mod.Version
+		b = modulePluginBuild(info.Type, info.Path)
+
+	case info.Type == PluginTypeSource:
+		srcPath, err := filepath.Abs(cfg.Path)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, err := os.Stat(srcPath); err != nil {
+			return nil, nil, fmt.Errorf("source path not found: %s", cfg.Path)
+		}
+		info.Path = srcPath
+		b = pluginBuild{typ: PluginTypeSource, src: srcPath}
+
+	case info.Type == PluginTypeExec:
+		info.Path = cfg.Path
+		if path, err := exec.LookPath(cfg.Path); err == nil {
+			info.Path = path
+		}
+		return info, nil, nil
+
+	default:
+		info.Path = cfg.Path
+		return info, nil, nil
+	}
+
+	if b.typ == PluginTypeSource {
+		constraint, err := sourceGenkitVersion(b.src)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := checkPluginVersion(cfg.Name, constraint); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	key, err := pl.buildKey(ctx, b)
+	if err != nil {
+		return nil, nil, err
+	}
+	name := fmt.Sprintf("%s_%s%s", cfg.Name, key, pluginExt(info.Type))
+	if cfg.IsModule() {
+		// Show the module version in the file name; "local" for replacements
+		version := info.Version
+		if version == "" {
+			version = "local"
+		}
+		name = fmt.Sprintf("%s@%s_%s%s", cfg.Name, version, key, pluginExt(info.Type))
+	}
+	info.CachePath = filepath.Join(pl.cacheDir, name)
+	if st, err := os.Stat(info.CachePath); err == nil {
+		info.BuiltAt = st.ModTime()
+	}
+	return info, &b, nil
+}
+
+// ToolInterfaces returns the names of the optional genkit interfaces tool
+// implements, e.g. "ConfigurableTool" or "RuleTool". Exec and wasm plugins
+// report the capabilities they declared.
+func ToolInterfaces(tool Tool) []string {
+	has := func(capability string) bool { return true }
+	requires := func() bool { return true }
+	if pt, ok := tool.(*protocolTool); ok {
+		has = pt.desc.HasCapability
+		requires = func() bool { return pt.desc.Requires != "" }
+	}
+
+	var names []string
+	if _, ok := tool.(ConfigurableTool); ok && has(PluginCapabilityConfig) {
+		names = append(names, "ConfigurableTool")
+	}
+	if _, ok := tool.(ValidatableTool); ok && has(PluginCapabilityValidate) {
+		names = append(names, "ValidatableTool")
+	}
+	if _, ok := tool.(RuleTool); ok && has(PluginCapabilityRules) {
+		names = append(names, "RuleTool")
+	}
+	if _, ok := tool.(OptionsTool); ok && has(PluginCapabilityOptions) {
+		names = append(names, "OptionsTool")
+	}
+	if _, ok := tool.(VersionedTool); ok && requires() {
+		names = append(names, "VersionedTool")
+	}
+	return names
+}
@@ -0,0 +1,92 @@
+package genkit
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBuildAndInspectPlugin(t *testing.T) {
+	project := t.TempDir()
+	writeTestModule(t, filepath.Join(project, "modgen"))
+	gomod := "module example.com/project\n\ngo 1.21\n\nreplace " + testModulePath + " => ./modgen\n"
+	if err := os.WriteFile(filepath.Join(project, "go.mod"), []byte(gomod), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	loader := NewPluginLoader(t.TempDir())
+	cfg := PluginConfig{Name: "modgen", Module: testModulePath, Type: PluginTypeExec, dir: project}
+	ctx := context.Background()
+
+	info, err := loader.InspectPlugin(ctx, cfg)
+	if err != nil {
+		t.Fatalf("InspectPlugin() error = %v", err)
+	}
+	if info.Path != filepath.Join(project, "modgen") || filepath.Dir(info.CachePath) != loader.CacheDir() || !info.BuiltAt.IsZero() {
+		t.Errorf("InspectPlugin() before build = %+v", info)
+	}
+
+	built, err := loader.BuildPlugin(ctx, cfg)
+	if err != nil {
+		t.Fatalf("BuildPlugin() error = %v", err)
+	}
+	if built.CachePath != info.CachePath || built.BuiltAt.IsZero() {
+		t.Errorf("BuildPlugin() = %+v", built)
+	}
+	if info, _ := loader.InspectPlugin(ctx, cfg); !info.BuiltAt.Equal(built.BuiltAt) {
+		t.Errorf("InspectPlugin() after build BuiltAt = %v, want %v", info.BuiltAt, built.BuiltAt)
+	}
+
+	// Prebuilt plugins have no cache entry
+	prebuilt, err := loader.BuildPlugin(ctx, PluginConfig{Name: "prebuilt", Path: "gen.so", Type: PluginTypePlugin})
+	if err != nil || prebuilt.CachePath != "" || prebuilt.Path != "gen.so" {
+		t.Errorf("BuildPlugin() prebuilt = %+v, %v", prebuilt, err)
+	}
+
+	if err := loader.CleanCache(time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(built.CachePath); err != nil {
+		t.Errorf("CleanCache(1h) removed a fresh build: %v", err)
+	}
+	if err := loader.CleanCache(0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(built.CachePath); !os.IsNotExist(err) {
+		t.Errorf("CleanCache(0) kept %s", built.CachePath)
+	}
+}
+
+func TestToolInterfaces(t *testing.T) {
+	transport := func(desc PluginResponse) pluginTransport {
+		return func(context.Context, []byte) ([]byte, error) {
+			desc.Version = PluginProtocolVersion
+			desc.Name = "mygen"
+			return json.Marshal(&desc)
+		}
+	}
+
+	tool, err := newProtocolTool(context.Background(), "mygen", transport(PluginResponse{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ToolInterfaces(tool); len(got) != 0 {
+		t.Errorf("ToolInterfaces() without capabilities = %v", got)
+	}
+
+	tool, err = newProtocolTool(context.Background(), "mygen", transport(PluginResponse{
+		Capabilities: []string{PluginCapabilityConfig, PluginCapabilityRules},
+		Requires:     ">= v0.1",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"ConfigurableTool", "RuleTool", "VersionedTool"}
+	if got := ToolInterfaces(tool); !reflect.DeepEqual(got, want) {
+		t.Errorf("ToolInterfaces() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,316 @@
+package genkit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+// PluginLockFile is the name of the lockfile written by "devgen plugins lock"
+// next to devgen.toml.
+const PluginLockFile = "devgen.lock"
+
+// PluginLock pins the resolved versions and checksums of module plugins.
+type PluginLock struct {
+	Plugins []LockedPlugin `toml:"plugins"`
+}
+
+// LockedPlugin is the locked state of a single module plugin.
+type LockedPlugin struct {
+	// Name is the plugin name from devgen.toml.
+	Name string `toml:"name"`
+
+	// Module is the module path.
+	Module string `toml:"module"`
+
+	// Requested is the version requested in devgen.toml, e.g. "latest" or "v1".
+	// It is omitted when it equals Version.
+	Requested string `toml:"requested,omitempty"`
+
+	// Version is the resolved module version.
+	Version string `toml:"version,omitempty"`
+
+	// Sum is the go.sum hash of the module content.
+	Sum string `toml:"sum,omitempty"`
+
+	// Replace is the local directory the module is replaced with in go.mod.
+	// Local replacements have no version or checksum.
+	Replace string `toml:"replace,omitempty"`
+}
+
+// ResolvedModule is a plugin module resolved to a directory on disk.
+type ResolvedModule struct {
+	// Path is the module path.
+	Path string `json:"path"`
+
+	// Version is the resolved version, empty for local replacements.
+	Version string `json:"version,omitempty"`
+
+	// Sum is the go.sum hash of the module content, empty for local replacements.
+	Sum string `json:"sum,omitempty"`
+
+	// Dir is the module root directory.
+	Dir string `json:"dir"`
+
+	// Replace is the local replacement path from go.mod, if any.
+	Replace string `json:"replace,omitempty"`
+}
+
+// LoadPluginLock reads the lockfile next to the given devgen.toml.
+// A missing lockfile is not an error.
+func LoadPluginLock(configPath string) (*PluginLock, error) {
+	path := filepath.Join(filepath.Dir(configPath), PluginLockFile)
+	var lock PluginLock
+	if _, err := toml.DecodeFile(path, &lock); err != nil {
+		if os.IsNotExist(err) {
+			return &PluginLock{}, nil
+		}
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return &lock, nil
+}
+
+// WritePluginLock writes the lockfile next to the given devgen.toml.
+func WritePluginLock(configPath string, lock *PluginLock) error {
+	var buf bytes.Buffer
+	buf.WriteString("# Code generated by devgen plugins lock. DO NOT EDIT.\n\n")
+	if err := toml.NewEncoder(&buf).Encode(lock); err != nil {
+		return fmt.Errorf("encode lockfile: %w", err)
+	}
+	path := filepath.Join(filepath.Dir(configPath), PluginLockFile)
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// Find returns the locked entry for a plugin, or nil.
+func (l *PluginLock) Find(name string) *LockedPlugin {
+	if l == nil {
+		return nil
+	}
+	for i := range l.Plugins {
+		if l.Plugins[i].Name == name {
+			return &l.Plugins[i]
+		}
+	}
+	return nil
+}
+
+// matches reports whether the locked entry applies to cfg.
+func (lp *LockedPlugin) matches(cfg PluginConfig) bool {
+	if lp == nil || lp.Module != cfg.Module {
+		return false
+	}
+	requested := lp.Requested
+	if requested == "" {
+		requested = lp.Version
+	}
+	return cfg.Version == "" || cfg.Version == requested
+}
+
+// IsModule reports whether the plugin is referenced by module path.
+func (c *PluginConfig) IsModule() bool {
+	return c.Module != ""
+}
+
+// ResolveModule resolves a module plugin to a directory. It honours a
+// replace directive in the project's go.mod, the version pinned in
+// devgen.lock and otherwise downloads the module through the Go module
+// cache and GOPROXY. Without a version, the version required by the
+// project's go.mod is used.
+func (pl *PluginLoader) ResolveModule(ctx context.Context, cfg PluginConfig) (*ResolvedModule, error) {
+	if err := module.CheckPath(cfg.Module); err != nil {
+		return nil, fmt.Errorf("invalid module path: %w", err)
+	}
+
+	gomod, gomodDir, err := findGoMod(cfg.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	version := cfg.Version
+	if gomod != nil {
+		for _, r := range gomod.Replace {
+			if r.Old.Path != cfg.Module || (r.Old.Version != "" && r.Old.Version != version) {
+				continue
+			}
+			if r.New.Version == "" {
+				dir := r.New.Path
+				if !filepath.IsAbs(dir) {
+					dir = filepath.Join(gomodDir, dir)
+				}
+				return &ResolvedModule{Path: cfg.Module, Dir: dir, Replace: r.New.Path}, nil
+			}
+			resolved, err := downloadModule(ctx, pl.cacheDir, r.New.Path, r.New.Version)
+			if err != nil {
+				return nil, err
+			}
+			resolved.Path = cfg.Module
+			return resolved, nil
+		}
+		if version == "" {
+			for _, r := range gomod.Require {
+				if r.Mod.Path == cfg.Module {
+					version = r.Mod.Version
+				}
+			}
+		}
+	}
+	if version == "" {
+		return nil, fmt.Errorf("no version for module %s: set version in devgen.toml or require it in go.mod", cfg.Module)
+	}
+
+	var wantSum string
+	if locked := cfg.locked; locked.matches(cfg) && locked.Version != "" {
+		version, wantSum = locked.Version, locked.Sum
+	}
+
+	resolved, err := downloadModule(ctx, pl.cacheDir, cfg.Module, version)
+	if err != nil {
+		return nil, err
+	}
+	if wantSum != "" && resolved.Sum != wantSum {
+		return nil, fmt.Errorf("checksum mismatch for %s@%s: %s has %s, downloaded %s",
+			cfg.Module, resolved.Version, PluginLockFile, wantSum, resolved.Sum)
+	}
+	return resolved, nil
+}
+
+// downloadModule downloads module@version into the module cache.
+// It runs outside any main module so the project's go.mod and go.sum are
+// left untouched.
+func downloadModule(ctx context.Context, workDir, path, version string) (*ResolvedModule, error) {
+	if err := os.MkdirAll(workDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create cache dir: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "go", "mod", "download", "-json", path+"@"+version)
+	cmd.Dir = workDir
+	cmd.Env = append(os.Environ(), "GO111MODULE=on", "GOWORK=off")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	runErr := cmd.Run()
+
+	var info struct {
+		Path    string
+		Version string
+		Error   string
+		Dir     string
+		Sum     string
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &info); err != nil {
+		if runErr != nil {
+			return nil, fmt.Errorf("go mod download %s@%s: %w\n%s", path, version, runErr, strings.TrimSpace(stderr.String()))
+		}
+		return nil, fmt.Errorf("go mod download %s@%s: %w", path, version, err)
+	}
+	if info.Error != "" {
+		return nil, fmt.Errorf("go mod download %s@%s: %s", path, version, info.Error)
+	}
+	if runErr != nil {
+		return nil, fmt.Errorf("go mod download %s@%s: %w\n%s", path, version, runErr, strings.TrimSpace(stderr.String()))
+	}
+	return &ResolvedModule{Path: info.Path, Version: info.Version, Sum: info.Sum, Dir: info.Dir}, nil
+}
+
+// findGoMod parses the go.mod of the project containing dir.
+// It returns nil if there is none.
+func findGoMod(dir string) (*modfile.File, string, error) {
+	if dir == "" {
+		var err error
+		if dir, err = os.Getwd(); err != nil {
+			return nil, "", err
+		}
+	}
+	for {
+		path := filepath.Join(dir, "go.mod")
+		data, err := os.ReadFile(path)
+		if err == nil {
+			f, err := modfile.Parse(path, data, nil)
+			if err != nil {
+				return nil, "", err
+			}
+			return f, dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, "", nil
+		}
+		dir = parent
+	}
+}
+
+// loadModulePlugin resolves a module plugin, builds it into the cache
+// directory according to its type and loads the result.
+func (pl *PluginLoader) loadModulePlugin(ctx context.Context, cfg PluginConfig) ([]Tool, error) {
+	info, _, err := pl.buildPlugin(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	built := cfg
+	built.Path = info.CachePath
+	switch info.Type {
+	case PluginTypeExec:
+		return pl.loadExecPlugin(ctx, built)
+	case PluginTypeWasm:
+		return pl.loadWasmPlugin(ctx, built)
+	default:
+		return pl.loadGoPluginFile(info.CachePath, cfg.Name)
+	}
+}
+
+// modulePluginBuild returns the build of a module plugin. It runs in the
+// module directory without any workspace, so the module's own go.mod is used.
+func modulePluginBuild(typ PluginType, srcDir string) pluginBuild {
+	return pluginBuild{typ: typ, src: srcDir, env: []string{"GOWORK=off"}}
+}
+
+// pluginExt returns the file extension of a built plugin of the given type.
+func pluginExt(typ PluginType) string {
+	switch typ {
+	case PluginTypeExec:
+		return ".exe"
+	case PluginTypeWasm:
+		return ".wasm"
+	default:
+		return ".so"
+	}
+}
+
+// LockPlugins resolves every module plugin in cfg and returns the lock
+// state. Plugins referenced by local path are skipped.
+func (pl *PluginLoader) LockPlugins(ctx context.Context, cfg *Config) (*PluginLock, error) {
+	lock := &PluginLock{}
+	for _, p := range cfg.Plugins {
+		if !p.IsModule() {
+			continue
+		}
+		// Resolve the requested version, not the previously locked one
+		p.locked = nil
+		mod, err := pl.ResolveModule(ctx, p)
+		if err != nil {
+			return nil, fmt.Errorf("resolve plugin %s: %w", p.Name, err)
+		}
+		entry := LockedPlugin{
+			Name:    p.Name,
+			Module:  p.Module,
+			Version: mod.Version,
+			Sum:     mod.Sum,
+			Replace: mod.Replace,
+		}
+		if p.Version != "" && p.Version != mod.Version {
+			entry.Requested = p.Version
+		}
+		lock.Plugins = append(lock.Plugins, entry)
+	}
+	return lock, nil
+}
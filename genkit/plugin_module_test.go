@@ -0,0 +1,180 @@
+package genkit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/zip"
+)
+
+const testModulePath = "example.com/gen/modgen"
+
+// testModulePlugin is an exec plugin without dependencies that only
+// answers describe requests.
+const testModulePlugin = `package main
+
+import (
+	"encoding/json"
+	"os"
+)
+
+func main() {
+	var req map[string]any
+	_ = json.NewDecoder(os.Stdin).Decode(&req)
+	_ = json.NewEncoder(os.Stdout).Encode(map[string]any{"version": 1, "name": "modgen"})
+}
+`
+
+func writeTestModule(t *testing.T, dir string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"go.mod":  "module " + testModulePath + "\n\ngo 1.21\n",
+		"main.go": testModulePlugin,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// setupModuleProxy serves the test module as v1.0.0 from a file GOPROXY
+// and isolates the module cache.
+func setupModuleProxy(t *testing.T) {
+	t.Helper()
+	src := filepath.Join(t.TempDir(), "src")
+	writeTestModule(t, src)
+
+	proxy := t.TempDir()
+	vdir := filepath.Join(proxy, testModulePath, "@v")
+	if err := os.MkdirAll(vdir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	zf, err := os.Create(filepath.Join(vdir, "v1.0.0.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := zip.CreateFromDir(zf, module.Version{Path: testModulePath, Version: "v1.0.0"}, src); err != nil {
+		t.Fatal(err)
+	}
+	_ = zf.Close()
+	for name, content := range map[string]string{
+		"list":        "v1.0.0\n",
+		"v1.0.0.info": `{"Version":"v1.0.0","Time":"2026-01-01T00:00:00Z"}`,
+		"v1.0.0.mod":  "module " + testModulePath + "\n\ngo 1.21\n",
+		"latest.info": `{"Version":"v1.0.0","Time":"2026-01-01T00:00:00Z"}`,
+	} {
+		if err := os.WriteFile(filepath.Join(vdir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Setenv("GOPROXY", "file://"+filepath.ToSlash(proxy))
+	t.Setenv("GOSUMDB", "off")
+	t.Setenv("GOMODCACHE", t.TempDir())
+	t.Setenv("GOFLAGS", "-modcacherw")
+}
+
+func TestModulePluginReplace(t *testing.T) {
+	project := t.TempDir()
+	writeTestModule(t, filepath.Join(project, "modgen"))
+	gomod := "module example.com/project\n\ngo 1.21\n\nreplace " + testModulePath + " => ./modgen\n"
+	if err := os.WriteFile(filepath.Join(project, "go.mod"), []byte(gomod), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cacheDir := t.TempDir()
+	loader := NewPluginLoader(cacheDir)
+	cfg := PluginConfig{Name: "modgen", Module: testModulePath, Type: PluginTypeExec, dir: project}
+
+	mod, err := loader.ResolveModule(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("ResolveModule() error = %v", err)
+	}
+	if mod.Dir != filepath.Join(project, "modgen") || mod.Version != "" || mod.Replace != "./modgen" {
+		t.Errorf("ResolveModule() = %+v", mod)
+	}
+
+	tool, err := loader.LoadPlugin(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("LoadPlugin() error = %v", err)
+	}
+	if tool.Name() != "modgen" {
+		t.Errorf("Name() = %q", tool.Name())
+	}
+	if built, _ := filepath.Glob(filepath.Join(cacheDir, "modgen@local_*")); len(built) != 1 {
+		t.Errorf("built plugins = %v, want one local build", built)
+	}
+}
+
+func TestModulePluginProxyAndLock(t *testing.T) {
+	setupModuleProxy(t)
+
+	project := t.TempDir()
+	if err := os.WriteFile(filepath.Join(project, "go.mod"), []byte("module example.com/project\n\ngo 1.21\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	configPath := filepath.Join(project, "devgen.toml")
+	config := "[[plugins]]\nname = \"modgen\"\nmodule = \"" + testModulePath + "\"\nversion = \"latest\"\ntype = \"exec\"\n"
+	if err := os.WriteFile(configPath, []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfigFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cacheDir := t.TempDir()
+	loader := NewPluginLoader(cacheDir)
+
+	lock, err := loader.LockPlugins(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("LockPlugins() error = %v", err)
+	}
+	if len(lock.Plugins) != 1 {
+		t.Fatalf("LockPlugins() = %+v", lock)
+	}
+	locked := lock.Plugins[0]
+	if locked.Version != "v1.0.0" || locked.Requested != "latest" || !strings.HasPrefix(locked.Sum, "h1:") {
+		t.Errorf("locked = %+v", locked)
+	}
+	if err := WritePluginLock(configPath, lock); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err = LoadConfigFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tool, err := loader.LoadPlugin(context.Background(), cfg.Plugins[0])
+	if err != nil {
+		t.Fatalf("LoadPlugin() error = %v", err)
+	}
+	if tool.Name() != "modgen" {
+		t.Errorf("Name() = %q", tool.Name())
+	}
+	if built, _ := filepath.Glob(filepath.Join(cacheDir, "modgen@v1.0.0_*")); len(built) != 1 {
+		t.Errorf("built plugins = %v, want a build keyed by version", built)
+	}
+
+	// A tampered checksum must be rejected
+	lock.Plugins[0].Sum = "h1:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="
+	if err := WritePluginLock(configPath, lock); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = LoadConfigFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loader.ResolveModule(context.Background(), cfg.Plugins[0]); err == nil ||
+		!strings.Contains(err.Error(), "checksum mismatch for "+testModulePath+"@v1.0.0") {
+		t.Errorf("ResolveModule() with wrong checksum error = %v", err)
+	}
+}
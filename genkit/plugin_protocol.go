@@ -0,0 +1,304 @@
+package genkit
+
+import (
+	"go/token"
+)
+
+// PluginProtocolVersion is the version of the JSON protocol spoken with
+// out-of-process plugins. It is incremented on incompatible changes.
+const PluginProtocolVersion = 1
+
+// Plugin protocol methods.
+const (
+	// PluginMethodDescribe asks the plugin for its name, configuration,
+	// rules and capabilities. A plugin serving a tool set answers a
+	// describe request without a tool with the names of its tools.
+	PluginMethodDescribe = "describe"
+
+	// PluginMethodRun asks the plugin to generate files for the packages.
+	PluginMethodRun = "run"
+
+	// PluginMethodValidate asks the plugin for diagnostics on the packages.
+	PluginMethodValidate = "validate"
+)
+
+// Plugin capabilities reported in response to PluginMethodDescribe.
+const (
+	PluginCapabilityConfig   = "config"
+	PluginCapabilityValidate = "validate"
+	PluginCapabilityRules    = "rules"
+	PluginCapabilityOptions  = "options"
+)
+
+// PluginRequest is sent by devgen to a plugin on stdin.
+type PluginRequest struct {
+	Version      int           `json:"version"`
+	Method       string        `json:"method"`
+	IncludeTests bool          `json:"includeTests,omitempty"`
+	Packages     []PackageData `json:"packages,omitempty"`
+
+	// Options are the validated tool options from devgen.toml,
+	// sent with run and validate requests.
+	Options map[string]any `json:"options,omitempty"`
+
+	// Tool selects the tool of a plugin serving a tool set.
+	Tool string `json:"tool,omitempty"`
+}
+
+// PluginResponse is written by a plugin to stdout.
+type PluginResponse struct {
+	Version int `json:"version"`
+
+	// Describe results
+	Name string `json:"name,omitempty"`
+	// Tools lists the tools of a tool set, in response to a describe
+	// request without a tool.
+	Tools        []string    `json:"tools,omitempty"`
+	Capabilities []string    `json:"capabilities,omitempty"`
+	Config       *ToolConfig `json:"config,omitempty"`
+	Rules        []Rule      `json:"rules,omitempty"`
+	// OptionsSchema declares the accepted options.
+	OptionsSchema []OptionSpec `json:"optionsSchema,omitempty"`
+	// GenkitVersion is the genkit APIVersion the plugin was built with.
+	GenkitVersion string `json:"genkitVersion,omitempty"`
+	// Requires is the genkit version constraint declared by the tool,
+	// see VersionedTool.
+	Requires string `json:"requires,omitempty"`
+
+	// Run and validate results
+	Files       []PluginFile `json:"files,omitempty"`
+	Diagnostics []Diagnostic `json:"diagnostics,omitempty"`
+
+	// Log is the plugin's log output, replayed by devgen.
+	Log string `json:"log,omitempty"`
+
+	// Error is set when the request failed.
+	Error string `json:"error,omitempty"`
+}
+
+// HasCapability reports whether the plugin declared the capability.
+func (r *PluginResponse) HasCapability(capability string) bool {
+	for _, c := range r.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// PluginFile is a generated file returned by a plugin.
+type PluginFile struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// PackageData is the serialized form of a Package.
+// Syntax trees and type information are not included; plugins work with
+// names, docs, annotations and source positions.
+type PackageData struct {
+	Name       string          `json:"name"`
+	PkgPath    string          `json:"pkgPath"`
+	Dir        string          `json:"dir"`
+	GoFiles    []string        `json:"goFiles,omitempty"`
+	Types      []TypeData      `json:"types,omitempty"`
+	Enums      []EnumData      `json:"enums,omitempty"`
+	Interfaces []InterfaceData `json:"interfaces,omitempty"`
+}
+
+// TypeData is the serialized form of a Type.
+type TypeData struct {
+	Name        string         `json:"name"`
+	Doc         string         `json:"doc,omitempty"`
+	Annotations []*Annotation  `json:"annotations,omitempty"`
+	Fields      []FieldData    `json:"fields,omitempty"`
+	Pos         token.Position `json:"pos"`
+}
+
+// FieldData is the serialized form of a Field.
+type FieldData struct {
+	Name           string         `json:"name"`
+	Type           string         `json:"type"`
+	UnderlyingType string         `json:"underlyingType"`
+	Tag            string         `json:"tag,omitempty"`
+	Doc            string         `json:"doc,omitempty"`
+	Comment        string         `json:"comment,omitempty"`
+	Annotations    []*Annotation  `json:"annotations,omitempty"`
+	Pos            token.Position `json:"pos"`
+}
+
+// EnumData is the serialized form of an Enum.
+type EnumData struct {
+	Name           string          `json:"name"`
+	Doc            string          `json:"doc,omitempty"`
+	Annotations    []*Annotation   `json:"annotations,omitempty"`
+	UnderlyingType string          `json:"underlyingType"`
+	Values         []EnumValueData `json:"values,omitempty"`
+}
+
+// EnumValueData is the serialized form of an EnumValue.
+type EnumValueData struct {
+	Name        string         `json:"name"`
+	Value       string         `json:"value"`
+	Doc         string         `json:"doc,omitempty"`
+	Comment     string         `json:"comment,omitempty"`
+	Annotations []*Annotation  `json:"annotations,omitempty"`
+	Pos         token.Position `json:"pos"`
+}
+
+// InterfaceData is the serialized form of an Interface.
+type InterfaceData struct {
+	Name        string         `json:"name"`
+	Doc         string         `json:"doc,omitempty"`
+	Annotations []*Annotation  `json:"annotations,omitempty"`
+	Methods     []MethodData   `json:"methods,omitempty"`
+	Pos         token.Position `json:"pos"`
+}
+
+// MethodData is the serialized form of a Method.
+type MethodData struct {
+	Name        string         `json:"name"`
+	Doc         string         `json:"doc,omitempty"`
+	Annotations []*Annotation  `json:"annotations,omitempty"`
+	Params      []Param        `json:"params,omitempty"`
+	Results     []Param        `json:"results,omitempty"`
+	Pos         token.Position `json:"pos"`
+}
+
+// EncodePackages converts loaded packages to their serialized form.
+// Annotations are parsed from docs so plugins in any language can use them.
+func EncodePackages(pkgs []*Package) []PackageData {
+	result := make([]PackageData, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		pd := PackageData{
+			Name:    pkg.Name,
+			PkgPath: pkg.PkgPath,
+			Dir:     pkg.Dir,
+			GoFiles: pkg.GoFiles,
+		}
+		for _, t := range pkg.Types {
+			td := TypeData{Name: t.Name, Doc: t.Doc, Annotations: ParseAnnotations(t.Doc)}
+			if t.TypeSpec != nil && pkg.Fset != nil {
+				td.Pos = pkg.Fset.Position(t.TypeSpec.Name.Pos())
+			}
+			for _, f := range t.Fields {
+				td.Fields = append(td.Fields, FieldData{
+					Name:           f.Name,
+					Type:           f.Type,
+					UnderlyingType: f.UnderlyingType,
+					Tag:            f.Tag,
+					Doc:            f.Doc,
+					Comment:        f.Comment,
+					Annotations:    ParseAnnotations(f.Doc + "\n" + f.Comment),
+					Pos:            f.Pos,
+				})
+			}
+			pd.Types = append(pd.Types, td)
+		}
+		for _, e := range pkg.Enums {
+			ed := EnumData{
+				Name:           e.Name,
+				Doc:            e.Doc,
+				Annotations:    ParseAnnotations(e.Doc),
+				UnderlyingType: e.UnderlyingType,
+			}
+			for _, v := range e.Values {
+				ed.Values = append(ed.Values, EnumValueData{
+					Name:        v.Name,
+					Value:       v.Value,
+					Doc:         v.Doc,
+					Comment:     v.Comment,
+					Annotations: ParseAnnotations(v.Doc + "\n" + v.Comment),
+					Pos:         v.Pos,
+				})
+			}
+			pd.Enums = append(pd.Enums, ed)
+		}
+		for _, iface := range pkg.Interfaces {
+			id := InterfaceData{
+				Name:        iface.Name,
+				Doc:         iface.Doc,
+				Annotations: ParseAnnotations(iface.Doc),
+				Pos:         iface.Pos,
+			}
+			for _, m := range iface.Methods {
+				md := MethodData{
+					Name:        m.Name,
+					Doc:         m.Doc,
+					Annotations: ParseAnnotations(m.Doc),
+					Pos:         m.Pos,
+				}
+				for _, p := range m.Params {
+					md.Params = append(md.Params, *p)
+				}
+				for _, r := range m.Results {
+					md.Results = append(md.Results, *r)
+				}
+				id.Methods = append(id.Methods, md)
+			}
+			pd.Interfaces = append(pd.Interfaces, id)
+		}
+		result = append(result, pd)
+	}
+	return result
+}
+
+// DecodePackages converts serialized packages back to Packages.
+// The returned packages have no syntax trees or type information,
+// so Type.TypeSpec, Package.Syntax and Package.TypesInfo are nil.
+func DecodePackages(data []PackageData) []*Package {
+	fset := token.NewFileSet()
+	result := make([]*Package, 0, len(data))
+	for _, pd := range data {
+		pkg := &Package{
+			Name:    pd.Name,
+			PkgPath: pd.PkgPath,
+			Dir:     pd.Dir,
+			GoFiles: pd.GoFiles,
+			Fset:    fset,
+		}
+		for _, td := range pd.Types {
+			t := &Type{Name: td.Name, Doc: td.Doc, Pkg: pkg}
+			for _, fd := range td.Fields {
+				t.Fields = append(t.Fields, &Field{
+					Name:           fd.Name,
+					Type:           fd.Type,
+					UnderlyingType: fd.UnderlyingType,
+					Tag:            fd.Tag,
+					Doc:            fd.Doc,
+					Comment:        fd.Comment,
+					Pos:            fd.Pos,
+				})
+			}
+			pkg.Types = append(pkg.Types, t)
+		}
+		for _, ed := range pd.Enums {
+			e := &Enum{Name: ed.Name, Doc: ed.Doc, Pkg: pkg, UnderlyingType: ed.UnderlyingType}
+			for _, vd := range ed.Values {
+				e.Values = append(e.Values, &EnumValue{
+					Name:    vd.Name,
+					Value:   vd.Value,
+					Doc:     vd.Doc,
+					Comment: vd.Comment,
+					Pos:     vd.Pos,
+				})
+			}
+			pkg.Enums = append(pkg.Enums, e)
+		}
+		for _, id := range pd.Interfaces {
+			iface := &Interface{Name: id.Name, Doc: id.Doc, Pkg: pkg, Pos: id.Pos}
+			for _, md := range id.Methods {
+				m := &Method{Name: md.Name, Doc: md.Doc, Pos: md.Pos}
+				for i := range md.Params {
+					m.Params = append(m.Params, &md.Params[i])
+				}
+				for i := range md.Results {
+					m.Results = append(m.Results, &md.Results[i])
+				}
+				iface.Methods = append(iface.Methods, m)
+			}
+			pkg.Interfaces = append(pkg.Interfaces, iface)
+		}
+		result = append(result, pkg)
+	}
+	return result
+}
@@ -0,0 +1,105 @@
+package genkit
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewProtocolTools(t *testing.T) {
+	var requests []PluginRequest
+	transport := func(_ context.Context, input []byte) ([]byte, error) {
+		var req PluginRequest
+		if err := json.Unmarshal(input, &req); err != nil {
+			return nil, err
+		}
+		requests = append(requests, req)
+		resp := PluginResponse{Version: PluginProtocolVersion, Name: req.Tool}
+		if req.Tool == "" {
+			resp.Tools = []string{"markgen", "docgen"}
+		}
+		return json.Marshal(&resp)
+	}
+
+	tools, err := newProtocolTools(context.Background(), "genset", transport, 0)
+	if err != nil {
+		t.Fatalf("newProtocolTools() error = %v", err)
+	}
+	if got := toolNames(tools); !reflect.DeepEqual(got, []string{"markgen", "docgen"}) {
+		t.Errorf("tools = %v", got)
+	}
+	if len(requests) != 3 || requests[1].Tool != "markgen" || requests[2].Tool != "docgen" {
+		t.Errorf("requests = %+v", requests)
+	}
+
+	if _, err := newProtocolTool(context.Background(), "genset", transport); err == nil ||
+		!strings.Contains(err.Error(), "serves a tool set (markgen, docgen)") {
+		t.Errorf("newProtocolTool() of a tool set error = %v", err)
+	}
+}
+
+func TestSelectTools(t *testing.T) {
+	tools := []Tool{&plainTestTool{name: "markgen"}, &plainTestTool{name: "docgen"}}
+
+	got, err := selectTools(PluginConfig{Name: "genset"}, tools)
+	if err != nil || !reflect.DeepEqual(toolNames(got), []string{"markgen", "docgen"}) {
+		t.Errorf("selectTools() = %v, %v", toolNames(got), err)
+	}
+
+	got, err = selectTools(PluginConfig{Name: "genset", Tools: []string{"docgen", "markgen"}}, tools)
+	if err != nil || !reflect.DeepEqual(toolNames(got), []string{"docgen", "markgen"}) {
+		t.Errorf("selectTools() in configured order = %v, %v", toolNames(got), err)
+	}
+
+	_, err = selectTools(PluginConfig{Name: "genset", Tools: []string{"apigen"}}, tools)
+	if err == nil || !strings.Contains(err.Error(), `plugin genset has no tool "apigen" (available: markgen, docgen)`) {
+		t.Errorf("selectTools() with unknown tool error = %v", err)
+	}
+
+	_, err = selectTools(PluginConfig{Name: "genset"}, append(tools, &plainTestTool{name: "docgen"}))
+	if err == nil || !strings.Contains(err.Error(), "provides tool docgen more than once") {
+		t.Errorf("selectTools() with duplicate tool error = %v", err)
+	}
+}
+
+func TestLoadTools(t *testing.T) {
+	builtin := []Tool{&plainTestTool{name: "enumgen"}, &plainTestTool{name: "validategen"}}
+	newLoader := func() *PluginLoader {
+		pl := NewPluginLoader(t.TempDir())
+		pl.loaded["genset"] = []Tool{&plainTestTool{name: "markgen"}, &plainTestTool{name: "enumgen"}}
+		pl.loaded["other"] = []Tool{&plainTestTool{name: "markgen"}}
+		return pl
+	}
+
+	_, err := newLoader().LoadTools(context.Background(), &Config{Plugins: []PluginConfig{{Name: "genset"}}}, builtin)
+	if err == nil || !strings.Contains(err.Error(), "plugin genset provides tool enumgen, which collides with the built-in tool") ||
+		!strings.Contains(err.Error(), "override_builtin = true") {
+		t.Errorf("LoadTools() with collision error = %v", err)
+	}
+
+	tools, err := newLoader().LoadTools(context.Background(), &Config{Plugins: []PluginConfig{{Name: "genset", OverrideBuiltin: true}}}, builtin)
+	if err != nil {
+		t.Fatalf("LoadTools() with override error = %v", err)
+	}
+	if got := toolNames(tools); !reflect.DeepEqual(got, []string{"markgen", "enumgen", "validategen"}) {
+		t.Errorf("LoadTools() = %v", got)
+	}
+	if tools[1] == builtin[0] {
+		t.Error("LoadTools() kept the overridden built-in tool")
+	}
+
+	tools, err = newLoader().LoadTools(context.Background(), &Config{Plugins: []PluginConfig{{Name: "genset", Tools: []string{"markgen"}}}}, builtin)
+	if err != nil || !reflect.DeepEqual(toolNames(tools), []string{"markgen", "enumgen", "validategen"}) || tools[1] != builtin[0] {
+		t.Errorf("LoadTools() with selection = %v, %v", toolNames(tools), err)
+	}
+
+	_, err = newLoader().LoadTools(context.Background(), &Config{Plugins: []PluginConfig{
+		{Name: "genset", Tools: []string{"markgen"}},
+		{Name: "other"},
+	}}, builtin)
+	if err == nil || !strings.Contains(err.Error(), "tool markgen is provided by plugins genset and other") {
+		t.Errorf("LoadTools() with duplicate plugin tools error = %v", err)
+	}
+}
@@ -0,0 +1,216 @@
+// Package pluginsdk runs a genkit.Tool as an out-of-process devgen plugin.
+//
+// An exec plugin is a regular executable configured with type = "exec" in
+// devgen.toml. devgen writes a genkit.PluginRequest to its stdin and reads a
+// genkit.PluginResponse from its stdout. Unlike source and .so plugins, the
+// executable does not need to be built with the same Go toolchain or
+// dependency versions as devgen.
+//
+// The same program compiled with GOOS=wasip1 GOARCH=wasm can be configured
+// with type = "wasm" and runs sandboxed in devgen's embedded runtime.
+//
+// A plugin main is a few lines:
+//
+//	package main
+//
+//	import "github.com/tlipoca9/devgen/genkit/pluginsdk"
+//
+//	func main() {
+//	    pluginsdk.Main(&MyGenerator{})
+//	}
+//
+// A plugin may also serve a tool set, from which devgen.toml selects tools
+// with tools = [...]:
+//
+//	func main() {
+//	    pluginsdk.Main(&MarkGenerator{}, &DocGenerator{})
+//	}
+//
+// Packages passed to the tool are decoded from the request, so they carry
+// names, docs, fields, enums, interfaces and positions, but no syntax trees
+// or type information.
+package pluginsdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/tlipoca9/devgen/genkit"
+)
+
+// Main serves a single request from stdin and exits. Passing more than one
+// tool serves a tool set.
+// It exits with status 1 if the request cannot be read or answered.
+func Main(tools ...genkit.Tool) {
+	if err := ServeTools(tools, os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", filepath.Base(os.Args[0]), err)
+		os.Exit(1)
+	}
+}
+
+// Serve reads a request from r, runs tool and writes the response to w.
+// Errors returned by the tool are reported in the response, not returned.
+func Serve(tool genkit.Tool, r io.Reader, w io.Writer) error {
+	return ServeTools([]genkit.Tool{tool}, r, w)
+}
+
+// ServeTools is like Serve for a tool set.
+func ServeTools(tools []genkit.Tool, r io.Reader, w io.Writer) error {
+	var req genkit.PluginRequest
+	if err := json.NewDecoder(r).Decode(&req); err != nil {
+		return fmt.Errorf("decode request: %w", err)
+	}
+
+	resp := HandleTools(tools, &req)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		return fmt.Errorf("encode response: %w", err)
+	}
+	return nil
+}
+
+// Handle answers a single request.
+func Handle(tool genkit.Tool, req *genkit.PluginRequest) *genkit.PluginResponse {
+	return HandleTools([]genkit.Tool{tool}, req)
+}
+
+// HandleTools answers a single request to a tool set. A describe request
+// without a tool lists the names of the tools; every other request must
+// name the tool it is for. A set of one tool behaves like a single tool.
+func HandleTools(tools []genkit.Tool, req *genkit.PluginRequest) *genkit.PluginResponse {
+	resp := &genkit.PluginResponse{Version: genkit.PluginProtocolVersion}
+	if req.Version != genkit.PluginProtocolVersion {
+		resp.Error = fmt.Sprintf("unsupported protocol version %d, plugin supports version %d",
+			req.Version, genkit.PluginProtocolVersion)
+		return resp
+	}
+
+	var tool genkit.Tool
+	switch {
+	case req.Tool != "":
+		for _, t := range tools {
+			if t.Name() == req.Tool {
+				tool = t
+				break
+			}
+		}
+		if tool == nil {
+			resp.Error = fmt.Sprintf("unknown tool %q", req.Tool)
+			return resp
+		}
+	case len(tools) == 1:
+		tool = tools[0]
+	case req.Method == genkit.PluginMethodDescribe:
+		for _, t := range tools {
+			resp.Tools = append(resp.Tools, t.Name())
+		}
+		return resp
+	default:
+		resp.Error = fmt.Sprintf("%s request to a tool set must name a tool", req.Method)
+		return resp
+	}
+
+	switch req.Method {
+	case genkit.PluginMethodDescribe:
+		describe(tool, resp)
+	case genkit.PluginMethodRun:
+		run(tool, req, resp)
+	case genkit.PluginMethodValidate:
+		validate(tool, req, resp)
+	default:
+		resp.Error = fmt.Sprintf("unknown method %q", req.Method)
+	}
+	return resp
+}
+
+func describe(tool genkit.Tool, resp *genkit.PluginResponse) {
+	resp.Name = tool.Name()
+	resp.GenkitVersion = genkit.APIVersion
+	if vt, ok := tool.(genkit.VersionedTool); ok {
+		resp.Requires = vt.GenkitVersion()
+	}
+	if ct, ok := tool.(genkit.ConfigurableTool); ok {
+		cfg := ct.Config()
+		resp.Config = &cfg
+		resp.Capabilities = append(resp.Capabilities, genkit.PluginCapabilityConfig)
+	}
+	if _, ok := tool.(genkit.ValidatableTool); ok {
+		resp.Capabilities = append(resp.Capabilities, genkit.PluginCapabilityValidate)
+	}
+	if rt, ok := tool.(genkit.RuleTool); ok {
+		resp.Rules = rt.Rules()
+		resp.Capabilities = append(resp.Capabilities, genkit.PluginCapabilityRules)
+	}
+	if ot, ok := tool.(genkit.OptionsTool); ok {
+		resp.OptionsSchema = ot.OptionsSchema()
+		resp.Capabilities = append(resp.Capabilities, genkit.PluginCapabilityOptions)
+	}
+}
+
+// setOptions normalizes the options in req and passes them to tool.
+// devgen has already validated them, so this mostly restores types lost
+// in JSON encoding, such as durations and integers.
+func setOptions(tool genkit.Tool, req *genkit.PluginRequest) error {
+	ot, ok := tool.(genkit.OptionsTool)
+	if !ok {
+		return nil
+	}
+	opts, err := genkit.ValidateOptions(ot.OptionsSchema(), req.Options)
+	if err != nil {
+		return err
+	}
+	return ot.SetOptions(opts)
+}
+
+func run(tool genkit.Tool, req *genkit.PluginRequest, resp *genkit.PluginResponse) {
+	gen, logBuf, log := newGenerator(req)
+	defer func() { resp.Log = logBuf.String() }()
+
+	if err := setOptions(tool, req); err != nil {
+		resp.Error = fmt.Sprintf("options: %v", err)
+		return
+	}
+	if err := tool.Run(gen, log); err != nil {
+		resp.Error = err.Error()
+		return
+	}
+	files, err := gen.DryRun()
+	if err != nil {
+		resp.Error = fmt.Sprintf("generate: %v", err)
+		return
+	}
+	for path, content := range files {
+		resp.Files = append(resp.Files, genkit.PluginFile{Path: path, Content: string(content)})
+	}
+	sort.Slice(resp.Files, func(i, j int) bool { return resp.Files[i].Path < resp.Files[j].Path })
+}
+
+func validate(tool genkit.Tool, req *genkit.PluginRequest, resp *genkit.PluginResponse) {
+	vt, ok := tool.(genkit.ValidatableTool)
+	if !ok {
+		return
+	}
+	if err := setOptions(tool, req); err != nil {
+		resp.Error = fmt.Sprintf("options: %v", err)
+		return
+	}
+	gen, logBuf, log := newGenerator(req)
+	resp.Diagnostics = vt.Validate(gen, log)
+	resp.Log = logBuf.String()
+}
+
+// newGenerator builds a Generator from the packages in req, with a logger
+// whose output is returned to devgen.
+func newGenerator(req *genkit.PluginRequest) (*genkit.Generator, *bytes.Buffer, *genkit.Logger) {
+	gen := genkit.New(genkit.Options{IncludeTests: req.IncludeTests})
+	gen.Packages = genkit.DecodePackages(req.Packages)
+	if len(gen.Packages) > 0 {
+		gen.Fset = gen.Packages[0].Fset
+	}
+	var buf bytes.Buffer
+	return gen, &buf, genkit.NewLoggerWithWriter(&buf)
+}
@@ -0,0 +1,458 @@
+package pluginsdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"go/token"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tlipoca9/devgen/genkit"
+)
+
+// testPluginEnv makes the test binary act as an exec plugin.
+const testPluginEnv = "DEVGEN_PLUGINSDK_TEST_PLUGIN"
+
+func TestMain(m *testing.M) {
+	switch os.Getenv(testPluginEnv) {
+	case "1":
+		Main(&testTool{})
+		return
+	case "set":
+		Main(&testTool{}, &otherTool{})
+		return
+	}
+	os.Exit(m.Run())
+}
+
+// testTool generates a constant listing the values of each annotated enum.
+type testTool struct {
+	suffix string
+}
+
+func (t *testTool) Name() string { return "testgen" }
+
+func (t *testTool) OptionsSchema() []genkit.OptionSpec {
+	return []genkit.OptionSpec{{Name: "suffix", Type: genkit.OptionString, Default: "List"}}
+}
+
+func (t *testTool) SetOptions(opts map[string]any) error {
+	t.suffix, _ = opts["suffix"].(string)
+	return nil
+}
+
+func (t *testTool) Config() genkit.ToolConfig {
+	return genkit.ToolConfig{
+		OutputSuffix: "_test_gen.go",
+		Annotations:  []genkit.AnnotationConfig{{Name: "list", Type: "type", Doc: "List enum values"}},
+	}
+}
+
+func (t *testTool) Run(gen *genkit.Generator, log *genkit.Logger) error {
+	for _, pkg := range gen.Packages {
+		for _, e := range pkg.Enums {
+			if genkit.HasAnnotation(e.Doc, "testgen", "sleep") {
+				time.Sleep(time.Minute)
+			}
+			if !genkit.HasAnnotation(e.Doc, "testgen", "list") {
+				continue
+			}
+			var names []string
+			for _, v := range e.Values {
+				names = append(names, v.Name)
+			}
+			g := gen.NewGeneratedFile(genkit.OutputPath(pkg.Dir, strings.ToLower(e.Name)+"_test_gen.go"), pkg.GoImportPath())
+			g.P("// Code generated by testgen. DO NOT EDIT.")
+			g.P()
+			g.P("package ", pkg.Name)
+			g.P()
+			g.P("const ", e.Name, t.suffix, " = ", `"`, strings.Join(names, ","), `"`)
+			log.Item("%s", e.Name)
+		}
+	}
+	return nil
+}
+
+func (t *testTool) Validate(gen *genkit.Generator, log *genkit.Logger) []genkit.Diagnostic {
+	c := genkit.NewDiagnosticCollector("testgen")
+	for _, pkg := range gen.Packages {
+		for _, e := range pkg.Enums {
+			if len(e.Values) == 0 {
+				c.Error("T001", "enum has no values", token.Position{})
+			}
+			for _, v := range e.Values {
+				if genkit.HasAnnotation(v.Doc, "testgen", "skip") {
+					c.Warning("T002", "skip is not supported", v.Pos)
+				}
+			}
+		}
+	}
+	return c.Collect()
+}
+
+// otherTool is the second tool of the test tool set.
+type otherTool struct {
+	testTool
+}
+
+func (t *otherTool) Name() string { return "othergen" }
+
+func testPackages() []*genkit.Package {
+	pkg := &genkit.Package{Name: "model", PkgPath: "example.com/model", Dir: "/src/model"}
+	pkg.Enums = []*genkit.Enum{{
+		Name:           "Status",
+		Doc:            "testgen:@list\n",
+		Pkg:            pkg,
+		UnderlyingType: "int",
+		Values: []*genkit.EnumValue{
+			{Name: "StatusActive", Value: "1"},
+			{Name: "StatusInactive", Value: "2", Doc: "testgen:@skip\n", Pos: token.Position{Filename: "/src/model/status.go", Line: 9, Column: 2}},
+		},
+	}}
+	return []*genkit.Package{pkg}
+}
+
+// TestHandle tests describe, run and validate requests
+func TestHandle(t *testing.T) {
+	tool := &testTool{}
+
+	desc := Handle(tool, &genkit.PluginRequest{Version: genkit.PluginProtocolVersion, Method: genkit.PluginMethodDescribe})
+	if desc.Name != "testgen" || desc.Config == nil || desc.Config.OutputSuffix != "_test_gen.go" {
+		t.Errorf("describe = %+v", desc)
+	}
+	if !desc.HasCapability(genkit.PluginCapabilityValidate) || desc.HasCapability(genkit.PluginCapabilityRules) {
+		t.Errorf("capabilities = %v, want config and validate", desc.Capabilities)
+	}
+	if !desc.HasCapability(genkit.PluginCapabilityOptions) || len(desc.OptionsSchema) != 1 {
+		t.Errorf("options schema = %+v", desc.OptionsSchema)
+	}
+	if desc.GenkitVersion != genkit.APIVersion {
+		t.Errorf("genkit version = %q, want %q", desc.GenkitVersion, genkit.APIVersion)
+	}
+
+	packages := genkit.EncodePackages(testPackages())
+	run := Handle(tool, &genkit.PluginRequest{
+		Version:  genkit.PluginProtocolVersion,
+		Method:   genkit.PluginMethodRun,
+		Packages: packages,
+	})
+	if run.Error != "" {
+		t.Fatalf("run error = %s", run.Error)
+	}
+	if len(run.Files) != 1 || run.Files[0].Path != "/src/model/status_test_gen.go" {
+		t.Fatalf("run files = %+v", run.Files)
+	}
+	if !strings.Contains(run.Files[0].Content, `StatusList = "StatusActive,StatusInactive"`) {
+		t.Errorf("generated content = %s", run.Files[0].Content)
+	}
+	if !strings.Contains(run.Log, "Status") {
+		t.Errorf("log = %q, want tool log output", run.Log)
+	}
+
+	validate := Handle(tool, &genkit.PluginRequest{
+		Version:  genkit.PluginProtocolVersion,
+		Method:   genkit.PluginMethodValidate,
+		Packages: packages,
+	})
+	if len(validate.Diagnostics) != 1 || validate.Diagnostics[0].Code != "T002" || validate.Diagnostics[0].Line != 9 {
+		t.Errorf("validate diagnostics = %+v", validate.Diagnostics)
+	}
+
+	badOptions := Handle(tool, &genkit.PluginRequest{
+		Version: genkit.PluginProtocolVersion,
+		Method:  genkit.PluginMethodRun,
+		Options: map[string]any{"sufix": "Values"},
+	})
+	if !strings.Contains(badOptions.Error, `unknown option "sufix"`) {
+		t.Errorf("run with unknown option error = %q", badOptions.Error)
+	}
+
+	bad := Handle(tool, &genkit.PluginRequest{Version: 99, Method: genkit.PluginMethodDescribe})
+	if !strings.Contains(bad.Error, "unsupported protocol version 99") {
+		t.Errorf("version mismatch error = %q", bad.Error)
+	}
+}
+
+// TestHandleTools tests requests to a tool set
+func TestHandleTools(t *testing.T) {
+	tools := []genkit.Tool{&testTool{}, &otherTool{}}
+
+	list := HandleTools(tools, &genkit.PluginRequest{Version: genkit.PluginProtocolVersion, Method: genkit.PluginMethodDescribe})
+	if list.Error != "" || strings.Join(list.Tools, ",") != "testgen,othergen" || list.Name != "" {
+		t.Errorf("describe set = %+v", list)
+	}
+
+	desc := HandleTools(tools, &genkit.PluginRequest{Version: genkit.PluginProtocolVersion, Method: genkit.PluginMethodDescribe, Tool: "othergen"})
+	if desc.Name != "othergen" || len(desc.Tools) != 0 {
+		t.Errorf("describe othergen = %+v", desc)
+	}
+
+	noTool := HandleTools(tools, &genkit.PluginRequest{Version: genkit.PluginProtocolVersion, Method: genkit.PluginMethodRun})
+	if !strings.Contains(noTool.Error, "must name a tool") {
+		t.Errorf("run without tool error = %q", noTool.Error)
+	}
+	unknown := HandleTools(tools, &genkit.PluginRequest{Version: genkit.PluginProtocolVersion, Method: genkit.PluginMethodRun, Tool: "nogen"})
+	if !strings.Contains(unknown.Error, `unknown tool "nogen"`) {
+		t.Errorf("run with unknown tool error = %q", unknown.Error)
+	}
+}
+
+// TestServe tests the JSON encoding over reader and writer
+func TestServe(t *testing.T) {
+	in := strings.NewReader(`{"version":1,"method":"describe"}`)
+	var out bytes.Buffer
+	if err := Serve(&testTool{}, in, &out); err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+	var resp genkit.PluginResponse
+	if err := json.Unmarshal(out.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Version != genkit.PluginProtocolVersion || resp.Name != "testgen" {
+		t.Errorf("response = %+v", resp)
+	}
+
+	if err := Serve(&testTool{}, strings.NewReader("not json"), &out); err == nil {
+		t.Error("Serve() should fail on invalid input")
+	}
+}
+
+// TestExecPlugin tests loading and running the test binary as an exec plugin
+func TestExecPlugin(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Skipf("os.Executable: %v", err)
+	}
+	t.Setenv(testPluginEnv, "1")
+
+	loader := genkit.NewPluginLoader(t.TempDir())
+	pluginCfg := genkit.PluginConfig{
+		Name:    "testgen",
+		Path:    exe,
+		Type:    genkit.PluginTypeExec,
+		Options: map[string]any{"suffix": "Values"},
+	}
+	tool, err := loader.LoadPlugin(context.Background(), pluginCfg)
+	if err != nil {
+		t.Fatalf("LoadPlugin() error = %v", err)
+	}
+	if err := genkit.ApplyOptions([]genkit.Tool{tool}, &genkit.Config{Plugins: []genkit.PluginConfig{pluginCfg}}); err != nil {
+		t.Fatalf("ApplyOptions() error = %v", err)
+	}
+
+	if cfg := genkit.GetToolConfig(tool); len(cfg.Annotations) != 1 || cfg.Annotations[0].Name != "list" {
+		t.Errorf("GetToolConfig() = %+v", cfg)
+	}
+
+	gen := genkit.New()
+	gen.Packages = testPackages()
+	var logBuf bytes.Buffer
+	if err := tool.Run(gen, genkit.NewLoggerWithWriter(&logBuf)); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	files, err := gen.DryRun()
+	if err != nil {
+		t.Fatalf("DryRun() error = %v", err)
+	}
+	content, ok := files[filepath.Join("/src/model", "status_test_gen.go")]
+	if !ok || !strings.Contains(string(content), "StatusValues") {
+		t.Errorf("DryRun() = %v", files)
+	}
+	if !strings.Contains(logBuf.String(), "Status") {
+		t.Errorf("plugin log not replayed: %q", logBuf.String())
+	}
+
+	vt, ok := tool.(genkit.ValidatableTool)
+	if !ok {
+		t.Fatal("exec plugin should implement ValidatableTool")
+	}
+	if diags := vt.Validate(gen, genkit.NewLoggerWithWriter(&logBuf)); len(diags) != 1 || diags[0].Tool != "testgen" {
+		t.Errorf("Validate() = %+v", diags)
+	}
+
+	if _, err := loader.LoadPlugin(context.Background(), genkit.PluginConfig{
+		Name: "othergen",
+		Path: exe,
+		Type: genkit.PluginTypeExec,
+	}); err == nil || !strings.Contains(err.Error(), `reports name "testgen"`) {
+		t.Errorf("LoadPlugin() with wrong name error = %v", err)
+	}
+}
+
+// TestExecPlugin_Cancel tests that requests to an exec plugin end with the
+// context it was loaded with and after the configured timeout
+func TestExecPlugin_Cancel(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Skipf("os.Executable: %v", err)
+	}
+	t.Setenv(testPluginEnv, "1")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	tool, err := genkit.NewPluginLoader(t.TempDir()).LoadPlugin(ctx, genkit.PluginConfig{
+		Name: "testgen",
+		Path: exe,
+		Type: genkit.PluginTypeExec,
+	})
+	if err != nil {
+		t.Fatalf("LoadPlugin() error = %v", err)
+	}
+	cancel()
+	gen := genkit.New()
+	gen.Packages = testPackages()
+	if err := tool.Run(gen, genkit.NewLoggerWithWriter(io.Discard)); !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() after cancel error = %v, want context canceled", err)
+	}
+
+	slow, err := genkit.NewPluginLoader(t.TempDir()).LoadPlugin(context.Background(), genkit.PluginConfig{
+		Name:    "testgen",
+		Path:    exe,
+		Type:    genkit.PluginTypeExec,
+		Timeout: "2s",
+	})
+	if err != nil {
+		t.Fatalf("LoadPlugin() error = %v", err)
+	}
+	gen.Packages[0].Enums[0].Doc = "testgen:@sleep\n"
+	if err := slow.Run(gen, genkit.NewLoggerWithWriter(io.Discard)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Run() of a slow plugin error = %v, want deadline exceeded", err)
+	}
+}
+
+// TestExecToolSet tests loading a tool set from one executable
+func TestExecToolSet(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Skipf("os.Executable: %v", err)
+	}
+	t.Setenv(testPluginEnv, "set")
+
+	loader := genkit.NewPluginLoader(t.TempDir())
+	pluginCfg := genkit.PluginConfig{Name: "testset", Path: exe, Type: genkit.PluginTypeExec}
+	tools, err := loader.LoadPluginTools(context.Background(), pluginCfg)
+	if err != nil {
+		t.Fatalf("LoadPluginTools() error = %v", err)
+	}
+	if len(tools) != 2 || tools[0].Name() != "testgen" || tools[1].Name() != "othergen" {
+		t.Fatalf("LoadPluginTools() = %v", tools)
+	}
+	if _, err := loader.LoadPlugin(context.Background(), pluginCfg); err == nil || !strings.Contains(err.Error(), "provides 2 tools") {
+		t.Errorf("LoadPlugin() of a tool set error = %v", err)
+	}
+
+	pluginCfg.Tools = []string{"othergen"}
+	tool, err := loader.LoadPlugin(context.Background(), pluginCfg)
+	if err != nil {
+		t.Fatalf("LoadPlugin() with selection error = %v", err)
+	}
+	if tool.Name() != "othergen" {
+		t.Errorf("Name() = %q", tool.Name())
+	}
+
+	// Requests are routed to the selected tool
+	gen := genkit.New()
+	gen.Packages = testPackages()
+	if err := tool.Run(gen, genkit.NewLoggerWithWriter(io.Discard)); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	vt := tool.(genkit.ValidatableTool)
+	if diags := vt.Validate(gen, genkit.NewLoggerWithWriter(io.Discard)); len(diags) != 1 {
+		t.Errorf("Validate() = %+v", diags)
+	}
+
+	// Plugin options cannot be routed to the tools of a set
+	pluginCfg.Tools = nil
+	pluginCfg.Options = map[string]any{"suffix": "Values"}
+	_, err = loader.LoadTools(context.Background(), &genkit.Config{Plugins: []genkit.PluginConfig{pluginCfg}}, nil)
+	if err == nil || !strings.Contains(err.Error(), "[tools.<name>.options]") {
+		t.Errorf("LoadTools() with options on a tool set error = %v", err)
+	}
+}
+
+// TestWasmPlugin tests running a WASI module as a plugin with resource limits
+func TestWasmPlugin(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a wasm module")
+	}
+	wasmPath := filepath.Join(t.TempDir(), "wasmgen.wasm")
+	cmd := exec.Command("go", "build", "-o", wasmPath, "./testdata/wasmplugin")
+	cmd.Env = append(os.Environ(), "GOOS=wasip1", "GOARCH=wasm")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("build wasm plugin: %v\n%s", err, out)
+	}
+
+	loader := genkit.NewPluginLoader(t.TempDir())
+	tool, err := loader.LoadPlugin(context.Background(), genkit.PluginConfig{
+		Name:          "wasmgen",
+		Path:          wasmPath,
+		Type:          genkit.PluginTypeWasm,
+		MemoryLimitMB: 128,
+		Timeout:       "5s",
+	})
+	if err != nil {
+		t.Fatalf("LoadPlugin() error = %v", err)
+	}
+
+	runWith := func(annotation string) (map[string][]byte, error) {
+		gen := genkit.New()
+		gen.Packages = testPackages()
+		gen.Packages[0].Dir = t.TempDir()
+		gen.Packages[0].Enums[0].Doc = "wasmgen:@" + annotation + "\n"
+		if err := tool.Run(gen, genkit.NewLoggerWithWriter(io.Discard)); err != nil {
+			return nil, err
+		}
+		return gen.DryRun()
+	}
+
+	files, err := runWith("gen")
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("Run() generated %d files, want 1", len(files))
+	}
+	for _, content := range files {
+		if !strings.Contains(string(content), "StatusCount = 2") {
+			t.Errorf("generated content = %s", content)
+		}
+	}
+
+	if _, err := runWith("readfile"); err != nil {
+		t.Errorf("Run() with file access error = %v, want file system access denied", err)
+	}
+	if _, err := runWith("alloc"); err == nil || !strings.Contains(err.Error(), "memory limit") {
+		t.Errorf("Run() exceeding memory error = %v, want memory limit error", err)
+	}
+
+	if err := loader.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if _, err := runWith("gen"); err == nil {
+		t.Error("Run() after Close() should fail")
+	}
+
+	slowLoader := genkit.NewPluginLoader(t.TempDir())
+	defer slowLoader.Close()
+	slow, err := slowLoader.LoadPlugin(context.Background(), genkit.PluginConfig{
+		Name:    "wasmgen",
+		Path:    wasmPath,
+		Type:    genkit.PluginTypeWasm,
+		Timeout: "2s",
+	})
+	if err != nil {
+		t.Fatalf("LoadPlugin() error = %v", err)
+	}
+	gen := genkit.New()
+	gen.Packages = testPackages()
+	gen.Packages[0].Enums[0].Doc = "wasmgen:@loop\n"
+	if err := slow.Run(gen, genkit.NewLoggerWithWriter(io.Discard)); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Run() with endless loop error = %v, want timeout", err)
+	}
+}
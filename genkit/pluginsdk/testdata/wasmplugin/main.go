@@ -0,0 +1,47 @@
+// Command wasmplugin is a test plugin built with GOOS=wasip1 GOARCH=wasm.
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/tlipoca9/devgen/genkit"
+	"github.com/tlipoca9/devgen/genkit/pluginsdk"
+)
+
+type wasmTool struct{}
+
+func (t *wasmTool) Name() string { return "wasmgen" }
+
+func (t *wasmTool) Run(gen *genkit.Generator, log *genkit.Logger) error {
+	for _, pkg := range gen.Packages {
+		for _, e := range pkg.Enums {
+			switch {
+			case genkit.HasAnnotation(e.Doc, "wasmgen", "readfile"):
+				// File system access must be denied
+				if _, err := os.ReadFile(pkg.Dir + "/go.mod"); err == nil {
+					return os.ErrExist
+				}
+			case genkit.HasAnnotation(e.Doc, "wasmgen", "loop"):
+				for {
+				}
+			case genkit.HasAnnotation(e.Doc, "wasmgen", "alloc"):
+				var chunks [][]byte
+				for {
+					chunks = append(chunks, make([]byte, 16<<20))
+				}
+			}
+			g := gen.NewGeneratedFile(genkit.OutputPath(pkg.Dir, strings.ToLower(e.Name)+"_wasm.go"), pkg.GoImportPath())
+			g.P("// Code generated by wasmgen. DO NOT EDIT.")
+			g.P()
+			g.P("package ", pkg.Name)
+			g.P()
+			g.P("const ", e.Name, "Count = ", len(e.Values))
+		}
+	}
+	return nil
+}
+
+func main() {
+	pluginsdk.Main(&wasmTool{})
+}
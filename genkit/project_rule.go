@@ -0,0 +1,93 @@
+package genkit
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ProjectRuleName is the name of the project-specific rule built by
+// BuildProjectRule.
+const ProjectRuleName = "devgen-project"
+
+// ProjectRuleTool extends Tool with a description of how the loaded
+// packages use it. Where the rules of RuleTool are generic documentation,
+// the project rule lists the actual annotated declarations, such as the
+// values of an enum, so AI assistants don't have to guess them.
+type ProjectRuleTool interface {
+	Tool
+
+	// ProjectRule describes the declarations of the loaded packages the
+	// tool generates code for. It returns a zero ProjectRule if no package
+	// uses the tool.
+	ProjectRule(gen *Generator) ProjectRule
+}
+
+// ProjectRule is the part of the project-specific rule contributed by a
+// tool.
+type ProjectRule struct {
+	// Content is a Markdown section starting with a level-2 heading.
+	Content string
+
+	// Packages are the packages that use the tool. The project rule is
+	// attached to the Go files in their directories.
+	Packages []*Package
+}
+
+// BuildProjectRule combines the project rules of tools for the packages
+// loaded by gen into a single rule. root is the project root the globs of
+// the rule are relative to. It reports false if no tool describes anything.
+func BuildProjectRule(gen *Generator, tools []Tool, root string) (Rule, bool) {
+	var sections []string
+	var globs []string
+	for _, tool := range tools {
+		pt, ok := tool.(ProjectRuleTool)
+		if !ok {
+			continue
+		}
+		pr := pt.ProjectRule(gen)
+		if strings.TrimSpace(pr.Content) == "" {
+			continue
+		}
+		sections = append(sections, strings.TrimSpace(pr.Content))
+		for _, pkg := range pr.Packages {
+			if glob := packageGlob(root, pkg.Dir); !slices.Contains(globs, glob) {
+				globs = append(globs, glob)
+			}
+		}
+	}
+	if len(sections) == 0 {
+		return Rule{}, false
+	}
+	slices.Sort(globs)
+
+	var sb strings.Builder
+	sb.WriteString("# Project Code Generation\n\n")
+	sb.WriteString("The declarations below are annotated for devgen in this project. ")
+	sb.WriteString("Use these names and values instead of the generic examples of the tool rules. ")
+	sb.WriteString("This rule is generated from the source code by `devgen rules --project`; ")
+	sb.WriteString("run it again after changing annotations.\n")
+	for _, section := range sections {
+		sb.WriteString("\n" + section + "\n")
+	}
+
+	return Rule{
+		Name:        ProjectRuleName,
+		Description: "Enums, validated structs, converters and delegators of this project that devgen generates code for, with their actual values and constraints.",
+		Globs:       globs,
+		Content:     sb.String(),
+	}, true
+}
+
+// packageGlob returns the glob matching the Go files of the package in dir,
+// relative to root.
+func packageGlob(root, dir string) string {
+	rel, err := filepath.Rel(root, dir)
+	switch {
+	case err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)):
+		return filepath.ToSlash(filepath.Join(dir, "*.go"))
+	case rel == ".":
+		return "*.go"
+	}
+	return filepath.ToSlash(rel) + "/*.go"
+}
@@ -0,0 +1,50 @@
+package genkit
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// projectTestTool is a ProjectRuleTool that describes the packages it is
+// given.
+type projectTestTool struct {
+	plainTestTool
+	content string
+	pkgs    []*Package
+}
+
+func (t *projectTestTool) ProjectRule(*Generator) ProjectRule {
+	return ProjectRule{Content: t.content, Packages: t.pkgs}
+}
+
+func TestBuildProjectRule(t *testing.T) {
+	root := t.TempDir()
+	order := &Package{Name: "order", Dir: filepath.Join(root, "internal", "order")}
+	main := &Package{Name: "main", Dir: root}
+	gen := New()
+
+	if _, ok := BuildProjectRule(gen, []Tool{&plainTestTool{name: "plain"}, &projectTestTool{}}, root); ok {
+		t.Error("BuildProjectRule() without content should report false")
+	}
+
+	rule, ok := BuildProjectRule(gen, []Tool{
+		&projectTestTool{plainTestTool{"enumgen"}, "## Enums\n\n`OrderStatusPending`\n", []*Package{order}},
+		&plainTestTool{name: "plain"},
+		&projectTestTool{plainTestTool{"validategen"}, "## Validated Structs\n", []*Package{order, main}},
+	}, root)
+	if !ok {
+		t.Fatal("BuildProjectRule() reported false")
+	}
+	if rule.Name != ProjectRuleName || rule.AlwaysApply {
+		t.Errorf("rule = %+v", rule)
+	}
+	if want := []string{"*.go", "internal/order/*.go"}; !reflect.DeepEqual(rule.Globs, want) {
+		t.Errorf("Globs = %v, want %v", rule.Globs, want)
+	}
+	if !strings.HasPrefix(rule.Content, "# Project Code Generation\n") ||
+		!strings.Contains(rule.Content, "\n## Enums\n\n`OrderStatusPending`\n\n## Validated Structs\n") {
+		t.Errorf("Content =\n%s", rule.Content)
+	}
+}
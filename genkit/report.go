@@ -0,0 +1,382 @@
+package genkit
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ReportFormat is an output format for diagnostics.
+type ReportFormat string
+
+const (
+	// ReportFormatText is the human-readable log output.
+	ReportFormatText ReportFormat = "text"
+
+	// ReportFormatJSON is devgen's own DryRunResult schema.
+	ReportFormatJSON ReportFormat = "json"
+
+	// ReportFormatSARIF is SARIF 2.1.0, used by code-scanning pipelines.
+	ReportFormatSARIF ReportFormat = "sarif"
+
+	// ReportFormatJUnit is JUnit XML, used by test dashboards.
+	ReportFormatJUnit ReportFormat = "junit"
+)
+
+// ReportFormats lists all supported report formats.
+var ReportFormats = []ReportFormat{ReportFormatText, ReportFormatJSON, ReportFormatSARIF, ReportFormatJUnit}
+
+// ParseReportFormat parses a report format name.
+func ParseReportFormat(s string) (ReportFormat, error) {
+	for _, f := range ReportFormats {
+		if string(f) == s {
+			return f, nil
+		}
+	}
+	names := make([]string, len(ReportFormats))
+	for i, f := range ReportFormats {
+		names[i] = string(f)
+	}
+	return "", fmt.Errorf("unknown format %q, supported formats: %s", s, strings.Join(names, ", "))
+}
+
+// ReportOptions configures SARIF and JUnit rendering.
+type ReportOptions struct {
+	// ToolName is the name of the reporting driver. Default: "devgen".
+	ToolName string
+
+	// ToolVersion is the version of the reporting driver.
+	ToolVersion string
+
+	// BaseDir makes file paths relative when set.
+	// Paths outside BaseDir are left absolute.
+	BaseDir string
+}
+
+func (o ReportOptions) toolName() string {
+	if o.ToolName == "" {
+		return "devgen"
+	}
+	return o.ToolName
+}
+
+// relPath returns file relative to BaseDir using forward slashes.
+func (o ReportOptions) relPath(file string) string {
+	if file == "" || o.BaseDir == "" {
+		return filepath.ToSlash(file)
+	}
+	rel, err := filepath.Rel(o.BaseDir, file)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(file)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// RuleID returns the identifier of the check that produced the diagnostic,
+// in the form "tool/code" (or just "tool" when no code is set).
+func (d Diagnostic) RuleID() string {
+	if d.Code == "" {
+		return d.Tool
+	}
+	return d.Tool + "/" + d.Code
+}
+
+// sarifLog is the root object of a SARIF 2.1.0 file.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool        sarifTool         `json:"tool"`
+	Invocations []sarifInvocation `json:"invocations,omitempty"`
+	Results     []sarifResult     `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []sarifRule `json:"rules,omitempty"`
+}
+
+type sarifRule struct {
+	ID               string         `json:"id"`
+	ShortDescription *sarifMessage  `json:"shortDescription,omitempty"`
+	Properties       map[string]any `json:"properties,omitempty"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful bool `json:"executionSuccessful"`
+}
+
+type sarifResult struct {
+	RuleID     string          `json:"ruleId"`
+	RuleIndex  int             `json:"ruleIndex"`
+	Level      string          `json:"level"`
+	Message    sarifMessage    `json:"message"`
+	Locations  []sarifLocation `json:"locations,omitempty"`
+	Fixes      []sarifFix      `json:"fixes,omitempty"`
+	Properties map[string]any  `json:"properties,omitempty"`
+}
+
+type sarifFix struct {
+	Description     sarifMessage          `json:"description"`
+	ArtifactChanges []sarifArtifactChange `json:"artifactChanges"`
+}
+
+type sarifArtifactChange struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Replacements     []sarifReplacement    `json:"replacements"`
+}
+
+type sarifReplacement struct {
+	DeletedRegion   sarifRegion   `json:"deletedRegion"`
+	InsertedContent *sarifMessage `json:"insertedContent,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine,omitempty"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+// sarifLevel maps a diagnostic severity to a SARIF result level.
+func sarifLevel(s DiagnosticSeverity) string {
+	switch s {
+	case DiagnosticError:
+		return "error"
+	case DiagnosticWarning:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// WriteSARIF renders diagnostics as a SARIF 2.1.0 log.
+// Each distinct tool/code pair becomes a SARIF rule.
+func WriteSARIF(w io.Writer, result *DryRunResult, opts ReportOptions) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           opts.toolName(),
+			Version:        opts.ToolVersion,
+			InformationURI: "https://github.com/tlipoca9/devgen",
+		}},
+		Invocations: []sarifInvocation{{ExecutionSuccessful: result.Success}},
+		Results:     []sarifResult{},
+	}
+
+	ruleIndex := make(map[string]int)
+	for _, d := range result.Diagnostics {
+		id := d.RuleID()
+		idx, ok := ruleIndex[id]
+		if !ok {
+			idx = len(run.Tool.Driver.Rules)
+			ruleIndex[id] = idx
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+				ID:         id,
+				Properties: map[string]any{"tool": d.Tool},
+			})
+		}
+
+		res := sarifResult{
+			RuleID:     id,
+			RuleIndex:  idx,
+			Level:      sarifLevel(d.Severity),
+			Message:    sarifMessage{Text: d.Message},
+			Properties: map[string]any{"tool": d.Tool},
+		}
+		if d.Code != "" {
+			res.Properties["code"] = d.Code
+		}
+		if d.File != "" {
+			uri := opts.relPath(d.File)
+			loc := sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: uri},
+			}
+			if !filepath.IsAbs(filepath.FromSlash(uri)) {
+				loc.ArtifactLocation.URIBaseID = "%SRCROOT%"
+			}
+			if d.Line > 0 {
+				loc.Region = &sarifRegion{
+					StartLine:   d.Line,
+					StartColumn: d.Column,
+					EndLine:     d.EndLine,
+					EndColumn:   d.EndCol,
+				}
+			}
+			res.Locations = []sarifLocation{{PhysicalLocation: loc}}
+		}
+		for _, fix := range d.Fixes {
+			res.Fixes = append(res.Fixes, sarifFixOf(fix, opts))
+		}
+		run.Results = append(run.Results, res)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+}
+
+// sarifFixOf converts a suggested fix to a SARIF fix object.
+func sarifFixOf(fix SuggestedFix, opts ReportOptions) sarifFix {
+	sf := sarifFix{Description: sarifMessage{Text: fix.Message}}
+	changes := make(map[string]int)
+	for _, e := range fix.Edits {
+		uri := opts.relPath(e.File)
+		idx, ok := changes[uri]
+		if !ok {
+			idx = len(sf.ArtifactChanges)
+			changes[uri] = idx
+			loc := sarifArtifactLocation{URI: uri}
+			if !filepath.IsAbs(filepath.FromSlash(uri)) {
+				loc.URIBaseID = "%SRCROOT%"
+			}
+			sf.ArtifactChanges = append(sf.ArtifactChanges, sarifArtifactChange{ArtifactLocation: loc})
+		}
+		r := sarifReplacement{DeletedRegion: sarifRegion{
+			StartLine:   e.Line,
+			StartColumn: e.Column,
+			EndLine:     e.EndLine,
+			EndColumn:   e.EndCol,
+		}}
+		if e.NewText != "" {
+			r.InsertedContent = &sarifMessage{Text: e.NewText}
+		}
+		sf.ArtifactChanges[idx].Replacements = append(sf.ArtifactChanges[idx].Replacements, r)
+	}
+	return sf
+}
+
+// junitTestSuites is the root element of a JUnit XML report.
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Line      int           `xml:"line,attr,omitempty"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit renders diagnostics as JUnit XML.
+// Diagnostics are grouped into one test suite per tool. Errors become
+// failed test cases; warnings and infos become passing test cases whose
+// message is recorded in system-out. When there are no diagnostics, a single
+// passing test case is emitted so dashboards record a successful run.
+func WriteJUnit(w io.Writer, result *DryRunResult, opts ReportOptions) error {
+	byTool := make(map[string][]Diagnostic)
+	for _, d := range result.Diagnostics {
+		byTool[d.Tool] = append(byTool[d.Tool], d)
+	}
+
+	root := junitTestSuites{Name: opts.toolName()}
+	if len(byTool) == 0 {
+		byTool[opts.toolName()] = nil
+	}
+
+	tools := make([]string, 0, len(byTool))
+	for tool := range byTool {
+		tools = append(tools, tool)
+	}
+	sort.Strings(tools)
+
+	for _, tool := range tools {
+		suite := junitTestSuite{Name: tool}
+		for _, d := range byTool[tool] {
+			tc := junitTestCase{
+				Name:      junitCaseName(d, opts),
+				ClassName: strings.ReplaceAll(d.RuleID(), "/", "."),
+				File:      opts.relPath(d.File),
+				Line:      d.Line,
+			}
+			text := fmt.Sprintf("%s: [%s] %s", d.Severity, d.RuleID(), d.Message)
+			if d.Severity == DiagnosticError {
+				tc.Failure = &junitFailure{Message: d.Message, Type: d.RuleID(), Text: text}
+				suite.Failures++
+			} else {
+				tc.SystemOut = text
+			}
+			suite.TestCases = append(suite.TestCases, tc)
+		}
+		if len(suite.TestCases) == 0 {
+			suite.TestCases = append(suite.TestCases, junitTestCase{Name: tool, ClassName: tool})
+		}
+		suite.Tests = len(suite.TestCases)
+		root.Tests += suite.Tests
+		root.Failures += suite.Failures
+		root.Suites = append(root.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(root); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// junitCaseName returns the test case name for a diagnostic: its position
+// when known, otherwise the rule ID.
+func junitCaseName(d Diagnostic, opts ReportOptions) string {
+	if d.File == "" {
+		return d.RuleID()
+	}
+	return fmt.Sprintf("%s:%d:%d", opts.relPath(d.File), d.Line, d.Column)
+}
@@ -0,0 +1,130 @@
+package genkit
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func testReportResult() *DryRunResult {
+	result := &DryRunResult{Success: true}
+	result.AddDiagnostic(Diagnostic{
+		Severity: DiagnosticError,
+		Message:  "unsupported underlying type",
+		File:     "/repo/pkg/model.go",
+		Line:     10,
+		Column:   6,
+		Tool:     "enumgen",
+		Code:     "E001",
+	})
+	result.AddDiagnostic(Diagnostic{
+		Severity: DiagnosticWarning,
+		Message:  "unused variable",
+		File:     "/repo/pkg/util.go",
+		Line:     3,
+		Column:   2,
+		Tool:     "golangcilint",
+		Code:     "unused",
+	})
+	return result
+}
+
+func TestParseReportFormat(t *testing.T) {
+	for _, f := range ReportFormats {
+		got, err := ParseReportFormat(string(f))
+		if err != nil || got != f {
+			t.Errorf("ParseReportFormat(%q) = %q, %v", f, got, err)
+		}
+	}
+	if _, err := ParseReportFormat("xml"); err == nil {
+		t.Error("ParseReportFormat(xml) should return error")
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteSARIF(&buf, testReportResult(), ReportOptions{ToolVersion: "v1.0.0", BaseDir: "/repo"})
+	if err != nil {
+		t.Fatalf("WriteSARIF() error = %v", err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("invalid SARIF JSON: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("unexpected SARIF root: %+v", log)
+	}
+
+	run := log.Runs[0]
+	if run.Tool.Driver.Name != "devgen" || run.Tool.Driver.Version != "v1.0.0" {
+		t.Errorf("driver = %+v", run.Tool.Driver)
+	}
+	if len(run.Tool.Driver.Rules) != 2 || run.Tool.Driver.Rules[0].ID != "enumgen/E001" {
+		t.Errorf("rules = %+v", run.Tool.Driver.Rules)
+	}
+	if len(run.Results) != 2 {
+		t.Fatalf("results = %d, want 2", len(run.Results))
+	}
+
+	res := run.Results[0]
+	if res.Level != "error" || res.RuleID != "enumgen/E001" {
+		t.Errorf("result = %+v", res)
+	}
+	loc := res.Locations[0].PhysicalLocation
+	if loc.ArtifactLocation.URI != "pkg/model.go" || loc.ArtifactLocation.URIBaseID != "%SRCROOT%" {
+		t.Errorf("artifactLocation = %+v", loc.ArtifactLocation)
+	}
+	if loc.Region.StartLine != 10 || loc.Region.StartColumn != 6 {
+		t.Errorf("region = %+v", loc.Region)
+	}
+	if run.Results[1].Level != "warning" || run.Results[1].Properties["tool"] != "golangcilint" {
+		t.Errorf("result[1] = %+v", run.Results[1])
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, testReportResult(), ReportOptions{BaseDir: "/repo"}); err != nil {
+		t.Fatalf("WriteJUnit() error = %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "<?xml") {
+		t.Error("JUnit output missing XML header")
+	}
+
+	var suites junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+		t.Fatalf("invalid JUnit XML: %v", err)
+	}
+	if suites.Tests != 2 || suites.Failures != 1 || len(suites.Suites) != 2 {
+		t.Fatalf("testsuites = %+v", suites)
+	}
+
+	// Suites are sorted by tool name
+	if suites.Suites[0].Name != "enumgen" || suites.Suites[0].TestCases[0].Failure == nil {
+		t.Errorf("enumgen suite = %+v", suites.Suites[0])
+	}
+	if tc := suites.Suites[0].TestCases[0]; tc.Name != "pkg/model.go:10:6" || tc.ClassName != "enumgen.E001" {
+		t.Errorf("testcase = %+v", tc)
+	}
+	if tc := suites.Suites[1].TestCases[0]; tc.Failure != nil || !strings.Contains(tc.SystemOut, "unused variable") {
+		t.Errorf("warning testcase = %+v", tc)
+	}
+}
+
+func TestWriteJUnit_NoDiagnostics(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, &DryRunResult{Success: true}, ReportOptions{}); err != nil {
+		t.Fatalf("WriteJUnit() error = %v", err)
+	}
+
+	var suites junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+		t.Fatalf("invalid JUnit XML: %v", err)
+	}
+	if suites.Tests != 1 || suites.Failures != 0 {
+		t.Errorf("testsuites = %+v, want one passing test", suites)
+	}
+}
@@ -0,0 +1,280 @@
+package genkit
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// RuleBudget limits the size of a rule's content, since some agents
+// truncate or reject large rule files. Zero fields are unlimited.
+type RuleBudget struct {
+	// Chars is the maximum number of characters.
+	Chars int `toml:"max_chars"`
+
+	// Tokens is the maximum number of tokens, estimated as one token per
+	// four characters.
+	Tokens int `toml:"max_tokens"`
+}
+
+// IsZero reports whether the budget is unlimited.
+func (b RuleBudget) IsZero() bool {
+	return b.Chars <= 0 && b.Tokens <= 0
+}
+
+// Min returns the tighter of the limits of b and other.
+func (b RuleBudget) Min(other RuleBudget) RuleBudget {
+	return RuleBudget{Chars: minLimit(b.Chars, other.Chars), Tokens: minLimit(b.Tokens, other.Tokens)}
+}
+
+// Fits reports whether content stays within the budget.
+func (b RuleBudget) Fits(content string) bool {
+	chars := utf8.RuneCountInString(content)
+	return (b.Chars <= 0 || chars <= b.Chars) && (b.Tokens <= 0 || EstimateTokens(content) <= b.Tokens)
+}
+
+// String describes the budget, e.g. "6000 characters".
+func (b RuleBudget) String() string {
+	var parts []string
+	if b.Chars > 0 {
+		parts = append(parts, fmt.Sprintf("%d characters", b.Chars))
+	}
+	if b.Tokens > 0 {
+		parts = append(parts, fmt.Sprintf("%d tokens", b.Tokens))
+	}
+	if len(parts) == 0 {
+		return "unlimited"
+	}
+	return strings.Join(parts, " and ")
+}
+
+// minLimit returns the smaller positive limit of a and b.
+func minLimit(a, b int) int {
+	switch {
+	case a <= 0:
+		return max(b, 0)
+	case b <= 0:
+		return a
+	}
+	return min(a, b)
+}
+
+// EstimateTokens estimates the number of tokens of content as one token per
+// four characters.
+func EstimateTokens(content string) int {
+	return (utf8.RuneCountInString(content) + 3) / 4
+}
+
+// BudgetedAdapter is implemented by adapters of agents with a known limit
+// on the size of a rule file.
+type BudgetedAdapter interface {
+	AgentAdapter
+
+	// Budget returns the default budget of every rule of the agent.
+	Budget() RuleBudget
+}
+
+// AgentRulesConfig configures the rules of a single agent in devgen.toml:
+//
+//	[rules.agents.windsurf]
+//	max_chars = 6000
+//	split = true
+type AgentRulesConfig struct {
+	// RuleBudget overrides the adapter's default budget, see BudgetedAdapter.
+	RuleBudget
+
+	// Split splits rules over the budget at their level-2 headings.
+	Split bool `toml:"split"`
+}
+
+// BudgetRules applies the budget of an agent to rules. The budget of a rule
+// is the tighter of its own Budget and the agent's: the adapter default,
+// replaced by cfg if that sets any limit. If cfg.Split is set, rules over
+// budget are split with SplitRule. It returns the rules and a warning for
+// every rule that is still over budget.
+func BudgetRules(adapter AgentAdapter, rules []Rule, cfg AgentRulesConfig) ([]Rule, []string) {
+	agentBudget := cfg.RuleBudget
+	if ba, ok := adapter.(BudgetedAdapter); ok && agentBudget.IsZero() {
+		agentBudget = ba.Budget()
+	}
+
+	var result []Rule
+	var warnings []string
+	for _, rule := range rules {
+		budget := rule.Budget.Min(agentBudget)
+		if budget.IsZero() || budget.Fits(rule.Content) || !rule.ForAgent(adapter.Name()) {
+			result = append(result, rule)
+			continue
+		}
+		parts := []Rule{rule}
+		if cfg.Split {
+			parts = SplitRule(rule, func(sub Rule) string { return ruleLink(adapter, sub) })
+		}
+		for _, part := range parts {
+			if !budget.Fits(part.Content) {
+				warnings = append(warnings, fmt.Sprintf("rule %s has %d characters (~%d tokens), over the budget of %s for %s",
+					part.Name, utf8.RuneCountInString(part.Content), EstimateTokens(part.Content), budget, adapter.Name()))
+			}
+		}
+		result = append(result, parts...)
+	}
+	return result, warnings
+}
+
+// ruleLink returns the link target of the rule sub in the files of adapter:
+// a relative file name, or the name of the rule within an aggregated file.
+func ruleLink(adapter AgentAdapter, sub Rule) string {
+	if _, ok := adapter.(AggregatingAdapter); ok {
+		return ""
+	}
+	filename, _, err := adapter.Transform(sub)
+	if err != nil {
+		return ""
+	}
+	return filename
+}
+
+// splitGlobsPattern matches the comment that sets the globs of a section in
+// a split rule, e.g. <!-- devgen:globs **/*_test.go -->.
+var splitGlobsPattern = regexp.MustCompile(`(?m)^\s*<!--\s*devgen:globs\s+(.*?)\s*-->\s*\n?`)
+
+// SplitRule splits rule at the level-2 headings of its content. Every
+// section becomes a sub-rule named <rule>-<heading slug> with the globs of
+// a <!-- devgen:globs a, b --> comment in the section, or those of rule.
+// The sub-rules are attached by their globs and never always applied. The
+// rule itself keeps the text before the first section, followed by links
+// to the sub-rules; link returns the target of a link, or "" to name the
+// sub-rule instead. SplitRule returns the rule alone if it has no sections.
+func SplitRule(rule Rule, link func(sub Rule) string) []Rule {
+	intro, sections := splitSections(rule.Content)
+	if len(sections) == 0 {
+		return []Rule{rule}
+	}
+
+	parent := rule
+	parent.Budget = RuleBudget{}
+	var subs []Rule
+	var index strings.Builder
+	used := make(map[string]bool)
+	for _, section := range sections {
+		heading, body, _ := strings.Cut(section, "\n")
+		title := strings.TrimSpace(strings.TrimPrefix(heading, "##"))
+
+		name := rule.Name + "-" + slugify(title)
+		for i := 2; used[name]; i++ {
+			name = fmt.Sprintf("%s-%s-%d", rule.Name, slugify(title), i)
+		}
+		used[name] = true
+
+		sub := Rule{
+			Name:          name,
+			Description:   strings.TrimSuffix(rule.Description, ".") + ": " + title,
+			Globs:         rule.Globs,
+			Agents:        rule.Agents,
+			ExcludeAgents: rule.ExcludeAgents,
+		}
+		if m := splitGlobsPattern.FindStringSubmatch(body); m != nil {
+			sub.Globs = splitList(m[1])
+			body = splitGlobsPattern.ReplaceAllString(body, "")
+		}
+		sub.Content = "# " + title + "\n\n" + promoteHeadings(strings.TrimSpace(body))
+		subs = append(subs, sub)
+
+		if target := link(sub); target != "" {
+			fmt.Fprintf(&index, "- [%s](%s)\n", title, target)
+		} else {
+			fmt.Fprintf(&index, "- %s: see rule `%s`\n", title, name)
+		}
+	}
+
+	parent.Content = "## Sections\n\nThis rule is split into parts that are loaded for the matching files:\n\n" + index.String()
+	if intro = strings.TrimSpace(intro); intro != "" {
+		parent.Content = intro + "\n\n" + parent.Content
+	}
+	return append([]Rule{parent}, subs...)
+}
+
+// splitSections splits Markdown content into the text before the first
+// level-2 heading and the sections starting at each level-2 heading.
+// Headings inside code blocks are ignored.
+func splitSections(content string) (string, []string) {
+	var intro strings.Builder
+	var sections []string
+	var current *strings.Builder
+	inFence := false
+	for _, line := range strings.SplitAfter(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+		}
+		if !inFence && strings.HasPrefix(line, "## ") {
+			if current != nil {
+				sections = append(sections, current.String())
+			}
+			current = &strings.Builder{}
+		}
+		if current != nil {
+			current.WriteString(line)
+		} else {
+			intro.WriteString(line)
+		}
+	}
+	if current != nil {
+		sections = append(sections, current.String())
+	}
+	return intro.String(), sections
+}
+
+// promoteHeadings moves every Markdown heading of level 3 or deeper outside
+// code blocks one level up.
+func promoteHeadings(content string) string {
+	lines := strings.Split(content, "\n")
+	inFence := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if !inFence && strings.HasPrefix(line, "###") {
+			level := len(line) - len(strings.TrimLeft(line, "#"))
+			if len(line) == level || line[level] == ' ' {
+				lines[i] = line[1:]
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// slugify returns a lower-case, dash-separated name for a heading.
+func slugify(title string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			if dash && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			sb.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	if sb.Len() == 0 {
+		return "section"
+	}
+	return sb.String()
+}
+
+// splitList splits a comma-separated list and trims its items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,94 @@
+package genkit
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRuleBudget(t *testing.T) {
+	b := RuleBudget{Chars: 10}.Min(RuleBudget{Chars: 20, Tokens: 2})
+	if b != (RuleBudget{Chars: 10, Tokens: 2}) {
+		t.Errorf("Min() = %+v", b)
+	}
+	if !b.Fits("12345678") {
+		t.Error("8 characters should fit")
+	}
+	if b.Fits("123456789") {
+		t.Error("9 characters (~3 tokens) should not fit")
+	}
+	if !(RuleBudget{}).Fits(strings.Repeat("x", 100000)) {
+		t.Error("zero budget should be unlimited")
+	}
+}
+
+func TestSplitRule(t *testing.T) {
+	rule := Rule{
+		Name:        "guide",
+		Description: "Project guide.",
+		Globs:       []string{"**/*.go"},
+		AlwaysApply: true,
+		Content: "# Guide\n\nIntro.\n\n## Testing\n<!-- devgen:globs **/*_test.go, testdata/** -->\n\nUse ginkgo.\n\n" +
+			"### Mocks\n\n```go\n## not a heading\n```\n\n## Error Handling\n\nWrap errors.\n",
+	}
+	rules := SplitRule(rule, func(sub Rule) string { return sub.Name + ".md" })
+	if len(rules) != 3 {
+		t.Fatalf("SplitRule() returned %d rules", len(rules))
+	}
+
+	wantParent := "# Guide\n\nIntro.\n\n## Sections\n\nThis rule is split into parts that are loaded for the matching files:\n\n" +
+		"- [Testing](guide-testing.md)\n- [Error Handling](guide-error-handling.md)\n"
+	if rules[0].Content != wantParent || !rules[0].AlwaysApply {
+		t.Errorf("parent = %+v", rules[0])
+	}
+
+	sub := rules[1]
+	if sub.Name != "guide-testing" || sub.Description != "Project guide: Testing" || sub.AlwaysApply {
+		t.Errorf("sub-rule = %+v", sub)
+	}
+	if strings.Join(sub.Globs, " ") != "**/*_test.go testdata/**" {
+		t.Errorf("Globs = %v", sub.Globs)
+	}
+	if want := "# Testing\n\nUse ginkgo.\n\n## Mocks\n\n```go\n## not a heading\n```"; sub.Content != want {
+		t.Errorf("Content = %q, want %q", sub.Content, want)
+	}
+	if strings.Join(rules[2].Globs, " ") != "**/*.go" {
+		t.Errorf("inherited Globs = %v", rules[2].Globs)
+	}
+
+	if got := SplitRule(Rule{Name: "flat", Content: "# Flat\n\nNo sections."}, nil); len(got) != 1 {
+		t.Errorf("SplitRule() of a rule without sections returned %d rules", len(got))
+	}
+}
+
+func TestBudgetRules(t *testing.T) {
+	big := Rule{Name: "big", Content: "# Big\n\n## One\n\n" + strings.Repeat("a", 4000) + "\n\n## Two\n\n" + strings.Repeat("b", 4000)}
+	small := Rule{Name: "small", Content: "# Small"}
+
+	// Windsurf has a default budget of 6000 characters.
+	rules, warnings := BudgetRules(&WindsurfAdapter{}, []Rule{big, small}, AgentRulesConfig{})
+	if len(rules) != 2 || len(warnings) != 1 || !strings.Contains(warnings[0], "rule big has") {
+		t.Errorf("BudgetRules() = %d rules, warnings %q", len(rules), warnings)
+	}
+
+	rules, warnings = BudgetRules(&WindsurfAdapter{}, []Rule{big, small}, AgentRulesConfig{Split: true})
+	if len(rules) != 4 || len(warnings) != 0 {
+		t.Errorf("BudgetRules() with split = %d rules, warnings %q", len(rules), warnings)
+	}
+	if !strings.Contains(rules[0].Content, "- [One](big-one.md)") {
+		t.Errorf("parent content = %q", rules[0].Content)
+	}
+
+	// The agent budget from devgen.toml replaces the adapter default, and
+	// the rule's own budget still applies.
+	small.Budget = RuleBudget{Tokens: 1}
+	_, warnings = BudgetRules(&WindsurfAdapter{}, []Rule{big, small}, AgentRulesConfig{RuleBudget: RuleBudget{Chars: 10000}})
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "rule small has") {
+		t.Errorf("BudgetRules() with config warnings = %q", warnings)
+	}
+
+	// Aggregated sub-rules are referenced by name.
+	rules, _ = BudgetRules(&AgentsMDAdapter{}, []Rule{big}, AgentRulesConfig{RuleBudget: RuleBudget{Chars: 6000}, Split: true})
+	if !strings.Contains(rules[0].Content, "- One: see rule `big-one`") {
+		t.Errorf("aggregated parent content = %q", rules[0].Content)
+	}
+}
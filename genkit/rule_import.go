@@ -0,0 +1,289 @@
+package genkit
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ImportingAdapter is implemented by adapters whose rule files can be read
+// back into rules, the inverse of Transform. It lets projects move existing
+// agent rules into rules.source_dir, see ImportRules.
+type ImportingAdapter interface {
+	AgentAdapter
+
+	// Parse converts the content of the file filename in OutputDir into a
+	// rule. It returns false if the file is not a rule file of the agent.
+	Parse(filename, content string) (Rule, bool, error)
+}
+
+// ImportedRule is a rule read from a rule file of an agent.
+type ImportedRule struct {
+	// Path is the slash-separated path of the file relative to the project root.
+	Path string
+
+	Rule Rule
+}
+
+// ImportRules reads the rule files in the OutputDir of adapter, relative to
+// root. Files with managed sections are skipped, since devgen generated them
+// from rules it already has; their paths are returned as skipped.
+func ImportRules(adapter ImportingAdapter, root string) (rules []ImportedRule, skipped []string, err error) {
+	dir := filepath.Join(root, filepath.FromSlash(adapter.OutputDir()))
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("read directory %s: %w", adapter.OutputDir(), err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		rel := path.Join(adapter.OutputDir(), entry.Name())
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, nil, fmt.Errorf("read %s: %w", rel, err)
+		}
+		content := strings.ReplaceAll(string(data), "\r\n", "\n")
+		if HasManagedSections(content) {
+			skipped = append(skipped, rel)
+			continue
+		}
+		rule, ok, err := adapter.Parse(entry.Name(), content)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parse %s: %w", rel, err)
+		}
+		if ok {
+			rules = append(rules, ImportedRule{Path: rel, Rule: rule})
+		}
+	}
+	return rules, skipped, nil
+}
+
+// FormatRuleFile formats rule as a rule source file that LoadRuleFromFile
+// reads back, named rule.Name + ".md". Rules without any frontmatter field
+// are written without frontmatter.
+func FormatRuleFile(rule Rule) (string, error) {
+	content := strings.TrimSpace(rule.Content) + "\n"
+	fm := RuleFrontmatter{
+		Description:   rule.Description,
+		Globs:         rule.Globs,
+		AlwaysApply:   rule.AlwaysApply,
+		Agents:        rule.Agents,
+		ExcludeAgents: rule.ExcludeAgents,
+		MaxChars:      rule.Budget.Chars,
+		MaxTokens:     rule.Budget.Tokens,
+	}
+	data, err := yaml.Marshal(fm)
+	if err != nil {
+		return "", fmt.Errorf("format frontmatter: %w", err)
+	}
+	if strings.TrimSpace(string(data)) == "{}" {
+		return content, nil
+	}
+	return "---\n" + string(data) + "---\n\n" + content, nil
+}
+
+// ruleFrontmatter is the frontmatter of an agent rule file as key-value
+// pairs. The values of block sequences are joined with ", ".
+type ruleFrontmatter map[string]string
+
+// parseRuleFile splits an agent rule file into its frontmatter and content.
+// The frontmatter is read line by line rather than as YAML, since agents
+// accept unquoted globs such as "globs: **/*.go" that are invalid YAML.
+func parseRuleFile(content string) (ruleFrontmatter, string) {
+	fm := make(ruleFrontmatter)
+	raw, body := splitRuleFrontmatter(content)
+	if raw == "" {
+		return fm, strings.TrimSpace(content)
+	}
+	raw = strings.TrimPrefix(strings.TrimRight(raw, "\n"), "---\n")
+	raw = strings.TrimSuffix(raw, "---")
+
+	var key string
+	for _, line := range strings.Split(raw, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if item, ok := strings.CutPrefix(trimmed, "- "); ok && key != "" && line != trimmed {
+			if fm[key] != "" {
+				fm[key] += ", "
+			}
+			fm[key] += strings.TrimSpace(item)
+			continue
+		}
+		k, v, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(k)
+		fm[key] = strings.TrimSpace(v)
+	}
+	return fm, strings.TrimSpace(body)
+}
+
+// String returns the unquoted value of key.
+func (fm ruleFrontmatter) String(key string) string {
+	return unquoteValue(fm[key])
+}
+
+// Bool reports whether the value of key is true.
+func (fm ruleFrontmatter) Bool(key string) bool {
+	return strings.EqualFold(fm.String(key), "true")
+}
+
+// Globs returns the globs of key, written as a comma-separated string, a
+// YAML flow sequence or a block sequence. Commas inside "{}", as in
+// "*.{ts,tsx}", do not separate globs.
+func (fm ruleFrontmatter) Globs(key string) []string {
+	value := strings.TrimSpace(fm[key])
+	value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] &&
+		!strings.Contains(value[1:len(value)-1], string(value[0])) {
+		value = value[1 : len(value)-1] // A single quoted comma-separated string
+	}
+	var globs []string
+	for _, glob := range splitOutsideBraces(value) {
+		if glob = unquoteValue(glob); glob != "" {
+			globs = append(globs, glob)
+		}
+	}
+	return globs
+}
+
+// unquoteValue trims spaces and the quotes of a YAML scalar.
+func unquoteValue(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		s = s[1 : len(s)-1]
+	}
+	return s
+}
+
+// parseRuleName returns the rule name of filename, or false if it does not
+// have the extension ext.
+func parseRuleName(filename, ext string) (string, bool) {
+	name, ok := strings.CutSuffix(filename, ext)
+	return name, ok && name != ""
+}
+
+// Parse implements ImportingAdapter. A fileMatchPattern of "**/*", which
+// Transform writes for rules without globs, is dropped.
+func (k *KiroAdapter) Parse(filename, content string) (Rule, bool, error) {
+	name, ok := parseRuleName(filename, ".md")
+	if !ok {
+		return Rule{}, false, nil
+	}
+	fm, body := parseRuleFile(content)
+	rule := Rule{
+		Name:        name,
+		Description: fm.String("description"),
+		Globs:       fm.Globs("fileMatchPattern"),
+		AlwaysApply: fm.String("inclusion") == "always",
+		Content:     body,
+	}
+	if slices.Equal(rule.Globs, []string{"**/*"}) {
+		rule.Globs = nil
+	}
+	return rule, true, nil
+}
+
+// Parse implements ImportingAdapter.
+func (c *CodeBuddyAdapter) Parse(filename, content string) (Rule, bool, error) {
+	return parseDescriptionGlobsRule(filename, ".mdc", content)
+}
+
+// Parse implements ImportingAdapter.
+func (c *CursorAdapter) Parse(filename, content string) (Rule, bool, error) {
+	return parseDescriptionGlobsRule(filename, ".mdc", content)
+}
+
+// parseDescriptionGlobsRule parses the files of agents with 'description',
+// 'globs' and 'alwaysApply' frontmatter fields.
+func parseDescriptionGlobsRule(filename, ext, content string) (Rule, bool, error) {
+	name, ok := parseRuleName(filename, ext)
+	if !ok {
+		return Rule{}, false, nil
+	}
+	fm, body := parseRuleFile(content)
+	return Rule{
+		Name:        name,
+		Description: fm.String("description"),
+		Globs:       fm.Globs("globs"),
+		AlwaysApply: fm.Bool("alwaysApply"),
+		Content:     body,
+	}, true, nil
+}
+
+// Parse implements ImportingAdapter. Only instructions files are imported,
+// not the hand-written .github/copilot-instructions.md. An applyTo of "**"
+// makes the rule always apply.
+func (c *CopilotAdapter) Parse(filename, content string) (Rule, bool, error) {
+	name, ok := parseRuleName(filename, ".instructions.md")
+	if !ok {
+		return Rule{}, false, nil
+	}
+	fm, body := parseRuleFile(content)
+	rule := Rule{
+		Name:        name,
+		Description: fm.String("description"),
+		Globs:       fm.Globs("applyTo"),
+		Content:     body,
+	}
+	if slices.Equal(rule.Globs, []string{"**"}) {
+		rule.Globs, rule.AlwaysApply = nil, true
+	}
+	return rule, true, nil
+}
+
+// Parse implements ImportingAdapter. Rules with the 'model_decision' or
+// 'manual' trigger neither always apply nor have globs.
+func (w *WindsurfAdapter) Parse(filename, content string) (Rule, bool, error) {
+	name, ok := parseRuleName(filename, ".md")
+	if !ok {
+		return Rule{}, false, nil
+	}
+	fm, body := parseRuleFile(content)
+	rule := Rule{
+		Name:        name,
+		Description: fm.String("description"),
+		AlwaysApply: fm.String("trigger") == "always_on",
+		Content:     body,
+	}
+	if fm.String("trigger") == "glob" {
+		rule.Globs = fm.Globs("globs")
+	}
+	return rule, true, nil
+}
+
+// Parse implements ImportingAdapter. Files without 'paths' always apply,
+// and a leading HTML comment is read as the description.
+func (c *ClineAdapter) Parse(filename, content string) (Rule, bool, error) {
+	name, ok := parseRuleName(filename, ".md")
+	if !ok {
+		return Rule{}, false, nil
+	}
+	fm, body := parseRuleFile(content)
+	rule := Rule{
+		Name:  name,
+		Globs: fm.Globs("paths"),
+	}
+	rule.AlwaysApply = len(rule.Globs) == 0
+	if comment, ok := strings.CutPrefix(body, "<!--"); ok {
+		if description, rest, ok := strings.Cut(comment, "-->"); ok && !strings.Contains(description, "\n") {
+			rule.Description = strings.TrimSpace(description)
+			body = strings.TrimSpace(rest)
+		}
+	}
+	rule.Content = body
+	return rule, true, nil
+}
@@ -0,0 +1,133 @@
+package genkit
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestImportingAdapters_RoundTrip(t *testing.T) {
+	for _, tt := range []struct {
+		adapter ImportingAdapter
+		// lossy adjusts a rule for what the agent's format can't express.
+		lossy func(*Rule)
+	}{
+		{&KiroAdapter{}, nil},
+		{&CodeBuddyAdapter{}, nil},
+		{&CursorAdapter{}, nil},
+		{&CopilotAdapter{}, dropAlwaysGlobs},
+		{&WindsurfAdapter{}, dropAlwaysGlobs},
+		{&ClineAdapter{}, func(r *Rule) {
+			dropAlwaysGlobs(r)
+			r.AlwaysApply = len(r.Globs) == 0
+		}},
+	} {
+		for _, rule := range []Rule{testRule, testRuleAlways, testRuleNoGlobs} {
+			filename, content, err := tt.adapter.Transform(rule)
+			if err != nil {
+				t.Fatalf("%s: Transform() error = %v", tt.adapter.Name(), err)
+			}
+			if tt.lossy != nil {
+				tt.lossy(&rule)
+			}
+			got, ok, err := tt.adapter.Parse(filename, content)
+			if err != nil || !ok {
+				t.Fatalf("%s: Parse(%s) = %v, %v", tt.adapter.Name(), filename, ok, err)
+			}
+			if got.Name != rule.Name || got.Description != rule.Description || got.AlwaysApply != rule.AlwaysApply ||
+				!slices.Equal(got.Globs, rule.Globs) || got.Content != rule.Content {
+				t.Errorf("%s: Parse(Transform(%s)) = %+v", tt.adapter.Name(), rule.Name, got)
+			}
+		}
+	}
+}
+
+// dropAlwaysGlobs removes the globs of a rule that always applies.
+func dropAlwaysGlobs(r *Rule) {
+	if r.AlwaysApply {
+		r.Globs = nil
+	}
+}
+
+func TestCursorAdapter_ParseHandWritten(t *testing.T) {
+	content := "---\ndescription: \"API: handlers\"\nglobs: api/**/*.go,cmd/**\nalwaysApply: false\n---\n\n# API\n\nUse chi.\n"
+	rule, ok, err := (&CursorAdapter{}).Parse("api.mdc", content)
+	if err != nil || !ok {
+		t.Fatalf("Parse() = %v, %v", ok, err)
+	}
+	if rule.Description != "API: handlers" || !slices.Equal(rule.Globs, []string{"api/**/*.go", "cmd/**"}) ||
+		rule.Content != "# API\n\nUse chi." {
+		t.Errorf("Parse() = %+v", rule)
+	}
+	if _, ok, _ := (&CursorAdapter{}).Parse("README.md", content); ok {
+		t.Error("Parse() should skip files without the .mdc extension")
+	}
+}
+
+func TestParse_BraceGlobs(t *testing.T) {
+	want := []string{"src/**/*.{ts,tsx}", "cmd/**"}
+	for _, tt := range []struct {
+		adapter  ImportingAdapter
+		filename string
+		content  string
+	}{
+		{&CursorAdapter{}, "web.mdc", "---\ndescription: Web\nglobs: src/**/*.{ts,tsx},cmd/**\n---\n\n# Web\n"},
+		{&KiroAdapter{}, "web.md", "---\ninclusion: fileMatch\nfileMatchPattern: ['src/**/*.{ts,tsx}', 'cmd/**']\n---\n\n# Web\n"},
+		{&ClineAdapter{}, "web.md", "---\npaths:\n  - src/**/*.{ts,tsx}\n  - cmd/**\n---\n\n# Web\n"},
+	} {
+		rule, ok, err := tt.adapter.Parse(tt.filename, tt.content)
+		if err != nil || !ok {
+			t.Fatalf("%s: Parse() = %v, %v", tt.adapter.Name(), ok, err)
+		}
+		if !slices.Equal(rule.Globs, want) {
+			t.Errorf("%s: Parse() globs = %q, want %q", tt.adapter.Name(), rule.Globs, want)
+		}
+	}
+}
+
+func TestImportRules(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, ".cursor", "rules")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"api.mdc":     "---\ndescription: API\nglobs: api/**\n---\n\n# API\n",
+		"enumgen.mdc": "---\ndescription: enumgen\n---\n\n" + ManagedSection("enumgen", "# enumgen"),
+		"notes.txt":   "not a rule",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rules, skipped, err := ImportRules(&CursorAdapter{}, root)
+	if err != nil {
+		t.Fatalf("ImportRules() error = %v", err)
+	}
+	if len(rules) != 1 || rules[0].Path != ".cursor/rules/api.mdc" || rules[0].Rule.Name != "api" {
+		t.Errorf("ImportRules() rules = %+v", rules)
+	}
+	if !slices.Equal(skipped, []string{".cursor/rules/enumgen.mdc"}) {
+		t.Errorf("ImportRules() skipped = %v", skipped)
+	}
+
+	// The source file is read back into the same rule.
+	content, err := FormatRuleFile(rules[0].Rule)
+	if err != nil {
+		t.Fatalf("FormatRuleFile() error = %v", err)
+	}
+	path := filepath.Join(root, "api.md")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadRuleFromFile(path)
+	if err != nil {
+		t.Fatalf("LoadRuleFromFile() error = %v\n%s", err, content)
+	}
+	if loaded.Description != "API" || !slices.Equal(loaded.Globs, []string{"api/**"}) || loaded.Content != "# API" {
+		t.Errorf("LoadRuleFromFile() = %+v\n%s", loaded, content)
+	}
+}
@@ -0,0 +1,332 @@
+package genkit
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SourcedRule is a rule together with where it is defined, such as the path
+// of a rule source file or "tool enumgen".
+type SourcedRule struct {
+	Source string
+	Rule   Rule
+}
+
+// RuleIssue is a problem found by LintRuleDir or LintRules.
+type RuleIssue struct {
+	// Source is where the rule is defined, see SourcedRule.
+	Source string
+
+	// Rule is the name of the rule, empty if the file could not be loaded.
+	Rule string
+
+	Message string
+}
+
+// String formats the issue as "source: rule: message".
+func (i RuleIssue) String() string {
+	if i.Rule == "" {
+		return i.Source + ": " + i.Message
+	}
+	return i.Source + ": " + i.Rule + ": " + i.Message
+}
+
+// LintRuleDir loads the rule files of dir like LoadRulesFromDir and checks
+// their frontmatter keys against RuleFrontmatter. Unlike LoadRulesFromDir it
+// does not stop at a file that fails to load; the failure is returned as an
+// issue and the file is skipped.
+func LintRuleDir(dir string, opts ...RuleLoadOptions) ([]SourcedRule, []RuleIssue, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("read directory %s: %w", dir, err)
+	}
+
+	known := ruleFrontmatterKeys()
+	var rules []SourcedRule
+	var issues []RuleIssue
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") {
+			continue
+		}
+		filePath := filepath.Join(dir, entry.Name())
+		name := strings.TrimSuffix(entry.Name(), ".md")
+
+		keys, err := frontmatterKeys(filePath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("read %s: %w", filePath, err)
+		}
+		for _, key := range keys {
+			if !slices.Contains(known, key) {
+				issues = append(issues, RuleIssue{
+					Source:  filePath,
+					Rule:    name,
+					Message: fmt.Sprintf("unknown frontmatter key %q, known keys: %s", key, strings.Join(known, ", ")),
+				})
+			}
+		}
+
+		rule, err := LoadRuleFromFile(filePath, opts...)
+		if err != nil {
+			// YAML errors span lines, one per field
+			message := strings.Join(strings.Fields(err.Error()), " ")
+			issues = append(issues, RuleIssue{Source: filePath, Rule: name, Message: message})
+			continue
+		}
+		rules = append(rules, SourcedRule{Source: filePath, Rule: rule})
+	}
+	return rules, issues, nil
+}
+
+// frontmatterKeys returns the top-level keys of the frontmatter of the rule
+// file path, in order. Invalid YAML yields no keys; LoadRuleFromFile reports it.
+func frontmatterKeys(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	scanner := bufio.NewScanner(file)
+	if !scanner.Scan() || strings.TrimSpace(scanner.Text()) != "---" {
+		return nil, scanner.Err()
+	}
+	var yamlBuilder strings.Builder
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) == "---" {
+			break
+		}
+		yamlBuilder.WriteString(scanner.Text())
+		yamlBuilder.WriteString("\n")
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(yamlBuilder.String()), &node); err != nil || len(node.Content) == 0 {
+		return nil, nil
+	}
+	mapping := node.Content[0]
+	if mapping.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	var keys []string
+	for i := 0; i < len(mapping.Content); i += 2 {
+		keys = append(keys, mapping.Content[i].Value)
+	}
+	return keys, nil
+}
+
+// ruleFrontmatterKeys returns the YAML keys of RuleFrontmatter.
+func ruleFrontmatterKeys() []string {
+	var keys []string
+	t := reflect.TypeFor[RuleFrontmatter]()
+	for i := range t.NumField() {
+		if key, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ","); key != "" && key != "-" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// LintRules checks rules for:
+//   - names defined more than once
+//   - globs that are invalid or match no file under root
+//   - empty descriptions of rules that do not always apply, since agents
+//     decide from the description whether to apply them
+func LintRules(rules []SourcedRule, root string) ([]RuleIssue, error) {
+	files, err := listProjectFiles(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []RuleIssue
+	defined := make(map[string]string)
+	for _, sr := range rules {
+		rule := sr.Rule
+		report := func(format string, args ...any) {
+			issues = append(issues, RuleIssue{Source: sr.Source, Rule: rule.Name, Message: fmt.Sprintf(format, args...)})
+		}
+
+		if source, ok := defined[rule.Name]; ok {
+			report("duplicate rule name, also defined by %s", source)
+		} else {
+			defined[rule.Name] = sr.Source
+		}
+
+		for _, glob := range rule.Globs {
+			if err := ValidateGlob(glob); err != nil {
+				report("invalid glob %q: %v", glob, err)
+				continue
+			}
+			if !slices.ContainsFunc(files, func(name string) bool { return MatchGlob(glob, name) }) {
+				report("glob %q matches no file", glob)
+			}
+		}
+
+		if !rule.AlwaysApply && strings.TrimSpace(rule.Description) == "" {
+			report("description is empty, but the rule does not always apply")
+		}
+	}
+	return issues, nil
+}
+
+// listProjectFiles returns the slash-separated paths of the files under
+// root, relative to it. The .git and node_modules directories are skipped.
+func listProjectFiles(root string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != root && (d.Name() == ".git" || d.Name() == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list files in %s: %w", root, err)
+	}
+	return files, nil
+}
+
+// ValidateGlob reports whether glob is a valid rule glob, see MatchGlob.
+func ValidateGlob(glob string) error {
+	patterns, err := expandBraces(glob)
+	if err != nil {
+		return err
+	}
+	for _, pattern := range patterns {
+		if strings.TrimSpace(pattern) == "" {
+			return errors.New("empty pattern")
+		}
+		for _, segment := range strings.Split(pattern, "/") {
+			if _, err := path.Match(segment, ""); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// MatchGlob reports whether the slash-separated relative path name matches
+// glob, as agents match rule globs. A "**" segment matches any number of
+// directories, "{a,b}" matches either alternative, and the other segments
+// are matched with path.Match. A glob without "/", such as "*.go", matches
+// the base name of files in any directory. Invalid globs match nothing.
+func MatchGlob(glob, name string) bool {
+	patterns, err := expandBraces(glob)
+	if err != nil {
+		return false
+	}
+	for _, pattern := range patterns {
+		pattern = strings.TrimPrefix(strings.TrimPrefix(pattern, "./"), "/")
+		if !strings.Contains(pattern, "/") {
+			pattern = "**/" + pattern
+		}
+		if matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments matches path segments against pattern segments.
+func matchSegments(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchSegments(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	ok, err := path.Match(pattern[0], name[0])
+	return ok && err == nil && matchSegments(pattern[1:], name[1:])
+}
+
+// expandBraces expands the first "{a,b}" group of glob and, recursively,
+// the rest.
+func expandBraces(glob string) ([]string, error) {
+	start := strings.IndexByte(glob, '{')
+	if start < 0 {
+		if strings.ContainsRune(glob, '}') {
+			return nil, errors.New("unmatched '}'")
+		}
+		return []string{glob}, nil
+	}
+	depth, end := 0, -1
+	for i := start; i < len(glob) && end < 0; i++ {
+		switch glob[i] {
+		case '{':
+			depth++
+		case '}':
+			if depth--; depth == 0 {
+				end = i
+			}
+		}
+	}
+	if end < 0 {
+		return nil, errors.New("unmatched '{'")
+	}
+
+	alternatives := splitOutsideBraces(glob[start+1 : end])
+
+	var expanded []string
+	for _, alt := range alternatives {
+		patterns, err := expandBraces(glob[:start] + alt + glob[end+1:])
+		if err != nil {
+			return nil, err
+		}
+		expanded = append(expanded, patterns...)
+	}
+	return expanded, nil
+}
+
+// splitOutsideBraces splits s at the commas that are not inside "{}", so
+// that "a,{b,c}" yields "a" and "{b,c}".
+func splitOutsideBraces(s string) []string {
+	var parts []string
+	depth, last := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[last:i])
+				last = i + 1
+			}
+		}
+	}
+	return append(parts, s[last:])
+}
@@ -0,0 +1,111 @@
+package genkit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		glob, name string
+		want       bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "internal/model/user.go", true},
+		{"*.go", "main.mod", false},
+		{"**/*.go", "main.go", true},
+		{"**/devgen.toml", "cmd/devgen.toml", true},
+		{"api/**", "api/v1/user.go", true},
+		{"api/**", "apis/user.go", false},
+		{"api/*.go", "api/v1/user.go", false},
+		{"./api/*.go", "api/user.go", true},
+		{"src/**/*.{ts,tsx}", "src/app/page.tsx", true},
+		{"src/**/*.{ts,tsx}", "src/app/page.js", false},
+		{"{cmd,internal/{a,b}}/*.go", "internal/b/b.go", true},
+		{"Makefile", "Makefile", true},
+		{"cmd/{a,b", "cmd/a", false},
+	}
+	for _, tt := range tests {
+		if got := MatchGlob(tt.glob, tt.name); got != tt.want {
+			t.Errorf("MatchGlob(%q, %q) = %v, want %v", tt.glob, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidateGlob(t *testing.T) {
+	for _, glob := range []string{"**/*.go", "src/*.{ts,tsx}", "[a-z]*.md"} {
+		if err := ValidateGlob(glob); err != nil {
+			t.Errorf("ValidateGlob(%q) = %v", glob, err)
+		}
+	}
+	for _, glob := range []string{"cmd/{a,b", "a}b", "[a-.go", "{a,}"} {
+		if err := ValidateGlob(glob); err == nil {
+			t.Errorf("ValidateGlob(%q) should fail", glob)
+		}
+	}
+}
+
+func TestLintRuleDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"ok.md":     "---\ndescription: OK\nglobs: ['*.go']\nexcludeAgents: [cline]\n---\n\n# OK\n",
+		"typo.md":   "---\ndescrption: Typo\n---\n\n# Typo\n",
+		"types.md":  "---\nglobs: 3\nalwaysApply: yes please\n---\n\n# Types\n",
+		"plain.md":  "# Plain\n",
+		"notes.txt": "---\nfoo: bar\n---\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rules, issues, err := LintRuleDir(dir)
+	if err != nil {
+		t.Fatalf("LintRuleDir() error = %v", err)
+	}
+	if len(rules) != 3 {
+		t.Errorf("LintRuleDir() rules = %+v", rules)
+	}
+	if len(issues) != 2 || issues[0].Rule != "types" || issues[1].Rule != "typo" ||
+		!strings.Contains(issues[1].Message, `unknown frontmatter key "descrption"`) {
+		t.Errorf("LintRuleDir() issues = %v", issues)
+	}
+}
+
+func TestLintRules(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"main.go", ".git/config"} {
+		if err := os.WriteFile(filepath.Join(root, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	issues, err := LintRules([]SourcedRule{
+		{Source: "tool a", Rule: Rule{Name: "a", Description: "A", Globs: []string{"*.go"}}},
+		{Source: "tool b", Rule: Rule{Name: "a", AlwaysApply: true}},
+		{Source: "tool c", Rule: Rule{Name: "c", Globs: []string{".git/*", "[.go"}}},
+	}, root)
+	if err != nil {
+		t.Fatalf("LintRules() error = %v", err)
+	}
+	want := []string{
+		"tool b: a: duplicate rule name, also defined by tool a",
+		`tool c: c: glob ".git/*" matches no file`,
+		`tool c: c: invalid glob "[.go": syntax error in pattern`,
+		"tool c: c: description is empty, but the rule does not always apply",
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("LintRules() = %v", issues)
+	}
+	for i, issue := range issues {
+		if issue.String() != want[i] {
+			t.Errorf("issue %d = %q, want %q", i, issue, want[i])
+		}
+	}
+}
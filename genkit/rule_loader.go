@@ -12,14 +12,28 @@ import (
 
 // RuleFrontmatter represents the YAML frontmatter of a rule file.
 type RuleFrontmatter struct {
-	Description string   `yaml:"description"`
-	Globs       []string `yaml:"globs"`
-	AlwaysApply bool     `yaml:"alwaysApply"`
+	Description   string   `yaml:"description,omitempty"`
+	Globs         []string `yaml:"globs,omitempty,flow"`
+	AlwaysApply   bool     `yaml:"alwaysApply,omitempty"`
+	Agents        []string `yaml:"agents,omitempty,flow"`
+	ExcludeAgents []string `yaml:"excludeAgents,omitempty,flow"`
+	MaxChars      int      `yaml:"maxChars,omitempty"`
+	MaxTokens     int      `yaml:"maxTokens,omitempty"`
+
+	// Template makes the content a Go template, executed with the
+	// RuleTemplateData of RuleLoadOptions.
+	Template bool `yaml:"template,omitempty"`
+}
+
+// RuleLoadOptions configures loading rule files.
+type RuleLoadOptions struct {
+	// Data is passed to the content of rule files with 'template: true'.
+	Data *RuleTemplateData
 }
 
 // LoadRulesFromDir loads all rule files from a directory.
 // Rule files must be .md files with YAML frontmatter.
-func LoadRulesFromDir(dir string) ([]Rule, error) {
+func LoadRulesFromDir(dir string, opts ...RuleLoadOptions) ([]Rule, error) {
 	// Check if directory exists
 	info, err := os.Stat(dir)
 	if os.IsNotExist(err) {
@@ -48,7 +62,7 @@ func LoadRulesFromDir(dir string) ([]Rule, error) {
 		}
 
 		filePath := filepath.Join(dir, entry.Name())
-		rule, err := LoadRuleFromFile(filePath)
+		rule, err := LoadRuleFromFile(filePath, opts...)
 		if err != nil {
 			return nil, fmt.Errorf("load rule %s: %w", filePath, err)
 		}
@@ -59,7 +73,7 @@ func LoadRulesFromDir(dir string) ([]Rule, error) {
 }
 
 // LoadRuleFromFile loads a single rule from a markdown file with YAML frontmatter.
-func LoadRuleFromFile(path string) (Rule, error) {
+func LoadRuleFromFile(path string, opts ...RuleLoadOptions) (Rule, error) {
 	file, err := os.Open(path)
 	if err != nil {
 		return Rule{}, fmt.Errorf("open file: %w", err)
@@ -91,12 +105,26 @@ func LoadRuleFromFile(path string) (Rule, error) {
 	// Extract rule name from filename (without extension)
 	name := strings.TrimSuffix(filepath.Base(path), ".md")
 
+	content := contentBuilder.String()
+	if frontmatter.Template {
+		var data *RuleTemplateData
+		if len(opts) > 0 {
+			data = opts[0].Data
+		}
+		if content, err = executeRuleTemplate(name, content, data); err != nil {
+			return Rule{}, err
+		}
+	}
+
 	return Rule{
-		Name:        name,
-		Description: frontmatter.Description,
-		Globs:       frontmatter.Globs,
-		AlwaysApply: frontmatter.AlwaysApply,
-		Content:     strings.TrimSpace(contentBuilder.String()),
+		Name:          name,
+		Description:   frontmatter.Description,
+		Globs:         frontmatter.Globs,
+		AlwaysApply:   frontmatter.AlwaysApply,
+		Agents:        frontmatter.Agents,
+		ExcludeAgents: frontmatter.ExcludeAgents,
+		Budget:        RuleBudget{Chars: frontmatter.MaxChars, Tokens: frontmatter.MaxTokens},
+		Content:       strings.TrimSpace(content),
 	}, nil
 }
 
@@ -190,3 +190,57 @@ alwaysApply: false
 		t.Errorf("rule.Globs = %v, want [**/*.go]", rule.Globs)
 	}
 }
+
+func TestLoadRuleFromFile_Template(t *testing.T) {
+	tmpDir := t.TempDir()
+	source := `---
+description: Project conventions
+template: true
+agents: [cursor, kiro]
+excludeAgents: [kiro]
+---
+
+# Conventions for {{ .Module }}
+
+{{ with .Tool "enumgen" }}{{ range .Annotations }}- ` + "`enumgen:@{{ .Name }}`" + `: {{ .Doc | firstLine }}
+{{ end }}{{ end }}Output suffix: {{ (.Tool "enumgen").OutputSuffix }}
+`
+	path := filepath.Join(tmpDir, "conventions.md")
+	if err := os.WriteFile(path, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	data := &RuleTemplateData{
+		Module: "example.com/shop",
+		Tools: []RuleTemplateTool{{Name: "enumgen", ToolConfig: ToolConfig{
+			OutputSuffix: "_enum.go",
+			Annotations:  []AnnotationConfig{{Name: "enum", Doc: "Generates enum helpers.\n\nMore."}},
+		}}},
+	}
+	rule, err := LoadRuleFromFile(path, RuleLoadOptions{Data: data})
+	if err != nil {
+		t.Fatalf("LoadRuleFromFile() error = %v", err)
+	}
+	want := "# Conventions for example.com/shop\n\n- `enumgen:@enum`: Generates enum helpers.\nOutput suffix: _enum.go"
+	if rule.Content != want {
+		t.Errorf("Content = %q, want %q", rule.Content, want)
+	}
+	if !rule.ForAgent("cursor") || rule.ForAgent("kiro") || rule.ForAgent("copilot") {
+		t.Errorf("ForAgent() with Agents = %v, ExcludeAgents = %v", rule.Agents, rule.ExcludeAgents)
+	}
+
+	// Content without 'template: true' is kept verbatim.
+	if err := os.WriteFile(path, []byte("Use {{ .Module }} literally.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if rule, err := LoadRuleFromFile(path, RuleLoadOptions{Data: data}); err != nil || rule.Content != "Use {{ .Module }} literally." {
+		t.Errorf("LoadRuleFromFile() of a plain rule = %q, %v", rule.Content, err)
+	}
+
+	if err := os.WriteFile(path, []byte("---\ntemplate: true\n---\n{{ .Missing }}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadRuleFromFile(path, RuleLoadOptions{Data: data}); err == nil {
+		t.Error("LoadRuleFromFile() with an unknown field should fail")
+	}
+}
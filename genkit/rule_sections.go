@@ -0,0 +1,258 @@
+package genkit
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Markers around the sections devgen manages in a rule file. Everything
+// outside them belongs to the user and is kept when rules are written again.
+const (
+	ruleSectionBegin = "<!-- devgen:begin "
+	ruleSectionEnd   = "<!-- devgen:end -->"
+)
+
+// legacyFrontmatterKeys are the frontmatter keys of the rule files devgen
+// wrote before managed sections existed, with the Kiro, CodeBuddy and Cursor
+// adapters. Such files are replaced as a whole, see isLegacyRuleFile.
+var legacyFrontmatterKeys = []string{"description", "inclusion", "fileMatchPattern", "globs", "alwaysApply"}
+
+// ManagedSection wraps content in the markers of the managed section name.
+func ManagedSection(name, content string) string {
+	return ruleSectionBegin + name + " -->\n" + strings.TrimSpace(content) + "\n" + ruleSectionEnd + "\n"
+}
+
+// HasManagedSections reports whether content contains a section managed by
+// devgen.
+func HasManagedSections(content string) bool {
+	return strings.Contains(content, ruleSectionBegin)
+}
+
+// MergeRuleFile merges generated, the content devgen renders for a rule
+// file, into existing, the content of the file on disk:
+//
+//   - managed sections of existing are replaced by the sections of the same
+//     name in generated, or removed if generated has none;
+//   - sections only in generated are inserted after the last managed section,
+//     or appended;
+//   - the frontmatter of generated replaces the frontmatter of existing;
+//   - everything else in existing is left untouched.
+//
+// The text of generated outside its sections, such as a title, is only used
+// when the file is created. MergeRuleFile returns an empty string if the
+// merged file would contain no managed sections and nothing written by the
+// user, in which case the file should be removed.
+func MergeRuleFile(existing, generated string) (string, error) {
+	genFrontmatter, genBody := splitRuleFrontmatter(generated)
+	genSegments, err := parseRuleSections(genBody)
+	if err != nil {
+		return "", fmt.Errorf("generated content: %w", err)
+	}
+	sections := make(map[string]string)
+	var order []string
+	var genOutside strings.Builder
+	for _, seg := range genSegments {
+		if seg.name == "" {
+			genOutside.WriteString(seg.text)
+			continue
+		}
+		if _, ok := sections[seg.name]; ok {
+			return "", fmt.Errorf("generated content: duplicate section %s", seg.name)
+		}
+		sections[seg.name] = seg.text
+		order = append(order, seg.name)
+	}
+
+	exFrontmatter, exBody := splitRuleFrontmatter(existing)
+	exSegments, err := parseRuleSections(exBody)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(existing) == "" || isLegacyRuleFile(exFrontmatter, exSegments, genFrontmatter) {
+		if len(order) == 0 {
+			return "", nil
+		}
+		return generated, nil
+	}
+
+	var body strings.Builder
+	used := make(map[string]bool)
+	lastSection := -1
+	skipBlank := false
+	for _, seg := range exSegments {
+		if seg.name == "" {
+			if skipBlank {
+				seg.text = strings.TrimPrefix(seg.text, "\n")
+			}
+			body.WriteString(seg.text)
+			skipBlank = false
+			continue
+		}
+		text, ok := sections[seg.name]
+		if !ok || used[seg.name] {
+			// Drop the section together with the blank line around it.
+			if s := body.String(); strings.HasSuffix(s, "\n\n") {
+				body.Reset()
+				body.WriteString(s[:len(s)-1])
+			} else if s == "" {
+				skipBlank = true
+			}
+			continue
+		}
+		body.WriteString(text)
+		used[seg.name] = true
+		lastSection = body.Len()
+	}
+
+	var added strings.Builder
+	for _, name := range order {
+		if !used[name] {
+			added.WriteString("\n" + sections[name])
+		}
+	}
+
+	result := body.String()
+	switch {
+	case added.Len() == 0:
+	case lastSection >= 0:
+		result = result[:lastSection] + added.String() + result[lastSection:]
+	default:
+		if result = strings.TrimRight(result, "\n"); result != "" {
+			result += "\n" + added.String()
+		} else {
+			result = strings.TrimPrefix(added.String(), "\n")
+		}
+	}
+
+	if len(order) == 0 && !HasManagedSections(result) {
+		if rest := strings.TrimSpace(result); rest == "" || rest == strings.TrimSpace(genOutside.String()) {
+			return "", nil
+		}
+	}
+
+	frontmatter := exFrontmatter
+	if genFrontmatter != "" {
+		frontmatter = genFrontmatter
+	}
+	return frontmatter + result, nil
+}
+
+// isLegacyRuleFile reports whether a file with the frontmatter frontmatter
+// and the segments segments, at the path devgen renders a rule with the
+// frontmatter genFrontmatter to, was written by devgen before managed
+// sections existed. Those files are the frontmatter of the rule, which always
+// has a description and only the keys of legacyFrontmatterKeys, followed by
+// its content, without markers.
+func isLegacyRuleFile(frontmatter string, segments []ruleSegment, genFrontmatter string) bool {
+	if frontmatter == "" || genFrontmatter == "" {
+		return false
+	}
+	for _, seg := range segments {
+		if seg.name != "" {
+			return false
+		}
+	}
+	fm, _ := parseRuleFile(frontmatter)
+	if _, ok := fm["description"]; !ok {
+		return false
+	}
+	for key := range fm {
+		if !slices.Contains(legacyFrontmatterKeys, key) {
+			return false
+		}
+	}
+	return true
+}
+
+// ruleSegment is a part of a rule file: a managed section including its
+// markers, or the text between sections, which has an empty name.
+type ruleSegment struct {
+	name string
+	text string
+}
+
+// parseRuleSections splits content into managed sections and the text
+// between them.
+func parseRuleSections(content string) ([]ruleSegment, error) {
+	var segments []ruleSegment
+	var text strings.Builder
+	var current string
+	inSection := false
+
+	for _, line := range strings.SplitAfter(content, "\n") {
+		if line == "" {
+			continue
+		}
+		if !inSection {
+			if name, ok := ruleSectionName(line); ok {
+				if text.Len() > 0 {
+					segments = append(segments, ruleSegment{text: text.String()})
+					text.Reset()
+				}
+				current, inSection = name, true
+			} else if strings.TrimSpace(line) == ruleSectionEnd {
+				return nil, fmt.Errorf("devgen:end marker without devgen:begin")
+			}
+			text.WriteString(line)
+			continue
+		}
+
+		if _, ok := ruleSectionName(line); ok {
+			return nil, fmt.Errorf("section %s is not closed before the next devgen:begin marker", current)
+		}
+		text.WriteString(line)
+		if strings.TrimSpace(line) == ruleSectionEnd {
+			if !strings.HasSuffix(line, "\n") {
+				text.WriteString("\n")
+			}
+			segments = append(segments, ruleSegment{name: current, text: text.String()})
+			text.Reset()
+			inSection = false
+		}
+	}
+	if inSection {
+		return nil, fmt.Errorf("section %s has no devgen:end marker", current)
+	}
+	if text.Len() > 0 {
+		segments = append(segments, ruleSegment{text: text.String()})
+	}
+	return segments, nil
+}
+
+// ruleSectionName returns the section name if line is a devgen:begin marker.
+func ruleSectionName(line string) (string, bool) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, ruleSectionBegin) || !strings.HasSuffix(line, "-->") {
+		return "", false
+	}
+	name := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(line, ruleSectionBegin), "-->"))
+	return name, name != ""
+}
+
+// splitRuleFrontmatter splits content into its YAML frontmatter, including
+// the --- delimiters and the blank line after them, and the body.
+func splitRuleFrontmatter(content string) (frontmatter, body string) {
+	if !strings.HasPrefix(content, "---\n") {
+		return "", content
+	}
+	end := strings.Index(content[4:], "\n---\n")
+	if end < 0 {
+		return "", content
+	}
+	end += 4 + len("\n---\n")
+	for strings.HasPrefix(content[end:], "\n") {
+		end++
+	}
+	return content[:end], content[end:]
+}
+
+// managedRuleContent wraps the body of content, the output of
+// AgentAdapter.Transform, in the managed section name.
+func managedRuleContent(name, content string) string {
+	frontmatter, body := splitRuleFrontmatter(content)
+	if frontmatter != "" && !strings.HasSuffix(frontmatter, "\n\n") {
+		frontmatter += "\n"
+	}
+	return frontmatter + ManagedSection(name, body)
+}
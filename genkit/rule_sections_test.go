@@ -0,0 +1,110 @@
+package genkit
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMergeRuleFile(t *testing.T) {
+	section := func(name, content string) string { return ManagedSection(name, content) }
+	generated := "# AGENTS.md\n\n" + section("a", "## A\n\nnew a") + "\n" + section("c", "## C")
+
+	tests := []struct {
+		name     string
+		existing string
+		want     string
+	}{
+		{"new file", "", generated},
+		{
+			"replace, remove and insert sections",
+			"# Notes\n\nkeep me\n\n" + section("a", "old a") + "\n" + section("b", "old b") + "\nfooter\n",
+			"# Notes\n\nkeep me\n\n" + section("a", "## A\n\nnew a") + "\n" + section("c", "## C") + "\nfooter\n",
+		},
+		{
+			"append to hand-written file",
+			"# Notes\n",
+			"# Notes\n\n" + section("a", "## A\n\nnew a") + "\n" + section("c", "## C"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MergeRuleFile(tt.existing, generated)
+			if err != nil {
+				t.Fatalf("MergeRuleFile() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("MergeRuleFile() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeRuleFileFrontmatter(t *testing.T) {
+	existing := "---\ninclusion: always\n---\n\n" + ManagedSection("a", "old") + "\nmine\n"
+	generated := "---\ninclusion: fileMatch\n---\n\n" + ManagedSection("a", "new")
+
+	got, err := MergeRuleFile(existing, generated)
+	if err != nil {
+		t.Fatalf("MergeRuleFile() error = %v", err)
+	}
+	if want := generated + "\nmine\n"; got != want {
+		t.Errorf("MergeRuleFile() =\n%s\nwant\n%s", got, want)
+	}
+
+	// The generated file of a rule that was removed is deleted, unless the
+	// user added something to it.
+	if got, err := MergeRuleFile(generated, ""); err != nil || got != "" {
+		t.Errorf("MergeRuleFile(stale) = %q, %v, want empty", got, err)
+	}
+	if got, err := MergeRuleFile(existing, ""); err != nil || got != "---\ninclusion: always\n---\n\nmine\n" {
+		t.Errorf("MergeRuleFile(stale with user content) = %q, %v", got, err)
+	}
+	if got, err := MergeRuleFile("", "# Copilot Instructions\n"); err != nil || got != "" {
+		t.Errorf("MergeRuleFile() without sections = %q, %v, want empty", got, err)
+	}
+}
+
+func TestMergeRuleFileLegacy(t *testing.T) {
+	rule := Rule{Name: "a", Description: "A", Globs: []string{"**/*.go"}, Content: "# A\n\nnew a"}
+	// Files as devgen wrote them before managed sections: frontmatter + content
+	legacy := map[AgentAdapter]string{
+		&KiroAdapter{}:   "---\ndescription: A\ninclusion: fileMatch\nfileMatchPattern: ['**/*.go']\n---\n\n# A\n\nold a",
+		&CursorAdapter{}: "---\ndescription: A\nglobs: **/*.go\nalwaysApply: false\n---\n\n# A\n\nold a",
+	}
+	for adapter, existing := range legacy {
+		files, err := RenderRules(adapter, []Rule{rule})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := MergeRuleFile(existing, files[0].Content)
+		if err != nil {
+			t.Fatalf("%s: MergeRuleFile() error = %v", adapter.Name(), err)
+		}
+		if got != files[0].Content {
+			t.Errorf("%s: MergeRuleFile(legacy) =\n%s\nwant\n%s", adapter.Name(), got, files[0].Content)
+		}
+	}
+
+	// Hand-written files with other frontmatter keep their text.
+	files, err := RenderRules(&CursorAdapter{}, []Rule{rule})
+	if err != nil {
+		t.Fatal(err)
+	}
+	existing := "---\ndescription: Mine\nauthor: me\n---\n\nmine\n"
+	got, err := MergeRuleFile(existing, files[0].Content)
+	if err != nil || !strings.Contains(got, "mine\n") || !HasManagedSections(got) {
+		t.Errorf("MergeRuleFile(hand-written) = %q, %v", got, err)
+	}
+}
+
+func TestMergeRuleFileInvalid(t *testing.T) {
+	for _, existing := range []string{
+		"<!-- devgen:begin a -->\nno end\n",
+		"<!-- devgen:end -->\n",
+		"<!-- devgen:begin a -->\n<!-- devgen:begin b -->\n<!-- devgen:end -->\n",
+	} {
+		if _, err := MergeRuleFile(existing, ManagedSection("a", "x")); err == nil || !strings.Contains(err.Error(), "devgen:") {
+			t.Errorf("MergeRuleFile(%q) error = %v", existing, err)
+		}
+	}
+}
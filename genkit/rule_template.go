@@ -0,0 +1,97 @@
+package genkit
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// RuleTemplateData is the data of rule files with 'template: true' in their
+// frontmatter. For example:
+//
+//	Annotations of enumgen:
+//	{{ range (.Tool "enumgen").Annotations }}
+//	- `enumgen:@{{ .Name }}`: {{ .Doc | firstLine }}
+//	{{ end }}
+type RuleTemplateData struct {
+	// Module is the module path of the project, e.g. "example.com/shop".
+	Module string
+
+	// Tools are the loaded tools, sorted by name.
+	Tools []RuleTemplateTool
+
+	// Config is the project configuration from devgen.toml.
+	Config *Config
+}
+
+// RuleTemplateTool describes a tool in RuleTemplateData. The embedded
+// ToolConfig combines the tool's own configuration, see ConfigurableTool,
+// with its [tools.<name>] table in devgen.toml.
+type RuleTemplateTool struct {
+	Name string
+	ToolConfig
+}
+
+// Tool returns the tool with the given name, or nil if it is not loaded.
+func (d *RuleTemplateData) Tool(name string) *RuleTemplateTool {
+	for i := range d.Tools {
+		if d.Tools[i].Name == name {
+			return &d.Tools[i]
+		}
+	}
+	return nil
+}
+
+// NewRuleTemplateData returns the template data of the project containing
+// dir, with the configuration cfg and the loaded tools.
+func NewRuleTemplateData(dir string, cfg *Config, tools []Tool) (*RuleTemplateData, error) {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	data := &RuleTemplateData{Config: cfg}
+
+	mod, _, err := findGoMod(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read go.mod: %w", err)
+	}
+	if mod != nil && mod.Module != nil {
+		data.Module = mod.Module.Mod.Path
+	}
+
+	configs := MergeToolConfigs(CollectToolConfigs(tools), cfg.Tools)
+	for _, tool := range tools {
+		data.Tools = append(data.Tools, RuleTemplateTool{Name: tool.Name(), ToolConfig: configs[tool.Name()]})
+	}
+	slices.SortFunc(data.Tools, func(a, b RuleTemplateTool) int { return strings.Compare(a.Name, b.Name) })
+	return data, nil
+}
+
+// ruleTemplateFuncs are the functions available in rule templates besides
+// the text/template builtins.
+var ruleTemplateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"trim":  strings.TrimSpace,
+	"firstLine": func(s string) string {
+		line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+		return line
+	},
+}
+
+// executeRuleTemplate executes the content of the rule name as a template.
+func executeRuleTemplate(name, content string, data *RuleTemplateData) (string, error) {
+	if data == nil {
+		data = &RuleTemplateData{Config: &Config{}}
+	}
+	tmpl, err := template.New(name).Funcs(ruleTemplateFuncs).Option("missingkey=error").Parse(content)
+	if err != nil {
+		return "", fmt.Errorf("parse template: %w", err)
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("execute template: %w", err)
+	}
+	return sb.String(), nil
+}
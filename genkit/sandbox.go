@@ -0,0 +1,185 @@
+package genkit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Diagnostic codes reported for generated files that violate a write policy.
+const (
+	// CodeWriteOutsideModule reports a file outside the modules of the
+	// processed packages.
+	CodeWriteOutsideModule = "write-outside-module"
+
+	// CodeWriteOutsidePackage reports a file outside the directories of the
+	// processed packages.
+	CodeWriteOutsidePackage = "write-outside-package"
+)
+
+// WritePolicy restricts where the files generated by a plugin tool may be
+// written. A file is allowed if it is in the directory of a package the tool
+// was run with and inside that package's module, or inside one of
+// AllowedPaths. Packages the tool loads itself do not widen the policy.
+//
+// The policy applies to files created through NewGeneratedFile. It cannot
+// stop Go plugins or executables from touching the file system directly;
+// only wasm plugins run without file system access.
+type WritePolicy struct {
+	// Tool is the name of the restricted tool.
+	Tool string
+
+	// Plugin is the name of the plugin providing the tool.
+	Plugin string
+
+	// AllowedPaths are absolute files or directories the tool may write to
+	// in addition to the package directories.
+	AllowedPaths []string
+
+	// packageDirs and moduleDirs are the directories of the packages the
+	// tool was run with and of their modules, recorded by SetWritePolicy.
+	packageDirs map[string]bool
+	moduleDirs  []string
+}
+
+// WriteError is returned by Write and DryRun if generated files violate
+// their write policy. Nothing is written in that case.
+type WriteError struct {
+	Diagnostics []Diagnostic
+}
+
+// Error implements error.
+func (e *WriteError) Error() string {
+	lines := make([]string, len(e.Diagnostics))
+	for i, d := range e.Diagnostics {
+		lines[i] = fmt.Sprintf("%s: %s", d.Tool, d.Message)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SetWritePolicy restricts the files created by NewGeneratedFile until the
+// next call; nil lifts the restriction. The files may be written to the
+// directories of the packages loaded at the time of the call, so it must be
+// called right before the tool runs. devgen runs every plugin tool with the
+// policy of its plugin:
+//
+//	gen.SetWritePolicy(loader.WritePolicy(tool.Name()))
+//	err := tool.Run(gen, log)
+//	gen.SetWritePolicy(nil)
+func (g *Generator) SetWritePolicy(p *WritePolicy) {
+	if p != nil {
+		scoped := *p
+		scoped.packageDirs, scoped.moduleDirs = g.packageDirs()
+		p = &scoped
+	}
+	g.policy = p
+}
+
+// CheckWrites returns an error diagnostic for every generated file that
+// violates the write policy it was created under.
+func (g *Generator) CheckWrites() []Diagnostic {
+	var diagnostics []Diagnostic
+	for _, gf := range g.generatedFiles {
+		if gf.skip || gf.policy == nil {
+			continue
+		}
+
+		path, err := filepath.Abs(gf.filename)
+		if err != nil {
+			path = gf.filename
+		}
+		path = realPath(path)
+		if gf.policy.allows(path) {
+			continue
+		}
+
+		d := Diagnostic{Severity: DiagnosticError, File: path, Tool: gf.policy.Tool}
+		switch {
+		case !withinAny(path, gf.policy.moduleDirs):
+			d.Code = CodeWriteOutsideModule
+			d.Message = fmt.Sprintf("%s is outside the module of the processed packages", path)
+		case !gf.policy.packageDirs[filepath.Dir(path)]:
+			d.Code = CodeWriteOutsidePackage
+			d.Message = fmt.Sprintf("%s is not in the directory of a processed package", path)
+		default:
+			continue
+		}
+		d.Message += fmt.Sprintf("; add it to allowed_paths of plugin %s to permit it", gf.policy.Plugin)
+		diagnostics = append(diagnostics, d)
+	}
+	return diagnostics
+}
+
+// checkWrites returns a *WriteError if generated files violate their policy.
+func (g *Generator) checkWrites() error {
+	if diagnostics := g.CheckWrites(); len(diagnostics) > 0 {
+		return &WriteError{Diagnostics: diagnostics}
+	}
+	return nil
+}
+
+// packageDirs returns the directories of the loaded packages and of their
+// modules, with symlinks resolved.
+func (g *Generator) packageDirs() (map[string]bool, []string) {
+	packageDirs := make(map[string]bool)
+	modules := make(map[string]bool)
+	var moduleDirs []string
+	for _, pkg := range g.Packages {
+		if pkg.Dir == "" {
+			continue
+		}
+		dir := realPath(pkg.Dir)
+		packageDirs[dir] = true
+		if _, modDir, err := findGoMod(dir); err == nil && modDir != "" && !modules[modDir] {
+			modules[modDir] = true
+			moduleDirs = append(moduleDirs, modDir)
+		}
+	}
+	return packageDirs, moduleDirs
+}
+
+// allows reports whether path is inside one of the allowed paths.
+func (p *WritePolicy) allows(path string) bool {
+	for _, allowed := range p.AllowedPaths {
+		if within(path, realPath(allowed)) {
+			return true
+		}
+	}
+	return false
+}
+
+// realPath resolves symlinks in the longest existing prefix of path, so a
+// symlinked directory cannot be used to escape the allowed directories.
+func realPath(path string) string {
+	path = filepath.Clean(path)
+	var rest []string
+	for dir := path; ; dir = filepath.Dir(dir) {
+		if _, err := os.Lstat(dir); err == nil {
+			if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+				return filepath.Join(append([]string{resolved}, rest...)...)
+			}
+			return path
+		}
+		if filepath.Dir(dir) == dir {
+			return path
+		}
+		rest = append([]string{filepath.Base(dir)}, rest...)
+	}
+}
+
+// within reports whether path is dir or inside it.
+func within(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// withinAny reports whether path is inside one of dirs.
+func withinAny(path string, dirs []string) bool {
+	for _, dir := range dirs {
+		if within(path, dir) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package genkit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newSandboxGenerator returns a generator with one package in a fresh
+// module and the module directory.
+func newSandboxGenerator(t *testing.T) (*Generator, string) {
+	t.Helper()
+	mod := t.TempDir()
+	if err := os.WriteFile(filepath.Join(mod, "go.mod"), []byte("module example.com/m\n\ngo 1.21\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	pkgDir := filepath.Join(mod, "model")
+	if err := os.MkdirAll(pkgDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	gen := New()
+	gen.Packages = []*Package{{Name: "model", PkgPath: "example.com/m/model", Dir: pkgDir}}
+	return gen, mod
+}
+
+func TestWritePolicy(t *testing.T) {
+	gen, mod := newSandboxGenerator(t)
+	outside := t.TempDir()
+
+	gen.SetWritePolicy(&WritePolicy{Tool: "markgen", Plugin: "gens", AllowedPaths: []string{filepath.Join(mod, "docs")}})
+	gen.NewGeneratedFile(filepath.Join(mod, "model", "model_mark.go"), "").P("package model")
+	gen.NewGeneratedFile(filepath.Join(mod, "docs", "api", "model.md"), "").P("# model")
+	gen.NewGeneratedFile(filepath.Join(mod, "model", "..", "main_mark.go"), "").P("package main")
+	gen.NewGeneratedFile(filepath.Join(outside, "evil.go"), "").P("package evil")
+	gen.SetWritePolicy(nil)
+	gen.NewGeneratedFile(filepath.Join(outside, "builtin.go"), "").P("package builtin")
+
+	diagnostics := gen.CheckWrites()
+	if len(diagnostics) != 2 {
+		t.Fatalf("CheckWrites() = %+v", diagnostics)
+	}
+	for i, want := range []struct{ code, file string }{
+		{CodeWriteOutsidePackage, filepath.Join(mod, "main_mark.go")},
+		{CodeWriteOutsideModule, filepath.Join(outside, "evil.go")},
+	} {
+		d := diagnostics[i]
+		if d.Code != want.code || realPath(d.File) != realPath(want.file) || d.Tool != "markgen" || d.Severity != DiagnosticError {
+			t.Errorf("diagnostic %d = %+v, want %s for %s", i, d, want.code, want.file)
+		}
+		if !strings.Contains(d.Message, "allowed_paths of plugin gens") {
+			t.Errorf("diagnostic %d message = %q", i, d.Message)
+		}
+	}
+
+	err := gen.Write()
+	if _, ok := err.(*WriteError); !ok {
+		t.Fatalf("Write() error = %v, want *WriteError", err)
+	}
+	if _, err := os.Stat(filepath.Join(mod, "model", "model_mark.go")); !os.IsNotExist(err) {
+		t.Error("Write() wrote files despite a violation")
+	}
+	if _, err := gen.DryRun(); err == nil {
+		t.Error("DryRun() should fail on a violation")
+	}
+}
+
+func TestWritePolicyRunPackages(t *testing.T) {
+	gen, mod := newSandboxGenerator(t)
+
+	// A package the tool loads itself does not widen its policy
+	gen.SetWritePolicy(&WritePolicy{Tool: "markgen", Plugin: "gens"})
+	gen.Packages = append(gen.Packages, &Package{Name: "api", PkgPath: "example.com/m/api", Dir: filepath.Join(mod, "api")})
+	gen.NewGeneratedFile(filepath.Join(mod, "model", "model_mark.go"), "").P("package model")
+	gen.NewGeneratedFile(filepath.Join(mod, "api", "api_mark.go"), "").P("package api")
+
+	diagnostics := gen.CheckWrites()
+	if len(diagnostics) != 1 || diagnostics[0].Code != CodeWriteOutsidePackage ||
+		realPath(diagnostics[0].File) != realPath(filepath.Join(mod, "api", "api_mark.go")) {
+		t.Fatalf("CheckWrites() = %+v", diagnostics)
+	}
+
+	// The packages loaded when the policy is set are allowed
+	gen.SetWritePolicy(&WritePolicy{Tool: "docgen", Plugin: "gens"})
+	gen.NewGeneratedFile(filepath.Join(mod, "api", "api_doc.go"), "").P("package api")
+	if diagnostics := gen.CheckWrites(); len(diagnostics) != 1 || diagnostics[0].Tool != "markgen" {
+		t.Errorf("CheckWrites() = %+v", diagnostics)
+	}
+}
+
+func TestWritePolicySymlink(t *testing.T) {
+	gen, mod := newSandboxGenerator(t)
+	outside := t.TempDir()
+	link := filepath.Join(mod, "model", "out")
+	if err := os.Symlink(outside, link); err != nil {
+		t.Skipf("symlink: %v", err)
+	}
+
+	gen.SetWritePolicy(&WritePolicy{Tool: "markgen", Plugin: "gens", AllowedPaths: []string{filepath.Join(mod, "model")}})
+	gen.NewGeneratedFile(filepath.Join(link, "evil.go"), "").P("package evil")
+
+	if diagnostics := gen.CheckWrites(); len(diagnostics) != 1 || diagnostics[0].Code != CodeWriteOutsideModule {
+		t.Errorf("CheckWrites() through symlink = %+v", diagnostics)
+	}
+}
+
+func TestPluginLoaderWritePolicy(t *testing.T) {
+	pl := NewPluginLoader(t.TempDir())
+	pl.loaded["gens"] = []Tool{&plainTestTool{name: "markgen"}, &plainTestTool{name: "docgen"}}
+
+	cfg := &Config{Plugins: []PluginConfig{{Name: "gens", Tools: []string{"markgen"}, AllowedPaths: []string{"/srv/docs"}}}}
+	if _, err := pl.LoadTools(t.Context(), cfg, []Tool{&plainTestTool{name: "enumgen"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if p := pl.WritePolicy("markgen"); p == nil || p.Plugin != "gens" || len(p.AllowedPaths) != 1 {
+		t.Errorf("WritePolicy(markgen) = %+v", p)
+	}
+	if p := pl.WritePolicy("docgen"); p != nil {
+		t.Errorf("WritePolicy(docgen) = %+v, want nil for an unselected tool", p)
+	}
+	if p := pl.WritePolicy("enumgen"); p != nil {
+		t.Errorf("WritePolicy(enumgen) = %+v, want nil for a built-in tool", p)
+	}
+}
@@ -1,5 +1,7 @@
 package genkit
 
+import "slices"
+
 // Tool is the interface that code generation tools must implement.
 // It provides a unified way to run code generators.
 type Tool interface {
@@ -74,8 +76,27 @@ type Rule struct {
 	// If false, the rule is included based on Globs or manual reference.
 	AlwaysApply bool
 
+	// Agents limits the rule to the named agents (adapter names such as
+	// "cursor" or "kiro"). Empty means all agents.
+	Agents []string
+
+	// ExcludeAgents names agents that don't get the rule.
+	ExcludeAgents []string
+
+	// Budget limits the size of Content. Agents may set a tighter budget,
+	// see BudgetRules. Zero means unlimited.
+	Budget RuleBudget
+
 	// Content is the actual rule content in Markdown format.
 	// This should be detailed, step-by-step documentation with examples.
 	// Write it as if the reader knows nothing - be explicit and thorough.
 	Content string
 }
+
+// ForAgent reports whether the rule is written for the named agent.
+func (r Rule) ForAgent(agent string) bool {
+	if slices.Contains(r.ExcludeAgents, agent) {
+		return false
+	}
+	return len(r.Agents) == 0 || slices.Contains(r.Agents, agent)
+}
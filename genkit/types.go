@@ -28,6 +28,7 @@ type Diagnostic struct {
 	EndCol   int                `json:"endColumn,omitempty"`
 	Tool     string             `json:"tool"`
 	Code     string             `json:"code,omitempty"` // e.g., "E001"
+	Fixes    []SuggestedFix     `json:"fixes,omitempty"`
 }
 
 // NewDiagnostic creates a new diagnostic from a token.Position.
@@ -120,6 +121,17 @@ func (c *DiagnosticCollector) Warningf(
 	return c.Warning(code, fmt.Sprintf(format, args...), pos)
 }
 
+// WithFix attaches a suggested fix to the most recently added diagnostic.
+// It is a no-op if no diagnostic has been added or edits is empty.
+func (c *DiagnosticCollector) WithFix(message string, edits ...TextEdit) *DiagnosticCollector {
+	if len(c.diagnostics) == 0 || len(edits) == 0 {
+		return c
+	}
+	last := &c.diagnostics[len(c.diagnostics)-1]
+	last.Fixes = append(last.Fixes, SuggestedFix{Message: message, Edits: edits})
+	return c
+}
+
 // Collect returns all collected diagnostics.
 func (c *DiagnosticCollector) Collect() []Diagnostic {
 	return c.diagnostics
@@ -153,11 +165,11 @@ func (c *DiagnosticCollector) MergeSlice(diagnostics []Diagnostic) *DiagnosticCo
 // Annotations follow the format: tool:@name or tool:@name(arg1, arg2, key=value)
 // Example: enumgen:@enum(string, json)
 type Annotation struct {
-	Tool  string            // tool name (e.g., "enumgen")
-	Name  string            // annotation name (e.g., "enum")
-	Args  map[string]string // key=value args
-	Flags []string          // positional args without =
-	Raw   string
+	Tool  string            `json:"tool"`            // tool name (e.g., "enumgen")
+	Name  string            `json:"name"`            // annotation name (e.g., "enum")
+	Args  map[string]string `json:"args,omitempty"`  // key=value args
+	Flags []string          `json:"flags,omitempty"` // positional args without =
+	Raw   string            `json:"raw"`
 }
 
 // Has checks if the annotation has a flag or arg (case-sensitive).
@@ -0,0 +1,99 @@
+package genkit
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// APIVersion is the version of the genkit API implemented by this build.
+// It is raised on every release that changes the API available to plugins,
+// so plugins can declare the versions they were written for.
+const APIVersion = "v0.4.0"
+
+// VersionedTool is implemented by tools that declare the genkit API
+// versions they support. Plugins that do not implement it are assumed to
+// work with any version.
+//
+// Go plugins (source and .so) can instead export a package-level variable,
+// which devgen checks before compiling or using the plugin:
+//
+//	var GenkitVersion = ">= v0.4"
+type VersionedTool interface {
+	Tool
+
+	// GenkitVersion returns the supported API versions as a constraint,
+	// e.g. ">= v0.4" or ">= v0.4, < v0.6". A bare version means ">=".
+	GenkitVersion() string
+}
+
+// CheckAPIVersion reports whether APIVersion satisfies constraint.
+// The constraint is a comma-separated list of comparisons using
+// >=, >, <=, <, = or != followed by a semantic version; "v0.4" is
+// shorthand for "v0.4.0". An empty constraint is always satisfied.
+func CheckAPIVersion(constraint string) error {
+	ok, err := satisfiesVersion(APIVersion, constraint)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("requires genkit %s, devgen has %s", strings.TrimSpace(constraint), APIVersion)
+	}
+	return nil
+}
+
+// checkPluginVersion checks the constraint declared by a plugin.
+func checkPluginVersion(name, constraint string) error {
+	if err := CheckAPIVersion(constraint); err != nil {
+		return fmt.Errorf("plugin %s %w", name, err)
+	}
+	return nil
+}
+
+// satisfiesVersion reports whether version satisfies every comparison
+// in constraint.
+func satisfiesVersion(version, constraint string) (bool, error) {
+	for _, part := range strings.Split(constraint, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		op := ">="
+		for _, candidate := range []string{">=", "<=", "!=", ">", "<", "="} {
+			if strings.HasPrefix(part, candidate) {
+				op = candidate
+				part = strings.TrimSpace(part[len(candidate):])
+				break
+			}
+		}
+		if !strings.HasPrefix(part, "v") {
+			part = "v" + part
+		}
+		if !semver.IsValid(part) {
+			return false, fmt.Errorf("invalid genkit version constraint %q: %q is not a semantic version", constraint, part)
+		}
+
+		cmp := semver.Compare(version, part)
+		var ok bool
+		switch op {
+		case ">=":
+			ok = cmp >= 0
+		case ">":
+			ok = cmp > 0
+		case "<=":
+			ok = cmp <= 0
+		case "<":
+			ok = cmp < 0
+		case "=":
+			ok = cmp == 0
+		case "!=":
+			ok = cmp != 0
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
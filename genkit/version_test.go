@@ -0,0 +1,93 @@
+package genkit
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSatisfiesVersion(t *testing.T) {
+	tests := []struct {
+		constraint string
+		want       bool
+	}{
+		{"", true},
+		{">= v0.4", true},
+		{"v0.4", true},
+		{"0.3", true},
+		{">= v0.5", false},
+		{">= v0.3, < v0.5", true},
+		{">= v0.3, < v0.4", false},
+		{"> v0.4.0", false},
+		{"<= v0.4.0", true},
+		{"= v0.4.0", true},
+		{"!= v0.4.0", false},
+	}
+	for _, tt := range tests {
+		got, err := satisfiesVersion("v0.4.0", tt.constraint)
+		if err != nil {
+			t.Errorf("satisfiesVersion(%q) error = %v", tt.constraint, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("satisfiesVersion(%q) = %v, want %v", tt.constraint, got, tt.want)
+		}
+	}
+
+	if _, err := satisfiesVersion("v0.4.0", ">= latest"); err == nil {
+		t.Error("satisfiesVersion() should reject invalid versions")
+	}
+}
+
+func TestCheckPluginVersion(t *testing.T) {
+	err := checkPluginVersion("mygen", ">= v99.0")
+	want := "plugin mygen requires genkit >= v99.0, devgen has " + APIVersion
+	if err == nil || err.Error() != want {
+		t.Errorf("checkPluginVersion() error = %v, want %q", err, want)
+	}
+	if err := checkPluginVersion("mygen", ">= "+APIVersion); err != nil {
+		t.Errorf("checkPluginVersion() error = %v", err)
+	}
+}
+
+func TestSourcePluginVersion(t *testing.T) {
+	dir := t.TempDir()
+	src := "package main\n\nvar GenkitVersion = \">= v99.0\"\n\nvar Tool = undefined\n"
+	if err := os.WriteFile(filepath.Join(dir, "plugin.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := sourceGenkitVersion(dir); err != nil || got != ">= v99.0" {
+		t.Errorf("sourceGenkitVersion() = %q, %v", got, err)
+	}
+
+	// The version is checked before compiling, so the undefined Tool does not matter
+	_, err := NewPluginLoader(t.TempDir()).LoadPlugin(context.Background(), PluginConfig{Name: "oldgen", Path: dir})
+	if err == nil || !strings.Contains(err.Error(), "plugin oldgen requires genkit >= v99.0, devgen has "+APIVersion) {
+		t.Errorf("LoadPlugin() error = %v", err)
+	}
+}
+
+func TestProtocolPluginVersion(t *testing.T) {
+	transport := func(requires string) pluginTransport {
+		return func(context.Context, []byte) ([]byte, error) {
+			return json.Marshal(&PluginResponse{Version: PluginProtocolVersion, Name: "mygen", Requires: requires})
+		}
+	}
+
+	if _, err := newProtocolTool(context.Background(), "mygen", transport("< v0.1")); err == nil ||
+		!strings.Contains(err.Error(), "plugin mygen requires genkit < v0.1") {
+		t.Errorf("newProtocolTool() error = %v", err)
+	}
+
+	tool, err := newProtocolTool(context.Background(), "mygen", transport(">= v0.1"))
+	if err != nil {
+		t.Fatalf("newProtocolTool() error = %v", err)
+	}
+	if tool.GenkitVersion() != ">= v0.1" {
+		t.Errorf("GenkitVersion() = %q", tool.GenkitVersion())
+	}
+}
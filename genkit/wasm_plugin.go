@@ -0,0 +1,155 @@
+package genkit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/experimental"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+// Default limits for WebAssembly plugins.
+const (
+	defaultWasmMemoryLimitMB = 256
+	defaultWasmTimeout       = 30 * time.Second
+)
+
+// wasmPageSize is the size of a WebAssembly memory page.
+const wasmPageSize = 64 * 1024
+
+// loadWasmPlugin compiles a WebAssembly module and asks it to describe itself.
+// The module runs in an embedded pure-Go runtime with WASI stdin/stdout only:
+// it has no file system, network or environment access, its memory is
+// limited and every request is cancelled after the configured timeout.
+func (pl *PluginLoader) loadWasmPlugin(ctx context.Context, cfg PluginConfig) ([]Tool, error) {
+	code, err := os.ReadFile(cfg.Path)
+	if err != nil {
+		return nil, fmt.Errorf("read wasm module: %w", err)
+	}
+
+	memoryLimitMB := cfg.MemoryLimitMB
+	if memoryLimitMB <= 0 {
+		memoryLimitMB = defaultWasmMemoryLimitMB
+	}
+	timeout, err := pluginTimeout(cfg, defaultWasmTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	// The memory limit is enforced by wasmMemory rather than the runtime, since
+	// the runtime refuses to grow memory beyond its limit without telling the host.
+	memoryLimit := uint64(memoryLimitMB) * 1024 * 1024
+	runtimeConfig := wazero.NewRuntimeConfig().WithCloseOnContextDone(true)
+	if cache, err := wazero.NewCompilationCacheWithDir(filepath.Join(pl.cacheDir, "wasm")); err == nil {
+		runtimeConfig = runtimeConfig.WithCompilationCache(cache)
+	}
+
+	// The runtime lives as long as the tool; modules are instantiated per request.
+	runtime := wazero.NewRuntimeWithConfig(context.Background(), runtimeConfig)
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, runtime); err != nil {
+		_ = runtime.Close(ctx)
+		return nil, fmt.Errorf("instantiate WASI: %w", err)
+	}
+	compiled, err := runtime.CompileModule(ctx, code)
+	if err != nil {
+		_ = runtime.Close(ctx)
+		return nil, fmt.Errorf("compile wasm module: %w", err)
+	}
+	for _, def := range append(slices.Collect(maps.Values(compiled.ExportedMemories())), compiled.ImportedMemories()...) {
+		if uint64(def.Min())*wasmPageSize > memoryLimit {
+			_ = runtime.Close(ctx)
+			return nil, fmt.Errorf("wasm module needs %d MiB of memory, exceeds memory limit of %d MiB",
+				uint64(def.Min())*wasmPageSize/1024/1024, memoryLimitMB)
+		}
+	}
+	// Called with pl.mu held by LoadPluginTools
+	pl.closers = append(pl.closers, func() error { return runtime.Close(context.Background()) })
+
+	tools, err := newProtocolTools(ctx, cfg.Name, func(ctx context.Context, input []byte) ([]byte, error) {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		var stdout, stderr bytes.Buffer
+		moduleConfig := wazero.NewModuleConfig().
+			WithName("").
+			WithArgs(cfg.Name).
+			WithStdin(bytes.NewReader(input)).
+			WithStdout(&stdout).
+			WithStderr(&stderr)
+
+		allocator := &wasmMemoryAllocator{limit: memoryLimit}
+		mod, err := runtime.InstantiateModule(experimental.WithMemoryAllocator(ctx, allocator), compiled, moduleConfig)
+		if mod != nil {
+			_ = mod.Close(ctx)
+		}
+		var exitErr *sys.ExitError
+		if errors.As(err, &exitErr) {
+			switch exitErr.ExitCode() {
+			case 0:
+				err = nil
+			case sys.ExitCodeDeadlineExceeded:
+				return nil, fmt.Errorf("timed out after %s", timeout)
+			}
+		}
+		if err != nil {
+			if allocator.exceeded {
+				return nil, fmt.Errorf("exceeded memory limit of %d MiB: %w", memoryLimitMB, err)
+			}
+			return nil, fmt.Errorf("%w\n%s", err, strings.TrimSpace(stderr.String()))
+		}
+		return stdout.Bytes(), nil
+	}, 0) // the transport limits every request, describe included
+	if err != nil {
+		return nil, err
+	}
+	return tools, nil
+}
+
+// wasmMemoryAllocator backs the linear memory of a module instance and
+// records whether the module tried to grow it beyond limit bytes.
+type wasmMemoryAllocator struct {
+	limit    uint64
+	exceeded bool
+}
+
+// Allocate implements experimental.MemoryAllocator.
+func (a *wasmMemoryAllocator) Allocate(capacity, _ uint64) experimental.LinearMemory {
+	return &wasmMemory{allocator: a, buf: make([]byte, 0, min(capacity, a.limit))}
+}
+
+// wasmMemory is a linear memory limited by its allocator.
+type wasmMemory struct {
+	allocator *wasmMemoryAllocator
+	buf       []byte
+}
+
+// Reallocate implements experimental.LinearMemory. Growing beyond the limit
+// fails, which the module sees as a failed memory.grow.
+func (m *wasmMemory) Reallocate(size uint64) []byte {
+	if size > m.allocator.limit {
+		m.allocator.exceeded = true
+		return nil
+	}
+	if size > uint64(cap(m.buf)) {
+		buf := make([]byte, len(m.buf), min(max(size, 2*uint64(cap(m.buf))), m.allocator.limit))
+		copy(buf, m.buf)
+		m.buf = buf
+	}
+	m.buf = m.buf[:size]
+	return m.buf
+}
+
+// Free implements experimental.LinearMemory.
+func (m *wasmMemory) Free() {
+	m.buf = nil
+}